@@ -0,0 +1,149 @@
+package consumers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/utils"
+)
+
+// Ways the bot hands an MR to GitLab for merging.
+const (
+	autoMergeModeMerged               = "merged"
+	autoMergeModeWhenPipelineSucceeds = "merge_when_pipeline_succeeds"
+)
+
+// autoMergeMetadata is stored on auto_merged and auto_merge_failed actions.
+type autoMergeMetadata struct {
+	SHA    string `json:"sha"`
+	Mode   string `json:"mode,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ProcessAutoMerge merges MRs of repositories with /auto_merge enabled that carry the auto-merge
+// label, are fully approved, have no unresolved threads, conflicts or block labels and whose head
+// pipeline succeeded. MRs whose pipeline is still running are set to merge when it succeeds.
+// MRs without a pipeline are only merged in repositories without CI. Each head commit is tried
+// once; the author is told the result.
+func (c *MRReviewerConsumer) ProcessAutoMerge() {
+	var configs []models.AutoMergeConfig
+	if err := c.db.Find(&configs).Error; err != nil {
+		log.Printf("failed to fetch auto-merge configs: %v", err)
+		return
+	}
+
+	for _, cfg := range configs {
+		var mrs []models.MergeRequest
+		if err := c.db.
+			Preload("Repository").Preload("Author").Preload("Labels").Preload("Reviewers").Preload("Approvers").
+			Joins("JOIN merge_request_labels ON merge_request_labels.merge_request_id = merge_requests.id").
+			Joins("JOIN labels ON labels.id = merge_request_labels.label_id").
+			Where("merge_requests.state = ? AND merge_requests.merged_at IS NULL", "opened").
+			Where("merge_requests.repository_id = ? AND labels.name = ?", cfg.RepositoryID, cfg.LabelName).
+			Find(&mrs).Error; err != nil {
+			log.Printf("failed to fetch merge requests for auto-merge: %v", err)
+			continue
+		}
+
+		for i := range mrs {
+			mr := &mrs[i]
+			mode, ok := c.autoMergeMode(mr)
+			if !ok || c.triedForHead(mr, models.ActionAutoMerged, models.ActionAutoMergeFailed) {
+				continue
+			}
+			c.autoMerge(mr, mode)
+		}
+	}
+}
+
+// autoMergeMode reports how the MR can be handed to GitLab, or false if it is not ready.
+func (c *MRReviewerConsumer) autoMergeMode(mr *models.MergeRequest) (string, bool) {
+	if mr.Draft || mr.MergeWhenPipelineSucceeds || mr.SHA == "" {
+		return "", false
+	}
+	if !utils.IsMRFullyApproved(mr) || utils.IsMRConflicted(mr) {
+		return "", false
+	}
+	if utils.IsMRBlocked(c.db, mr) || utils.HasReleaseLabel(c.db, mr) || utils.HasUnresolvedComments(c.db, mr.ID) {
+		return "", false
+	}
+
+	switch {
+	case mr.PipelineSHA != mr.SHA:
+		return "", false // Pipeline of the head commit not synced yet
+	case mr.PipelineStatus == "":
+		if utils.RepositoryHasCI(c.db, mr.RepositoryID) {
+			return "", false // Pipeline may not have started yet
+		}
+		return autoMergeModeMerged, true
+	case mr.PipelineStatus == utils.PipelineSuccess:
+		return autoMergeModeMerged, true
+	case !utils.IsPipelineFinished(mr.PipelineStatus):
+		return autoMergeModeWhenPipelineSucceeds, true
+	}
+	return "", false
+}
+
+// triedForHead reports whether an action of one of the given types was recorded for the MR's
+// current head commit.
+func (c *MRReviewerConsumer) triedForHead(mr *models.MergeRequest, actionTypes ...models.MRActionType) bool {
+	var actions []models.MRAction
+	c.db.Where("merge_request_id = ? AND action_type IN ?", mr.ID, actionTypes).Find(&actions)
+	for _, action := range actions {
+		var meta struct {
+			SHA string `json:"sha"`
+		}
+		if err := json.Unmarshal([]byte(action.Metadata), &meta); err == nil && meta.SHA == mr.SHA {
+			return true
+		}
+	}
+	return false
+}
+
+// autoMerge asks GitLab to merge the MR's head commit and reports the result to its author.
+func (c *MRReviewerConsumer) autoMerge(mr *models.MergeRequest, mode string) {
+	opts := &gitlab.AcceptMergeRequestOptions{SHA: gitlab.Ptr(mr.SHA)}
+	if mode == autoMergeModeWhenPipelineSucceeds {
+		opts.MergeWhenPipelineSucceeds = gitlab.Ptr(true)
+	}
+
+	meta := autoMergeMetadata{SHA: mr.SHA, Mode: mode}
+	actionType := models.ActionAutoMerged
+	_, _, err := c.mrService.AcceptMergeRequest(mr.Repository.GitlabID, mr.IID, opts)
+	if err != nil {
+		log.Printf("failed to auto-merge MR %d: %v", mr.ID, err)
+		actionType = models.ActionAutoMergeFailed
+		meta = autoMergeMetadata{SHA: mr.SHA, Reason: err.Error()}
+	}
+
+	metadata, _ := json.Marshal(meta)
+	if err := c.db.Create(&models.MRAction{
+		MergeRequestID: mr.ID,
+		ActionType:     actionType,
+		Timestamp:      time.Now().UTC(),
+		Metadata:       string(metadata),
+		Notified:       true,
+	}).Error; err != nil {
+		log.Printf("failed to record auto-merge of MR %d: %v", mr.ID, err)
+	}
+
+	var text string
+	switch {
+	case err != nil:
+		text = fmt.Sprintf("⚠️ Auto-merge failed [%s]:\n%s\n%s\nReason: %s", mr.Repository.Name, mr.Title, mr.WebURL, err.Error())
+	case mode == autoMergeModeWhenPipelineSucceeds:
+		if err := c.db.Model(mr).UpdateColumn("merge_when_pipeline_succeeds", true).Error; err != nil {
+			log.Printf("failed to mark MR %d to merge when pipeline succeeds: %v", mr.ID, err)
+		}
+		text = fmt.Sprintf("⏳ Your MR will be merged when its pipeline succeeds [%s]:\n%s\n%s", mr.Repository.Name, mr.Title, mr.WebURL)
+	default:
+		text = fmt.Sprintf("🚀 Your MR was merged automatically [%s]:\n%s\n%s", mr.Repository.Name, mr.Title, mr.WebURL)
+	}
+	c.notifyUserDM(&mr.Author, utils.NotifyAutoMerge, text)
+	log.Printf("Auto-merge of MR %d: %s", mr.ID, string(metadata))
+}
@@ -0,0 +1,154 @@
+package consumers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+
+	"devstreamlinebot/mocks"
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+// TestProcessAutoMerge tests that only labeled, fully approved MRs with a green or running pipeline,
+// no unresolved threads and no block label are merged, once per head commit, and that the author is told.
+func TestProcessAutoMerge(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	mockBot := mocks.NewMockVKBot()
+	userFactory := testutils.NewUserFactory(db)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create(testutils.WithEmail("author@example.com"))
+	reviewer := userFactory.Create()
+	db.Create(&models.AutoMergeConfig{RepositoryID: repo.ID, LabelName: "auto-merge"})
+	db.Create(&models.BlockLabel{RepositoryID: repo.ID, LabelName: "on-hold"})
+
+	mrFactory := testutils.NewMergeRequestFactory(db)
+	newMR := func(title string, pipelineStatus string, labels ...string) models.MergeRequest {
+		mr := mrFactory.Create(repo, author, testutils.WithTitle(title), testutils.WithLabels(db, labels...))
+		testutils.AssignReviewers(db, &mr, reviewer)
+		testutils.AssignApprovers(db, &mr, reviewer)
+		db.Model(&mr).UpdateColumns(map[string]interface{}{"sha": "head-" + title, "pipeline_status": pipelineStatus, "pipeline_sha": "head-" + title})
+		return mr
+	}
+
+	ready := newMR("ready", "success", "auto-merge")
+	running := newMR("running", "running", "auto-merge")
+	newMR("unlabeled", "success")
+	newMR("failing", "failed", "auto-merge")
+	newMR("blocked", "success", "auto-merge", "on-hold")
+	discussed := newMR("discussed", "success", "auto-merge")
+	testutils.CreateMRComment(db, discussed, reviewer, 1, testutils.WithResolvable())
+
+	mrService := &mocks.MockMergeRequestsService{}
+	consumer := NewMRReviewerConsumerWithServices(db, mockBot, mrService, nil, 0, nil)
+	consumer.ProcessAutoMerge()
+	consumer.ProcessAutoMerge()
+
+	calls := mrService.AcceptMergeRequestCalls
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 merge requests to GitLab, got %d", len(calls))
+	}
+	for _, call := range calls {
+		switch call.MergeRequest {
+		case ready.IID:
+			if call.Opt.MergeWhenPipelineSucceeds != nil || *call.Opt.SHA != "head-ready" {
+				t.Errorf("expected a direct merge of the head commit, got %+v", call.Opt)
+			}
+		case running.IID:
+			if call.Opt.MergeWhenPipelineSucceeds == nil || !*call.Opt.MergeWhenPipelineSucceeds {
+				t.Errorf("expected merge when pipeline succeeds for the running pipeline, got %+v", call.Opt)
+			}
+		default:
+			t.Errorf("unexpected merge of MR !%d", call.MergeRequest)
+		}
+	}
+
+	sent := mockBot.GetSentMessages()
+	if len(sent) != 2 {
+		t.Fatalf("expected 2 DMs to the author, got %d", len(sent))
+	}
+	var merged, scheduled bool
+	for _, m := range sent {
+		merged = merged || strings.Contains(m.Text, "merged automatically")
+		scheduled = scheduled || strings.Contains(m.Text, "merged when its pipeline succeeds")
+	}
+	if !merged || !scheduled {
+		t.Errorf("expected merged and scheduled DMs, got %+v", sent)
+	}
+}
+
+// TestProcessAutoMerge_Failure tests that a refused merge is reported to the author with GitLab's
+// reason and retried only after new commits.
+func TestProcessAutoMerge_Failure(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	mockBot := mocks.NewMockVKBot()
+	userFactory := testutils.NewUserFactory(db)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create(testutils.WithEmail("author@example.com"))
+	reviewer := userFactory.Create()
+	db.Create(&models.AutoMergeConfig{RepositoryID: repo.ID, LabelName: "auto-merge"})
+
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author, testutils.WithLabels(db, "auto-merge"))
+	testutils.AssignReviewers(db, &mr, reviewer)
+	testutils.AssignApprovers(db, &mr, reviewer)
+	db.Model(&mr).UpdateColumns(map[string]interface{}{"sha": "aaa111", "pipeline_sha": "aaa111"})
+
+	mrService := &mocks.MockMergeRequestsService{
+		AcceptMergeRequestFunc: func(pid interface{}, mergeRequest int, opt *gitlab.AcceptMergeRequestOptions, options ...gitlab.RequestOptionFunc) (*gitlab.MergeRequest, *gitlab.Response, error) {
+			return nil, nil, errors.New("405 Branch cannot be merged")
+		},
+	}
+	consumer := NewMRReviewerConsumerWithServices(db, mockBot, mrService, nil, 0, nil)
+	consumer.ProcessAutoMerge()
+	consumer.ProcessAutoMerge()
+
+	sent := mockBot.GetSentMessages()
+	if len(sent) != 1 || sent[0].ChatID != "author@example.com" || !containsAll(sent[0].Text, "Auto-merge failed", "Branch cannot be merged") {
+		t.Fatalf("expected one failure DM with the reason, got %+v", sent)
+	}
+
+	db.Model(&mr).UpdateColumns(map[string]interface{}{"sha": "bbb222", "pipeline_sha": "bbb222"})
+	consumer.ProcessAutoMerge()
+	if len(mrService.AcceptMergeRequestCalls) != 2 {
+		t.Errorf("expected a retry after new commits, got %d merge requests", len(mrService.AcceptMergeRequestCalls))
+	}
+}
+
+// TestAutoMergeMode_WithoutPipeline tests that MRs without a head pipeline are merged only once
+// their head commit was checked and the repository has no CI.
+func TestAutoMergeMode_WithoutPipeline(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	reviewer := userFactory.Create()
+	mrFactory := testutils.NewMergeRequestFactory(db)
+	consumer := NewMRReviewerConsumerWithServices(db, nil, nil, nil, 0, nil)
+
+	mr := mrFactory.Create(repo, author)
+	testutils.AssignReviewers(db, &mr, reviewer)
+	testutils.AssignApprovers(db, &mr, reviewer)
+	mode := func() (string, bool) {
+		var reloaded models.MergeRequest
+		db.Preload("Reviewers").Preload("Approvers").Preload("Labels").First(&reloaded, mr.ID)
+		return consumer.autoMergeMode(&reloaded)
+	}
+
+	db.Model(&mr).UpdateColumns(map[string]interface{}{"sha": "head", "pipeline_sha": ""})
+	if _, ok := mode(); ok {
+		t.Error("expected no merge before the head commit was checked")
+	}
+
+	db.Model(&mr).UpdateColumn("pipeline_sha", "head")
+	if got, ok := mode(); !ok || got != autoMergeModeMerged {
+		t.Errorf("expected a direct merge without CI, got %q %v", got, ok)
+	}
+
+	withCI := mrFactory.Create(repo, author)
+	db.Model(&withCI).UpdateColumn("pipeline_id", 7)
+	if _, ok := mode(); ok {
+		t.Error("expected no merge without a pipeline in a repository with CI")
+	}
+}
@@ -0,0 +1,178 @@
+package consumers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/utils"
+)
+
+// rebaseMetadata is stored on rebase_requested and rebase_failed actions.
+type rebaseMetadata struct {
+	SHA    string `json:"sha"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ProcessAutoRebase rebases MRs of repositories with /auto_rebase enabled that GitLab reports as
+// needing a rebase and that have no conflicts, e.g. after being retargeted to a release branch.
+// Drafts, blocked and release MRs are skipped, each head commit is tried once and at most
+// MaxPerHour rebases are started per repository. Rebases run asynchronously in GitLab, so the
+// outcome of earlier requests is checked first and failures are reported to the author.
+func (c *MRReviewerConsumer) ProcessAutoRebase() {
+	c.checkRebaseResults()
+
+	var configs []models.AutoRebaseConfig
+	if err := c.db.Find(&configs).Error; err != nil {
+		log.Printf("failed to fetch auto-rebase configs: %v", err)
+		return
+	}
+
+	for _, cfg := range configs {
+		var started int64
+		c.db.Model(&models.MRAction{}).
+			Joins("JOIN merge_requests ON merge_requests.id = mr_actions.merge_request_id").
+			Where("merge_requests.repository_id = ? AND mr_actions.action_type = ? AND mr_actions.timestamp > ?",
+				cfg.RepositoryID, models.ActionRebaseRequested, time.Now().UTC().Add(-time.Hour)).
+			Count(&started)
+		budget := cfg.MaxPerHour - int(started)
+		if budget <= 0 {
+			continue
+		}
+
+		var mrs []models.MergeRequest
+		if err := c.db.
+			Preload("Repository").Preload("Author").Preload("Labels").
+			Where("state = ? AND repository_id = ? AND detailed_merge_status = ? AND has_conflicts = ? AND draft = ?",
+				"opened", cfg.RepositoryID, "need_rebase", false, false).
+			Order("id ASC").
+			Find(&mrs).Error; err != nil {
+			log.Printf("failed to fetch merge requests for auto-rebase: %v", err)
+			continue
+		}
+
+		for i := range mrs {
+			if budget == 0 {
+				break
+			}
+			mr := &mrs[i]
+			if !c.autoRebaseEligible(mr) || c.triedForHead(mr, models.ActionRebaseRequested) {
+				continue
+			}
+			c.requestRebase(mr)
+			budget--
+		}
+	}
+}
+
+// autoRebaseEligible reports whether the bot may rebase the MR.
+func (c *MRReviewerConsumer) autoRebaseEligible(mr *models.MergeRequest) bool {
+	if mr.Draft || mr.SHA == "" || utils.MergeBlocker(mr.HasConflicts, mr.DetailedMergeStatus) != utils.MergeBlockerNeedRebase {
+		return false
+	}
+	return !utils.IsMRBlocked(c.db, mr) && !utils.HasReleaseLabel(c.db, mr)
+}
+
+// autoRebaseHandles reports whether the MR needs a rebase that the bot will start itself, in which
+// case the author is only told if it fails.
+func (c *MRReviewerConsumer) autoRebaseHandles(mr *models.MergeRequest) bool {
+	var count int64
+	c.db.Model(&models.AutoRebaseConfig{}).Where("repository_id = ?", mr.RepositoryID).Count(&count)
+	if count == 0 {
+		return false
+	}
+	if err := c.db.Model(mr).Association("Labels").Find(&mr.Labels); err != nil {
+		log.Printf("failed to load labels of MR %d: %v", mr.ID, err)
+	}
+	return c.autoRebaseEligible(mr)
+}
+
+// requestRebase starts a GitLab rebase of the MR onto its target branch.
+func (c *MRReviewerConsumer) requestRebase(mr *models.MergeRequest) {
+	_, err := c.mrService.RebaseMergeRequest(mr.Repository.GitlabID, mr.IID, &gitlab.RebaseMergeRequestOptions{})
+
+	metadata, _ := json.Marshal(rebaseMetadata{SHA: mr.SHA})
+	if dbErr := c.db.Create(&models.MRAction{
+		MergeRequestID: mr.ID,
+		ActionType:     models.ActionRebaseRequested,
+		Timestamp:      time.Now().UTC(),
+		Metadata:       string(metadata),
+		Notified:       err != nil, // Pending until checkRebaseResults sees the outcome
+	}).Error; dbErr != nil {
+		log.Printf("failed to record rebase of MR %d: %v", mr.ID, dbErr)
+	}
+
+	if err != nil {
+		log.Printf("failed to rebase MR %d: %v", mr.ID, err)
+		c.reportRebaseFailure(mr, err.Error())
+		return
+	}
+	log.Printf("Started rebase of MR %d onto %s", mr.ID, mr.TargetBranch)
+}
+
+// checkRebaseResults looks up rebases the bot started and reports those GitLab failed to the author.
+// Requests without an outcome after an hour are dropped by CleanupOldUnnotifiedActions.
+func (c *MRReviewerConsumer) checkRebaseResults() {
+	var actions []models.MRAction
+	if err := c.db.
+		Preload("MergeRequest").
+		Preload("MergeRequest.Repository").
+		Preload("MergeRequest.Author").
+		Where("notified = ? AND action_type = ?", false, models.ActionRebaseRequested).
+		Order("timestamp ASC").
+		Limit(100).
+		Find(&actions).Error; err != nil {
+		log.Printf("failed to fetch pending rebases: %v", err)
+		return
+	}
+
+	for _, action := range actions {
+		mr := action.MergeRequest
+		var meta rebaseMetadata
+		if err := json.Unmarshal([]byte(action.Metadata), &meta); err != nil || mr.State != "opened" || mr.SHA != meta.SHA {
+			c.markActionNotified(action.ID) // Merged, closed or rebased in the meantime
+			continue
+		}
+
+		glMR, _, err := c.mrService.GetMergeRequest(mr.Repository.GitlabID, mr.IID,
+			&gitlab.GetMergeRequestsOptions{IncludeRebaseInProgress: gitlab.Ptr(true)})
+		if err != nil {
+			log.Printf("failed to check rebase of MR %d: %v", mr.ID, err)
+			continue
+		}
+		if glMR == nil || glMR.RebaseInProgress {
+			continue
+		}
+		if glMR.SHA != meta.SHA {
+			c.markActionNotified(action.ID)
+			continue
+		}
+		if glMR.MergeError != "" {
+			c.reportRebaseFailure(&mr, glMR.MergeError)
+			c.markActionNotified(action.ID)
+		}
+	}
+}
+
+// reportRebaseFailure records a failed rebase of the MR's head commit and DMs its author.
+func (c *MRReviewerConsumer) reportRebaseFailure(mr *models.MergeRequest, reason string) {
+	metadata, _ := json.Marshal(rebaseMetadata{SHA: mr.SHA, Reason: reason})
+	if err := c.db.Create(&models.MRAction{
+		MergeRequestID: mr.ID,
+		ActionType:     models.ActionRebaseFailed,
+		Timestamp:      time.Now().UTC(),
+		Metadata:       string(metadata),
+		Notified:       true,
+	}).Error; err != nil {
+		log.Printf("failed to record failed rebase of MR %d: %v", mr.ID, err)
+	}
+
+	c.notifyUserDM(&mr.Author, utils.NotifyConflict, fmt.Sprintf(
+		"⚠️ Automatic rebase onto %s failed [%s]:\n%s\n%s\nReason: %s\nPlease rebase it yourself.",
+		mr.TargetBranch, mr.Repository.Name, mr.Title, mr.WebURL, reason,
+	))
+}
@@ -0,0 +1,106 @@
+package consumers
+
+import (
+	"testing"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+
+	"devstreamlinebot/mocks"
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+// TestProcessAutoRebase tests that only MRs needing a rebase without conflicts are rebased, skipping
+// drafts and blocked MRs, at most MaxPerHour per repository and once per head commit.
+func TestProcessAutoRebase(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := testutils.NewUserFactory(db).Create()
+	db.Create(&models.AutoRebaseConfig{RepositoryID: repo.ID, MaxPerHour: 2})
+	db.Create(&models.BlockLabel{RepositoryID: repo.ID, LabelName: "on-hold"})
+
+	mrFactory := testutils.NewMergeRequestFactory(db)
+	newMR := func(status string, opts ...testutils.MROption) models.MergeRequest {
+		mr := mrFactory.Create(repo, author, opts...)
+		db.Model(&mr).UpdateColumns(map[string]interface{}{"detailed_merge_status": status, "sha": "sha-" + mr.Title})
+		return mr
+	}
+	newMR("need_rebase", testutils.WithDraft())
+	newMR("need_rebase", testutils.WithLabels(db, "on-hold"))
+	newMR("conflict")
+	newMR("mergeable")
+	first := newMR("need_rebase")
+	second := newMR("need_rebase")
+	newMR("need_rebase")
+
+	mrService := &mocks.MockMergeRequestsService{
+		GetMergeRequestFunc: func(pid interface{}, iid int, opt *gitlab.GetMergeRequestsOptions, options ...gitlab.RequestOptionFunc) (*gitlab.MergeRequest, *gitlab.Response, error) {
+			return &gitlab.MergeRequest{RebaseInProgress: true}, nil, nil
+		},
+	}
+	consumer := NewMRReviewerConsumerWithServices(db, mocks.NewMockVKBot(), mrService, nil, 0, nil)
+	consumer.ProcessAutoRebase()
+	consumer.ProcessAutoRebase()
+
+	calls := mrService.RebaseMergeRequestCalls
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 rebases within the hourly limit, got %d", len(calls))
+	}
+	if calls[0].MergeRequest != first.IID || calls[1].MergeRequest != second.IID {
+		t.Errorf("expected rebases of !%d and !%d, got !%d and !%d", first.IID, second.IID, calls[0].MergeRequest, calls[1].MergeRequest)
+	}
+}
+
+// TestProcessAutoRebase_Failure tests that a rebase GitLab failed is reported to the author once
+// and not retried for the same head commit.
+func TestProcessAutoRebase_Failure(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	mockBot := mocks.NewMockVKBot()
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := testutils.NewUserFactory(db).Create(testutils.WithEmail("author@example.com"))
+	db.Create(&models.AutoRebaseConfig{RepositoryID: repo.ID, MaxPerHour: 5})
+
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author)
+	db.Model(&mr).UpdateColumns(map[string]interface{}{"detailed_merge_status": "need_rebase", "sha": "aaa111"})
+
+	mrService := &mocks.MockMergeRequestsService{
+		GetMergeRequestFunc: func(pid interface{}, iid int, opt *gitlab.GetMergeRequestsOptions, options ...gitlab.RequestOptionFunc) (*gitlab.MergeRequest, *gitlab.Response, error) {
+			mr := &gitlab.MergeRequest{MergeError: "Rebase failed: Rebase locally, resolve all conflicts, then push the branch."}
+			mr.SHA = "aaa111"
+			return mr, nil, nil
+		},
+	}
+	consumer := NewMRReviewerConsumerWithServices(db, mockBot, mrService, nil, 0, nil)
+	consumer.ProcessAutoRebase()
+	if len(mockBot.GetSentMessages()) != 0 {
+		t.Fatalf("expected no DM while the rebase runs, got %+v", mockBot.GetSentMessages())
+	}
+	consumer.ProcessAutoRebase()
+	consumer.ProcessAutoRebase()
+
+	sent := mockBot.GetSentMessages()
+	if len(sent) != 1 || sent[0].ChatID != "author@example.com" || !containsAll(sent[0].Text, "rebase", "failed", "Rebase locally") {
+		t.Fatalf("expected one failure DM to the author, got %+v", sent)
+	}
+	if len(mrService.RebaseMergeRequestCalls) != 1 {
+		t.Errorf("expected no retry for the same head commit, got %d rebases", len(mrService.RebaseMergeRequestCalls))
+	}
+}
+
+// TestProcessConflictNotifications_AutoRebase tests that authors are not asked to rebase MRs the bot rebases itself.
+func TestProcessConflictNotifications_AutoRebase(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	mockBot := mocks.NewMockVKBot()
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := testutils.NewUserFactory(db).Create(testutils.WithEmail("author@example.com"))
+	db.Create(&models.AutoRebaseConfig{RepositoryID: repo.ID, MaxPerHour: 5})
+
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author)
+	db.Model(&mr).UpdateColumns(map[string]interface{}{"detailed_merge_status": "need_rebase", "sha": "aaa111"})
+	testutils.CreateMRAction(db, mr, models.ActionConflictDetected, testutils.WithMetadata(`{"reason":"need_rebase"}`))
+
+	NewMRReviewerConsumerWithBot(db, mockBot, nil, 0, nil).ProcessConflictNotifications()
+	if sent := mockBot.GetSentMessages(); len(sent) != 0 {
+		t.Errorf("expected no rebase DM with auto-rebase on, got %+v", sent)
+	}
+}
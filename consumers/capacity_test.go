@@ -0,0 +1,90 @@
+package consumers
+
+import (
+	"strings"
+	"testing"
+
+	"devstreamlinebot/mocks"
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+// TestSelectReviewersWithTrace_SkipsUsersAtCapacity tests that reviewers at their open review limit are never picked.
+func TestSelectReviewersWithTrace_SkipsUsersAtCapacity(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+	mrFactory := testutils.NewMergeRequestFactory(db)
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	busy := userFactory.Create(testutils.WithUsername("busy"))
+	free := userFactory.Create(testutils.WithUsername("free"))
+	testutils.CreatePossibleReviewer(db, repo, busy)
+	testutils.CreatePossibleReviewer(db, repo, free)
+	db.Create(&models.RepositorySLA{RepositoryID: repo.ID, AssignCount: 1, MaxOpenReviews: 2})
+
+	for i := 0; i < 2; i++ {
+		open := mrFactory.Create(repo, author)
+		testutils.AssignReviewers(db, &open, busy)
+	}
+
+	created := mrFactory.Create(repo, author)
+	consumer := NewMRReviewerConsumerWithServices(db, nil, nil, nil, 0, nil)
+	mr := loadMRForReassign(t, consumer, created.ID)
+
+	for i := 0; i < 20; i++ {
+		selected, trace := consumer.selectReviewersWithTrace(&mr, 2, nil)
+		if len(selected) != 1 || selected[0].ID != free.ID {
+			t.Fatalf("expected only free reviewer, got %v", selected)
+		}
+		if len(trace.Excluded) != 1 || trace.Excluded[0].Reason != exclusionCapacity {
+			t.Fatalf("expected busy traced as at capacity, got %v", trace.Excluded)
+		}
+	}
+
+	db.Model(&busy).Update("max_open_reviews", 3)
+	selected, _ := consumer.selectReviewersWithTrace(&mr, 2, nil)
+	if len(selected) != 2 {
+		t.Errorf("expected user limit to override repository limit, got %v", selected)
+	}
+}
+
+// TestWarnReviewersUnavailable_WarnsOnce tests that chats are warned once per shortfall and not for unrelated shortfalls.
+func TestWarnReviewersUnavailable_WarnsOnce(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	mockBot := mocks.NewMockVKBot()
+	userFactory := testutils.NewUserFactory(db)
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	chat := testutils.NewChatFactory(db).Create()
+	testutils.CreateSubscription(db, repo, chat, testutils.NewVKUserFactory(db).Create())
+	created := testutils.NewMergeRequestFactory(db).Create(repo, author)
+
+	consumer := NewMRReviewerConsumerWithServices(db, mockBot, nil, nil, 0, nil)
+	mr := loadMRForReassign(t, consumer, created.ID)
+
+	consumer.warnReviewersUnavailable(&mr, &selectionTrace{})
+	if len(mockBot.GetSentMessages()) != 0 {
+		t.Fatal("expected no warning without capacity exclusions")
+	}
+
+	trace := &selectionTrace{}
+	trace.addExclusion("busy", exclusionCapacity)
+	consumer.warnReviewersUnavailable(&mr, trace)
+	consumer.warnReviewersUnavailable(&mr, trace)
+
+	sent := mockBot.GetSentMessages()
+	if len(sent) != 1 {
+		t.Fatalf("expected 1 warning, got %d", len(sent))
+	}
+	if !strings.Contains(sent[0].Text, "Nobody available") || !strings.Contains(sent[0].Text, "busy") {
+		t.Errorf("unexpected warning: %q", sent[0].Text)
+	}
+
+	var count int64
+	db.Model(&models.MRAction{}).Where("merge_request_id = ? AND action_type = ?", mr.ID, models.ActionReviewersUnavailable).Count(&count)
+	if count != 1 {
+		t.Errorf("expected 1 reviewers_unavailable action, got %d", count)
+	}
+}
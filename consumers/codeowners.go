@@ -0,0 +1,140 @@
+package consumers
+
+import (
+	"log"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/utils"
+)
+
+// getCodeOwnerGroups returns eligible reviewers per required CODEOWNERS section matching the
+// changed paths. Returns nil when CODEOWNERS selection is disabled for the repository,
+// the file is missing or nothing matches, so selection falls back to the regular pools.
+func (c *MRReviewerConsumer) getCodeOwnerGroups(mr *models.MergeRequest, paths []string, excludeUsers []models.User) map[string][]models.User {
+	if c.filesService == nil || len(paths) == 0 {
+		return nil
+	}
+
+	var cfg models.CodeOwnersConfig
+	if err := c.db.Where("repository_id = ?", mr.RepositoryID).First(&cfg).Error; err != nil {
+		return nil
+	}
+
+	content := c.fetchCodeOwners(mr, cfg.FilePath)
+	if content == "" {
+		log.Printf("CODEOWNERS enabled but not found for repository %d", mr.RepositoryID)
+		return nil
+	}
+
+	owners := utils.MatchCodeOwners(utils.ParseCodeOwners(content), paths)
+	if len(owners) == 0 {
+		return nil
+	}
+
+	excludeIDs := []uint{mr.AuthorID}
+	for _, u := range excludeUsers {
+		excludeIDs = append(excludeIDs, u.ID)
+	}
+
+	groups := make(map[string][]models.User)
+	for section, refs := range owners {
+		usernames, emails, groupRefs := utils.SplitCodeOwnerReferences(refs)
+		if len(groupRefs) > 0 {
+			log.Printf("CODEOWNERS section %s references groups %v, only user owners are used", section, groupRefs)
+		}
+		if len(usernames) == 0 && len(emails) == 0 {
+			continue
+		}
+
+		var users []models.User
+		if err := c.db.Scopes(utils.ActiveRepositoryUsers(mr.RepositoryID)).
+			Where("id NOT IN ? AND on_vacation = ?", excludeIDs, false).
+			Where("username IN ? OR email IN ?", usernames, emails).
+			Order("username").
+			Find(&users).Error; err != nil {
+			log.Printf("failed to fetch code owners for section %s: %v", section, err)
+			continue
+		}
+		if len(users) > 0 {
+			groups[section] = users
+		}
+	}
+
+	if len(groups) == 0 {
+		return nil
+	}
+
+	log.Printf("Found %d CODEOWNERS sections with reviewers for MR %d", len(groups), mr.ID)
+	return groups
+}
+
+// fetchCodeOwners returns the CODEOWNERS content from the MR target branch, or "" if none is found.
+func (c *MRReviewerConsumer) fetchCodeOwners(mr *models.MergeRequest, filePath string) string {
+	paths := utils.CodeOwnersPaths
+	if filePath != "" {
+		paths = []string{filePath}
+	}
+
+	opts := &gitlab.GetRawFileOptions{}
+	if mr.TargetBranch != "" {
+		opts.Ref = gitlab.Ptr(mr.TargetBranch)
+	}
+
+	for _, p := range paths {
+		content, _, err := c.filesService.GetRawFile(mr.Repository.GitlabID, p, opts)
+		if err == nil && len(content) > 0 {
+			return string(content)
+		}
+	}
+	return ""
+}
+
+// getSelectionPaths returns the MR's changed paths when the repository has path-based
+// selection configured (CODEOWNERS, path reviewer rules or the expertise strategy),
+// avoiding API calls otherwise.
+func (c *MRReviewerConsumer) getSelectionPaths(mr *models.MergeRequest) []string {
+	var count int64
+	c.db.Model(&models.CodeOwnersConfig{}).Where("repository_id = ?", mr.RepositoryID).Count(&count)
+	if count == 0 {
+		c.db.Model(&models.PathReviewer{}).Where("repository_id = ?", mr.RepositoryID).Count(&count)
+	}
+	if count == 0 && c.getReviewerStrategyName(mr.RepositoryID) != StrategyExpertise {
+		return nil
+	}
+	return c.getChangedPaths(mr)
+}
+
+// getChangedPaths lists old and new paths of all files changed in the MR.
+func (c *MRReviewerConsumer) getChangedPaths(mr *models.MergeRequest) []string {
+	if c.mrService == nil {
+		return nil
+	}
+	opts := &gitlab.ListMergeRequestDiffsOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100, Page: 1},
+	}
+
+	seen := make(map[string]bool)
+	var paths []string
+	for {
+		diffs, resp, err := c.mrService.ListMergeRequestDiffs(mr.Repository.GitlabID, mr.IID, opts)
+		if err != nil {
+			log.Printf("failed to list diffs for MR %d: %v", mr.ID, err)
+			return paths
+		}
+		for _, d := range diffs {
+			for _, p := range []string{d.NewPath, d.OldPath} {
+				if p != "" && !seen[p] {
+					seen[p] = true
+					paths = append(paths, p)
+				}
+			}
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return paths
+}
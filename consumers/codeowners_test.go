@@ -0,0 +1,96 @@
+package consumers
+
+import (
+	"testing"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+
+	"devstreamlinebot/mocks"
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+func newCodeOwnersMocks(content string, changed ...string) (*mocks.MockMergeRequestsService, *mocks.MockRepositoryFilesService) {
+	mrService := &mocks.MockMergeRequestsService{
+		ListMergeRequestDiffsFunc: func(pid interface{}, mergeRequest int, opt *gitlab.ListMergeRequestDiffsOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.MergeRequestDiff, *gitlab.Response, error) {
+			diffs := make([]*gitlab.MergeRequestDiff, len(changed))
+			for i, p := range changed {
+				diffs[i] = &gitlab.MergeRequestDiff{OldPath: p, NewPath: p}
+			}
+			return diffs, mocks.NewMockResponse(0), nil
+		},
+	}
+	filesService := &mocks.MockRepositoryFilesService{
+		GetRawFileFunc: func(pid interface{}, fileName string, opt *gitlab.GetRawFileOptions, options ...gitlab.RequestOptionFunc) ([]byte, *gitlab.Response, error) {
+			if fileName != "CODEOWNERS" {
+				return nil, mocks.NewMockResponse404(), nil
+			}
+			return []byte(content), mocks.NewMockResponse(0), nil
+		},
+	}
+	return mrService, filesService
+}
+
+// TestSelectReviewersWithTrace_UsesCodeOwners tests that a matching CODEOWNERS section supplies a required owner.
+func TestSelectReviewersWithTrace_UsesCodeOwners(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	owner := userFactory.Create(testutils.WithUsername("api-owner"))
+	poolMember := userFactory.Create(testutils.WithUsername("pool-member"))
+	testutils.CreatePossibleReviewer(db, repo, poolMember)
+	db.Create(&models.CodeOwnersConfig{RepositoryID: repo.ID})
+
+	created := testutils.NewMergeRequestFactory(db).Create(repo, author)
+	mrService, filesService := newCodeOwnersMocks("[API]\n/services/api/ @api-owner\n", "services/api/handler.go")
+	consumer := NewMRReviewerConsumerWithServices(db, nil, mrService, filesService, 0, nil)
+	mr := loadMRForReassign(t, consumer, created.ID)
+
+	selected, trace := consumer.selectReviewersWithTrace(&mr, 2, nil)
+
+	if len(selected) != 2 || selected[0].ID != owner.ID || selected[1].ID != poolMember.ID {
+		t.Fatalf("expected code owner then pool fill, got %v", selected)
+	}
+	if names := trace.CodeOwners["API"]; len(names) != 1 || names[0] != "api-owner" {
+		t.Errorf("expected API section traced, got %v", trace.CodeOwners)
+	}
+	if trace.Steps[0].Pool != "codeowners API" {
+		t.Errorf("expected codeowners step first, got %s", trace.Steps[0].Pool)
+	}
+}
+
+// TestSelectReviewersWithTrace_CodeOwnersFallback tests fallback to default pool when nothing matches or the mode is off.
+func TestSelectReviewersWithTrace_CodeOwnersFallback(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	userFactory.Create(testutils.WithUsername("api-owner"))
+	poolMember := userFactory.Create()
+	testutils.CreatePossibleReviewer(db, repo, poolMember)
+
+	created := testutils.NewMergeRequestFactory(db).Create(repo, author)
+	mrService, filesService := newCodeOwnersMocks("/services/api/ @api-owner\n", "web/index.ts")
+	consumer := NewMRReviewerConsumerWithServices(db, nil, mrService, filesService, 0, nil)
+	mr := loadMRForReassign(t, consumer, created.ID)
+
+	selected, _ := consumer.selectReviewersWithTrace(&mr, 1, nil)
+	if len(selected) != 1 || selected[0].ID != poolMember.ID {
+		t.Errorf("expected default pool when disabled, got %v", selected)
+	}
+	if len(filesService.GetRawFileCalls) != 0 {
+		t.Error("CODEOWNERS must not be fetched when the mode is off")
+	}
+
+	db.Create(&models.CodeOwnersConfig{RepositoryID: repo.ID})
+	selected, trace := consumer.selectReviewersWithTrace(&mr, 1, nil)
+	if len(selected) != 1 || selected[0].ID != poolMember.ID {
+		t.Errorf("expected default pool when no section matches, got %v", selected)
+	}
+	if len(trace.CodeOwners) != 0 {
+		t.Errorf("expected no code owners traced, got %v", trace.CodeOwners)
+	}
+}
@@ -0,0 +1,52 @@
+package consumers
+
+import (
+	"testing"
+
+	"devstreamlinebot/mocks"
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+// TestProcessConflictNotifications tests that authors are DMed about conflicts and needed rebases,
+// and that conflicts resolved before processing are skipped.
+func TestProcessConflictNotifications(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	mockBot := mocks.NewMockVKBot()
+	userFactory := testutils.NewUserFactory(db)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	alice := userFactory.Create(testutils.WithEmail("alice@example.com"))
+	bob := userFactory.Create(testutils.WithEmail("bob@example.com"))
+	carol := userFactory.Create(testutils.WithEmail("carol@example.com"))
+
+	mrFactory := testutils.NewMergeRequestFactory(db)
+	conflicted := mrFactory.Create(repo, alice)
+	db.Model(&conflicted).UpdateColumns(map[string]interface{}{"has_conflicts": true, "detailed_merge_status": "conflict"})
+	rebase := mrFactory.Create(repo, bob)
+	db.Model(&rebase).UpdateColumn("detailed_merge_status", "need_rebase")
+	resolved := mrFactory.Create(repo, carol)
+	db.Model(&resolved).UpdateColumn("detailed_merge_status", "mergeable")
+
+	testutils.CreateMRAction(db, conflicted, models.ActionConflictDetected, testutils.WithMetadata(`{"reason":"conflict"}`))
+	testutils.CreateMRAction(db, rebase, models.ActionConflictDetected, testutils.WithMetadata(`{"reason":"need_rebase"}`))
+	testutils.CreateMRAction(db, resolved, models.ActionConflictDetected, testutils.WithMetadata(`{"reason":"conflict"}`))
+
+	consumer := NewMRReviewerConsumerWithBot(db, mockBot, nil, 0, nil)
+	consumer.ProcessConflictNotifications()
+	consumer.ProcessConflictNotifications()
+
+	sent := mockBot.GetSentMessages()
+	if len(sent) != 2 {
+		t.Fatalf("expected 2 DMs, got %d", len(sent))
+	}
+	byChat := make(map[string]string)
+	for _, m := range sent {
+		byChat[m.ChatID] = m.Text
+	}
+	if !containsAll(byChat["alice@example.com"], "merge conflicts", conflicted.WebURL) {
+		t.Errorf("expected conflict DM to alice, got %q", byChat["alice@example.com"])
+	}
+	if !containsAll(byChat["bob@example.com"], "needs a rebase", rebase.WebURL) {
+		t.Errorf("expected rebase DM to bob, got %q", byChat["bob@example.com"])
+	}
+}
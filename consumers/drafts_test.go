@@ -0,0 +1,116 @@
+package consumers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"devstreamlinebot/mocks"
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+// TestProcessDraftReadyNotifications tests that reviewers who have not approved get a DM and the
+// subscribed chat a post when an MR leaves draft, and that MRs without reviewers are left to AssignReviewers.
+func TestProcessDraftReadyNotifications(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	mockBot := mocks.NewMockVKBot()
+	userFactory := testutils.NewUserFactory(db)
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	chat := testutils.NewChatFactory(db).Create()
+	testutils.CreateSubscription(db, repo, chat, testutils.NewVKUserFactory(db).Create())
+	author := userFactory.Create(testutils.WithEmail("author@example.com"))
+	pending := userFactory.Create(testutils.WithEmail("pending@example.com"))
+	approver := userFactory.Create(testutils.WithEmail("approver@example.com"))
+
+	mrFactory := testutils.NewMergeRequestFactory(db)
+	ready := mrFactory.Create(repo, author, testutils.WithTitle("Ready change"))
+	testutils.AssignReviewers(db, &ready, pending, approver)
+	testutils.AssignApprovers(db, &ready, approver)
+	unassigned := mrFactory.Create(repo, author, testutils.WithTitle("Unassigned change"))
+
+	now := time.Now()
+	testutils.CreateMRAction(db, ready, models.ActionDraftToggled, testutils.WithTimestamp(now), testutils.WithMetadata(`{"draft":false}`))
+	testutils.CreateMRAction(db, unassigned, models.ActionDraftToggled, testutils.WithTimestamp(now), testutils.WithMetadata(`{"draft":false}`))
+
+	consumer := NewMRReviewerConsumerWithBot(db, mockBot, nil, 0, nil)
+	consumer.ProcessDraftReadyNotifications()
+	consumer.ProcessDraftReadyNotifications()
+
+	sent := mockBot.GetSentMessages()
+	if len(sent) != 2 {
+		t.Fatalf("expected a DM and a chat post, got %d messages", len(sent))
+	}
+	var dm, post bool
+	for _, m := range sent {
+		switch m.ChatID {
+		case "pending@example.com":
+			dm = strings.Contains(m.Text, "ready for review")
+		case chat.ChatID:
+			post = containsAll(m.Text, "Ready for review", "Ready change", "pending@example.com")
+		default:
+			t.Errorf("unexpected message to %s: %q", m.ChatID, m.Text)
+		}
+	}
+	if !dm || !post {
+		t.Errorf("expected DM to the pending reviewer and a chat post, got %+v", sent)
+	}
+
+	var unnotified int64
+	db.Model(&models.MRAction{}).Where("notified = ?", false).Count(&unnotified)
+	if unnotified != 0 {
+		t.Errorf("expected all draft toggles marked notified, got %d pending", unnotified)
+	}
+}
+
+// TestAssignReviewers_Drafts tests that draft MRs get reviewers only with /sla drafts assign, and that
+// the first assignment after leaving draft is announced as ready for review.
+func TestAssignReviewers_Drafts(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+	mockBot := mocks.NewMockVKBot()
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	chat := testutils.NewChatFactory(db).Create()
+	testutils.CreateSubscription(db, repo, chat, testutils.NewVKUserFactory(db).Create())
+	author := userFactory.Create()
+	testutils.CreatePossibleReviewer(db, repo, userFactory.Create(testutils.WithUsername("bob")))
+	db.Create(&models.RepositorySLA{RepositoryID: repo.ID, AssignCount: 1, MaxAutoReassigns: 1})
+
+	mrFactory := testutils.NewMergeRequestFactory(db)
+	mr := mrFactory.Create(repo, author)
+	db.Model(&mr).UpdateColumn("draft", true)
+
+	mrService := &mocks.MockMergeRequestsService{}
+	consumer := NewMRReviewerConsumerWithServices(db, mockBot, mrService, nil, 0, nil)
+
+	consumer.AssignReviewers()
+	if len(mrService.UpdateMergeRequestCalls) != 0 {
+		t.Fatalf("expected draft MR assignment deferred, got %d updates", len(mrService.UpdateMergeRequestCalls))
+	}
+
+	db.Model(&mr).UpdateColumn("draft", false)
+	testutils.CreateMRAction(db, mr, models.ActionDraftToggled, testutils.WithMetadata(`{"draft":false}`))
+	consumer.AssignReviewers()
+	if len(mrService.UpdateMergeRequestCalls) != 1 {
+		t.Fatalf("expected reviewers assigned after leaving draft, got %d updates", len(mrService.UpdateMergeRequestCalls))
+	}
+	var announced bool
+	for _, m := range mockBot.GetSentMessages() {
+		if m.ChatID == chat.ChatID && strings.HasPrefix(m.Text, "✅ Ready for review") {
+			announced = true
+		}
+	}
+	if !announced {
+		t.Errorf("expected the assignment announced as ready for review, got %+v", mockBot.GetSentMessages())
+	}
+
+	draft := mrFactory.Create(repo, author)
+	db.Model(&draft).UpdateColumn("draft", true)
+	db.Model(&models.RepositorySLA{}).Where("repository_id = ?", repo.ID).Update("assign_drafts", true)
+	consumer.AssignReviewers()
+	if len(mrService.UpdateMergeRequestCalls) != 2 {
+		t.Errorf("expected draft MR assigned with assign_drafts on, got %d updates", len(mrService.UpdateMergeRequestCalls))
+	}
+}
@@ -0,0 +1,155 @@
+package consumers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"devstreamlinebot/mocks"
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+// TestSelectReviewers_SkipsInactiveUsers tests that blocked, locked and non-member pool users are never picked.
+func TestSelectReviewers_SkipsInactiveUsers(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	active := userFactory.Create(testutils.WithUsername("active"))
+	blocked := userFactory.Create(testutils.WithUsername("blocked"))
+	locked := userFactory.Create(testutils.WithUsername("locked"))
+	departed := userFactory.Create(testutils.WithUsername("departed"))
+	db.Model(&blocked).Update("state", "blocked")
+	db.Model(&locked).Update("locked", true)
+	for _, u := range []models.User{active, blocked, locked, departed} {
+		testutils.CreatePossibleReviewer(db, repo, u)
+	}
+	db.Create(&models.RepositoryMember{RepositoryID: repo.ID, UserID: active.ID})
+	db.Create(&models.RepositoryMember{RepositoryID: repo.ID, UserID: blocked.ID})
+	db.Create(&models.RepositoryMember{RepositoryID: repo.ID, UserID: locked.ID})
+	db.Model(&repo).Update("members_synced_at", time.Now())
+
+	created := testutils.NewMergeRequestFactory(db).Create(repo, author)
+	consumer := NewMRReviewerConsumerWithServices(db, nil, nil, nil, 0, nil)
+	mr := loadMRForReassign(t, consumer, created.ID)
+
+	selected, trace := consumer.selectReviewersWithTrace(&mr, 3, nil)
+	if len(selected) != 1 || selected[0].ID != active.ID {
+		t.Fatalf("expected only the active member, got %v", selected)
+	}
+	reasons := make(map[string]string)
+	for _, e := range trace.Excluded {
+		reasons[e.Username] = e.Reason
+	}
+	if reasons["blocked"] != "blocked" || reasons["locked"] != "locked" || reasons["departed"] != "not a project member" {
+		t.Errorf("unexpected exclusion reasons: %v", reasons)
+	}
+}
+
+// TestReplaceInactiveReviewers_ReplacesAndNotifies tests that a blocked reviewer is swapped and the chat told why.
+func TestReplaceInactiveReviewers_ReplacesAndNotifies(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+	mockBot := mocks.NewMockVKBot()
+	mrService := &mocks.MockMergeRequestsService{}
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	chat := testutils.NewChatFactory(db).Create()
+	testutils.CreateSubscription(db, repo, chat, testutils.NewVKUserFactory(db).Create())
+	author := userFactory.Create()
+	gone := userFactory.Create(testutils.WithUsername("gone"))
+	spare := userFactory.Create(testutils.WithUsername("spare"))
+	approver := userFactory.Create(testutils.WithUsername("approver"))
+	testutils.CreatePossibleReviewer(db, repo, gone)
+	testutils.CreatePossibleReviewer(db, repo, spare)
+
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author)
+	testutils.AssignReviewers(db, &mr, gone, approver)
+	testutils.AssignApprovers(db, &mr, approver)
+	db.Model(&gone).Update("state", "deactivated")
+	db.Model(&approver).Update("state", "blocked")
+
+	consumer := NewMRReviewerConsumerWithServices(db, mockBot, mrService, nil, 0, nil)
+	consumer.ReplaceInactiveReviewers()
+
+	if len(mrService.UpdateMergeRequestCalls) != 1 {
+		t.Fatalf("expected 1 GitLab update, got %d", len(mrService.UpdateMergeRequestCalls))
+	}
+	reloaded := loadMRForReassign(t, consumer, mr.ID)
+	ids := make(map[uint]bool)
+	for _, r := range reloaded.Reviewers {
+		ids[r.ID] = true
+	}
+	if len(ids) != 2 || !ids[spare.ID] || !ids[approver.ID] {
+		t.Errorf("expected spare to replace gone and the approver kept, got %v", reloaded.Reviewers)
+	}
+
+	sent := mockBot.GetSentMessages()
+	if len(sent) == 0 || !strings.Contains(sent[0].Text, "Reviewer account is deactivated") {
+		t.Errorf("expected chat notification with reason, got %+v", sent)
+	}
+}
+
+// TestReplaceInactiveReviewers_NotCountedAsAutoReassign tests that replacing an inactive reviewer
+// leaves the MaxAutoReassigns budget of stale reassignments untouched.
+func TestReplaceInactiveReviewers_NotCountedAsAutoReassign(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	chat := testutils.NewChatFactory(db).Create()
+	testutils.CreateSubscription(db, repo, chat, testutils.NewVKUserFactory(db).Create())
+	author := userFactory.Create()
+	gone := userFactory.Create()
+	spare := userFactory.Create()
+	testutils.CreatePossibleReviewer(db, repo, spare)
+
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author)
+	testutils.AssignReviewers(db, &mr, gone)
+	db.Model(&gone).Update("state", "blocked")
+
+	consumer := NewMRReviewerConsumerWithServices(db, mocks.NewMockVKBot(), &mocks.MockMergeRequestsService{}, nil, 0, nil)
+	consumer.ReplaceInactiveReviewers()
+
+	reloaded := loadMRForReassign(t, consumer, mr.ID)
+	if len(reloaded.Reviewers) != 1 || reloaded.Reviewers[0].ID != spare.ID {
+		t.Fatalf("expected spare to replace the blocked reviewer, got %v", reloaded.Reviewers)
+	}
+	if got := consumer.countAutoReassigns(mr.ID); got != 0 {
+		t.Errorf("countAutoReassigns() = %d, want 0", got)
+	}
+}
+
+// TestReplaceInactiveReviewers_WarnsOnceWithoutReplacement tests that the chat is warned once
+// when nobody can replace an inactive reviewer.
+func TestReplaceInactiveReviewers_WarnsOnceWithoutReplacement(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+	mockBot := mocks.NewMockVKBot()
+	mrService := &mocks.MockMergeRequestsService{}
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	chat := testutils.NewChatFactory(db).Create()
+	testutils.CreateSubscription(db, repo, chat, testutils.NewVKUserFactory(db).Create())
+	author := userFactory.Create()
+	gone := userFactory.Create(testutils.WithUsername("gone"))
+
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author)
+	testutils.AssignReviewers(db, &mr, gone)
+	db.Model(&gone).Update("state", "deactivated")
+
+	consumer := NewMRReviewerConsumerWithServices(db, mockBot, mrService, nil, 0, nil)
+	consumer.ReplaceInactiveReviewers()
+	consumer.ReplaceInactiveReviewers()
+
+	if len(mrService.UpdateMergeRequestCalls) != 0 {
+		t.Errorf("expected no GitLab update, got %d", len(mrService.UpdateMergeRequestCalls))
+	}
+	sent := mockBot.GetSentMessages()
+	if len(sent) != 1 || !strings.Contains(sent[0].Text, "No replacement available") ||
+		!strings.Contains(sent[0].Text, "Reviewer account is deactivated") {
+		t.Errorf("expected a single warning, got %+v", sent)
+	}
+}
@@ -0,0 +1,294 @@
+package consumers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	botgolang "github.com/mail-ru-im/bot-golang"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"gorm.io/gorm"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/utils"
+)
+
+const mrCommandUsage = "Usage:\n" +
+	"/mr <project_path!iid> label add|remove <label>\n" +
+	"/mr <project_path!iid> block <reason>\n" +
+	"/mr <project_path!iid> draft on|off\n" +
+	"/mr <project_path!iid> reviewers [username ...]\n" +
+	"/mr <project_path!iid> ready"
+
+// draftPrefixRe matches the title prefixes GitLab treats as marking an MR as draft.
+var draftPrefixRe = regexp.MustCompile(`(?i)^\s*(\[draft\]|\(draft\)|draft:|draft\s+-)\s*`)
+
+// draftTitle returns the MR title with the draft prefix added or removed.
+func draftTitle(title string, draft bool) string {
+	stripped := draftPrefixRe.ReplaceAllString(title, "")
+	if draft {
+		return "Draft: " + stripped
+	}
+	return stripped
+}
+
+// mrActionDenial returns why the user may not run the /mr action on the MR, or "" if allowed.
+// Release managers of the repository may run every action. Otherwise "ready" requires at least
+// Maintainer access and all other actions Developer access or being the MR's author.
+func mrActionDenial(db *gorm.DB, user *models.User, mr *models.MergeRequest, action string) string {
+	var managers int64
+	db.Model(&models.ReleaseManager{}).Where("repository_id = ? AND user_id = ?", mr.RepositoryID, user.ID).Count(&managers)
+	if managers > 0 {
+		return ""
+	}
+
+	var member models.RepositoryMember
+	accessLevel := 0
+	if err := db.Where("repository_id = ? AND user_id = ?", mr.RepositoryID, user.ID).First(&member).Error; err == nil {
+		accessLevel = member.AccessLevel
+	}
+
+	if action == "ready" {
+		if accessLevel >= int(gitlab.MaintainerPermissions) {
+			return ""
+		}
+		return "Only release managers and maintainers can mark MRs ready for release."
+	}
+	if accessLevel >= int(gitlab.DeveloperPermissions) || mr.AuthorID == user.ID {
+		return ""
+	}
+	return "Only release managers, developers of the project and the MR author can change this MR."
+}
+
+// handleMRCommand runs an action on an MR of a repository subscribed in this chat and replies in
+// the command's thread. Format: /mr <project_path!iid> <action> [args]
+// Changes are made in GitLab only; polling picks them up and records the resulting actions.
+func (c *VKCommandConsumer) handleMRCommand(msg *botgolang.Message, from botgolang.Contact) {
+	parts := strings.Fields(msg.Text)
+	if len(parts) < 3 {
+		c.replyInThread(msg, mrCommandUsage)
+		return
+	}
+	projectPath, mrIID, err := parseMRReference(parts[1])
+	if err != nil {
+		c.replyInThread(msg, "Invalid reference format. Use <project_path!iid> (e.g., intdev/jobofferapp!2103)")
+		return
+	}
+	action, args := strings.ToLower(parts[2]), parts[3:]
+
+	chatID := fmt.Sprint(msg.Chat.ID)
+	var chat models.Chat
+	if err := c.db.Where("chat_id = ?", chatID).First(&chat).Error; err != nil {
+		c.replyInThread(msg, "Chat not found")
+		return
+	}
+
+	repo, err := utils.FindRepositoryByIdentifier(c.db, projectPath)
+	if err != nil {
+		c.replyInThread(msg, "Repository not found for this reference.")
+		return
+	}
+	var subscribed int64
+	c.db.Model(&models.RepositorySubscription{}).Where("chat_id = ? AND repository_id = ?", chat.ID, repo.ID).Count(&subscribed)
+	if subscribed == 0 {
+		c.replyInThread(msg, fmt.Sprintf("This chat is not subscribed to %s.", repo.PathWithNamespace))
+		return
+	}
+
+	var mr models.MergeRequest
+	if err := c.db.Preload("Repository").Preload("Author").Preload("Reviewers").
+		Where("repository_id = ? AND i_id = ?", repo.ID, mrIID).First(&mr).Error; err != nil {
+		c.replyInThread(msg, "Merge request not found in local database.")
+		return
+	}
+	if mr.State != "opened" {
+		c.replyInThread(msg, "Merge request is not open.")
+		return
+	}
+
+	sender := c.findLinkedUser(from)
+	if sender == nil {
+		c.replyInThread(msg, "Your VK account is not linked to a GitLab user.")
+		return
+	}
+	if action == "reviewers" && len(args) == 0 {
+		c.replyInThread(msg, formatMRReviewers(&mr))
+		return
+	}
+	if denial := mrActionDenial(c.db, sender, &mr, action); denial != "" {
+		c.replyInThread(msg, denial)
+		return
+	}
+
+	ref := fmt.Sprintf("%s!%d", repo.PathWithNamespace, mr.IID)
+	var result string
+	switch action {
+	case "label":
+		result = c.mrLabel(&mr, ref, args)
+	case "block":
+		result = c.mrBlock(&mr, ref, sender, strings.Join(args, " "))
+	case "draft":
+		result = c.mrDraft(&mr, ref, args)
+	case "reviewers":
+		result = c.mrReviewers(&mr, ref, args)
+	case "ready":
+		result = c.mrReady(&mr, ref)
+	default:
+		result = mrCommandUsage
+	}
+	c.replyInThread(msg, result)
+}
+
+// mrLabel adds or removes a label: label add|remove <label>.
+func (c *VKCommandConsumer) mrLabel(mr *models.MergeRequest, ref string, args []string) string {
+	if len(args) < 2 {
+		return "Usage: /mr <project_path!iid> label add|remove <label>"
+	}
+	label := strings.Join(args[1:], " ")
+	labels := gitlab.LabelOptions{label}
+	opts := &gitlab.UpdateMergeRequestOptions{}
+	switch strings.ToLower(args[0]) {
+	case "add":
+		opts.AddLabels = &labels
+	case "remove":
+		opts.RemoveLabels = &labels
+	default:
+		return "Usage: /mr <project_path!iid> label add|remove <label>"
+	}
+	if err := c.updateMR(mr, opts); err != nil {
+		return fmt.Sprintf("Failed to update labels of %s: %v", ref, err)
+	}
+	if opts.AddLabels != nil {
+		return fmt.Sprintf("Added label %q to %s", label, ref)
+	}
+	return fmt.Sprintf("Removed label %q from %s", label, ref)
+}
+
+// mrBlock applies the repository's block label and records the reason as a note on the MR.
+func (c *VKCommandConsumer) mrBlock(mr *models.MergeRequest, ref string, sender *models.User, reason string) string {
+	if reason == "" {
+		return "Usage: /mr <project_path!iid> block <reason>"
+	}
+	var blockLabel models.BlockLabel
+	if err := c.db.Where("repository_id = ?", mr.RepositoryID).Order("id ASC").First(&blockLabel).Error; err != nil {
+		return "No block label configured for this repository. Use /add_block_label first."
+	}
+
+	if err := c.updateMR(mr, &gitlab.UpdateMergeRequestOptions{AddLabels: &gitlab.LabelOptions{blockLabel.LabelName}}); err != nil {
+		return fmt.Sprintf("Failed to block %s: %v", ref, err)
+	}
+
+	note := fmt.Sprintf("⛔ Blocked by @%s: %s", sender.Username, reason)
+	if _, _, err := c.glClient.Notes.CreateMergeRequestNote(mr.Repository.GitlabID, mr.IID,
+		&gitlab.CreateMergeRequestNoteOptions{Body: gitlab.Ptr(note)}); err != nil {
+		log.Printf("failed to post block reason on MR %d: %v", mr.ID, err)
+	}
+	metadata, _ := json.Marshal(map[string]string{"reason": reason})
+	if err := c.db.Create(&models.MRAction{
+		MergeRequestID: mr.ID,
+		ActionType:     models.ActionBlockRequested,
+		ActorID:        &sender.ID,
+		Timestamp:      time.Now().UTC(),
+		Metadata:       string(metadata),
+		Notified:       true,
+	}).Error; err != nil {
+		log.Printf("failed to record block of MR %d: %v", mr.ID, err)
+	}
+	return fmt.Sprintf("Blocked %s with %q\nReason: %s", ref, blockLabel.LabelName, reason)
+}
+
+// mrDraft marks the MR as draft or ready for review by editing its title prefix.
+func (c *VKCommandConsumer) mrDraft(mr *models.MergeRequest, ref string, args []string) string {
+	if len(args) != 1 || (strings.ToLower(args[0]) != "on" && strings.ToLower(args[0]) != "off") {
+		return "Usage: /mr <project_path!iid> draft on|off"
+	}
+	draft := strings.ToLower(args[0]) == "on"
+	if draft == mr.Draft {
+		if draft {
+			return fmt.Sprintf("%s is already a draft", ref)
+		}
+		return fmt.Sprintf("%s is not a draft", ref)
+	}
+	if err := c.updateMR(mr, &gitlab.UpdateMergeRequestOptions{Title: gitlab.Ptr(draftTitle(mr.Title, draft))}); err != nil {
+		return fmt.Sprintf("Failed to update %s: %v", ref, err)
+	}
+	if draft {
+		return fmt.Sprintf("Marked %s as draft", ref)
+	}
+	return fmt.Sprintf("Marked %s as ready for review", ref)
+}
+
+// mrReviewers replaces the MR's reviewers with the given usernames.
+func (c *VKCommandConsumer) mrReviewers(mr *models.MergeRequest, ref string, args []string) string {
+	var reviewerIDs []int
+	var names []string
+	for _, arg := range args {
+		for _, username := range strings.Split(arg, ",") {
+			username = strings.TrimPrefix(strings.TrimSpace(username), "@")
+			if username == "" {
+				continue
+			}
+			user, ok := c.findOrFetchUser(username)
+			if !ok {
+				return fmt.Sprintf("User %s not found", username)
+			}
+			if reason := utils.UserIneligibleReason(c.db, mr.RepositoryID, &user); reason != "" {
+				return fmt.Sprintf("%s cannot review: %s", username, reason)
+			}
+			reviewerIDs = append(reviewerIDs, user.GitlabID)
+			names = append(names, user.Username)
+		}
+	}
+	if len(reviewerIDs) == 0 {
+		return "Usage: /mr <project_path!iid> reviewers [username ...]"
+	}
+	if err := c.updateMR(mr, &gitlab.UpdateMergeRequestOptions{ReviewerIDs: &reviewerIDs}); err != nil {
+		return fmt.Sprintf("Failed to set reviewers of %s: %v", ref, err)
+	}
+	return fmt.Sprintf("Reviewers of %s: %s", ref, strings.Join(names, ", "))
+}
+
+// mrReady applies the repository's release-ready label.
+func (c *VKCommandConsumer) mrReady(mr *models.MergeRequest, ref string) string {
+	var readyLabel models.ReleaseReadyLabel
+	if err := c.db.Where("repository_id = ?", mr.RepositoryID).First(&readyLabel).Error; err != nil {
+		return "No release-ready label configured for this repository. Use /add_release_ready_label first."
+	}
+	if err := c.updateMR(mr, &gitlab.UpdateMergeRequestOptions{AddLabels: &gitlab.LabelOptions{readyLabel.LabelName}}); err != nil {
+		return fmt.Sprintf("Failed to mark %s ready for release: %v", ref, err)
+	}
+	return fmt.Sprintf("Marked %s ready for release with %q", ref, readyLabel.LabelName)
+}
+
+// updateMR applies opts to the MR in GitLab.
+func (c *VKCommandConsumer) updateMR(mr *models.MergeRequest, opts *gitlab.UpdateMergeRequestOptions) error {
+	if _, _, err := c.glClient.MergeRequests.UpdateMergeRequest(mr.Repository.GitlabID, mr.IID, opts); err != nil {
+		log.Printf("failed to update MR %d: %v", mr.ID, err)
+		return err
+	}
+	return nil
+}
+
+// formatMRReviewers lists the MR's current reviewers.
+func formatMRReviewers(mr *models.MergeRequest) string {
+	if len(mr.Reviewers) == 0 {
+		return fmt.Sprintf("%s!%d has no reviewers", mr.Repository.PathWithNamespace, mr.IID)
+	}
+	names := make([]string, len(mr.Reviewers))
+	for i, r := range mr.Reviewers {
+		names[i] = r.Username
+	}
+	return fmt.Sprintf("Reviewers of %s!%d: %s", mr.Repository.PathWithNamespace, mr.IID, strings.Join(names, ", "))
+}
+
+// replyInThread replies to the command message so the result lands in its thread.
+func (c *VKCommandConsumer) replyInThread(msg *botgolang.Message, text string) {
+	replyMsg := c.vkBot.NewTextMessage(fmt.Sprint(msg.Chat.ID), text)
+	replyMsg.ReplyMsgID = msg.ID
+	if err := replyMsg.Send(); err != nil {
+		log.Printf("failed to send reply message: %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package consumers
+
+import (
+	"testing"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+// TestDraftTitle tests adding and removing the draft prefix of MR titles.
+func TestDraftTitle(t *testing.T) {
+	tests := []struct {
+		title string
+		draft bool
+		want  string
+	}{
+		{"Add login", true, "Draft: Add login"},
+		{"Draft: Add login", true, "Draft: Add login"},
+		{"Draft: Add login", false, "Add login"},
+		{"[Draft] Add login", false, "Add login"},
+		{"(draft) Add login", false, "Add login"},
+		{"Draft - Add login", false, "Add login"},
+		{"Drafting rules", false, "Drafting rules"},
+	}
+	for _, tt := range tests {
+		if got := draftTitle(tt.title, tt.draft); got != tt.want {
+			t.Errorf("draftTitle(%q, %v) = %q, want %q", tt.title, tt.draft, got, tt.want)
+		}
+	}
+}
+
+// TestMRActionDenial tests which users may run /mr actions: release managers always, developers
+// and the author for everything but "ready", which needs a maintainer.
+func TestMRActionDenial(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	manager := userFactory.Create()
+	maintainer := userFactory.Create()
+	developer := userFactory.Create()
+	reporter := userFactory.Create()
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author)
+
+	db.Create(&models.ReleaseManager{RepositoryID: repo.ID, UserID: manager.ID})
+	db.Create(&models.RepositoryMember{RepositoryID: repo.ID, UserID: maintainer.ID, AccessLevel: int(gitlab.MaintainerPermissions)})
+	db.Create(&models.RepositoryMember{RepositoryID: repo.ID, UserID: developer.ID, AccessLevel: int(gitlab.DeveloperPermissions)})
+	db.Create(&models.RepositoryMember{RepositoryID: repo.ID, UserID: reporter.ID, AccessLevel: int(gitlab.ReporterPermissions)})
+
+	tests := []struct {
+		name    string
+		user    models.User
+		action  string
+		allowed bool
+	}{
+		{"manager ready", manager, "ready", true},
+		{"manager label", manager, "label", true},
+		{"maintainer ready", maintainer, "ready", true},
+		{"developer block", developer, "block", true},
+		{"developer ready", developer, "ready", false},
+		{"author draft", author, "draft", true},
+		{"author ready", author, "ready", false},
+		{"reporter label", reporter, "label", false},
+	}
+	for _, tt := range tests {
+		if got := mrActionDenial(db, &tt.user, &mr, tt.action) == ""; got != tt.allowed {
+			t.Errorf("%s: allowed = %v, want %v", tt.name, got, tt.allowed)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package consumers
+
+import "testing"
+
+// TestParseMRReference_Valid tests parsing of path!iid references.
+func TestParseMRReference_Valid(t *testing.T) {
+	path, iid, err := parseMRReference(" intdev/jobofferapp!2103 ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != "intdev/jobofferapp" || iid != 2103 {
+		t.Errorf("expected intdev/jobofferapp and 2103, got %s and %d", path, iid)
+	}
+}
+
+// TestParseMRReference_Invalid tests rejection of malformed references.
+func TestParseMRReference_Invalid(t *testing.T) {
+	for _, ref := range []string{"", "project", "!12", "project!", "project!abc", "project!0"} {
+		if _, _, err := parseMRReference(ref); err == nil {
+			t.Errorf("expected error for %q", ref)
+		}
+	}
+}
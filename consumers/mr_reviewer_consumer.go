@@ -1,6 +1,7 @@
 package consumers
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
@@ -41,11 +42,13 @@ func (r *threadSafeRand) Float64() float64 {
 }
 
 type MRReviewerConsumer struct {
-	db        *gorm.DB
-	vkBot     interfaces.VKBot
-	glClient  *gitlab.Client
-	interval  time.Duration
-	startTime time.Time
+	db           *gorm.DB
+	vkBot        interfaces.VKBot
+	mrService    interfaces.GitLabMergeRequestsService
+	filesService interfaces.GitLabRepositoryFilesService
+	interval     time.Duration
+	startTime    time.Time
+	replayTime   *time.Time // Set by simulations: selection treats it as the current time
 }
 
 func NewMRReviewerConsumer(db *gorm.DB, vkBot *botgolang.Bot, glClient *gitlab.Client, interval time.Duration, startTime *time.Time) *MRReviewerConsumer {
@@ -53,30 +56,39 @@ func NewMRReviewerConsumer(db *gorm.DB, vkBot *botgolang.Bot, glClient *gitlab.C
 	if vkBot != nil {
 		bot = &interfaces.RealVKBot{Bot: vkBot}
 	}
-	st := time.Now().AddDate(0, 0, -2)
-	if startTime != nil {
-		st = *startTime
-	}
-	return &MRReviewerConsumer{
-		db:        db,
-		vkBot:     bot,
-		glClient:  glClient,
-		interval:  interval,
-		startTime: st,
-	}
+	return NewMRReviewerConsumerWithBot(db, bot, glClient, interval, startTime)
 }
 
 func NewMRReviewerConsumerWithBot(db *gorm.DB, vkBot interfaces.VKBot, glClient *gitlab.Client, interval time.Duration, startTime *time.Time) *MRReviewerConsumer {
+	var mrService interfaces.GitLabMergeRequestsService
+	var filesService interfaces.GitLabRepositoryFilesService
+	if glClient != nil {
+		mrService = glClient.MergeRequests
+		filesService = glClient.RepositoryFiles
+	}
+	return NewMRReviewerConsumerWithServices(db, vkBot, mrService, filesService, interval, startTime)
+}
+
+// NewMRReviewerConsumerWithServices creates a consumer with injected GitLab services for testing.
+func NewMRReviewerConsumerWithServices(
+	db *gorm.DB,
+	vkBot interfaces.VKBot,
+	mrService interfaces.GitLabMergeRequestsService,
+	filesService interfaces.GitLabRepositoryFilesService,
+	interval time.Duration,
+	startTime *time.Time,
+) *MRReviewerConsumer {
 	st := time.Now().AddDate(0, 0, -2)
 	if startTime != nil {
 		st = *startTime
 	}
 	return &MRReviewerConsumer{
-		db:        db,
-		vkBot:     vkBot,
-		glClient:  glClient,
-		interval:  interval,
-		startTime: st,
+		db:           db,
+		vkBot:        vkBot,
+		mrService:    mrService,
+		filesService: filesService,
+		interval:     interval,
+		startTime:    st,
 	}
 }
 
@@ -85,10 +97,22 @@ func (c *MRReviewerConsumer) StartConsumer() {
 	go func() {
 		defer ticker.Stop()
 		for range ticker.C {
+			c.ApplySizeLabels()
+			c.ProcessDraftReadyNotifications()
 			c.AssignReviewers()
+			c.ReassignStaleReviews()
+			c.ReplaceInactiveReviewers()
 			c.ProcessStateChangeNotifications()
 			c.ProcessReviewerRemovalNotifications()
 			c.ProcessFullyApprovedNotifications()
+			c.ProcessAutoMerge()
+			c.ProcessPipelineNotifications()
+			c.ProcessConflictNotifications()
+			c.ProcessAutoRebase()
+			c.ProcessReReviewRequests()
+			c.ProcessSLAWarnings()
+			c.ProcessStaleMRs()
+			c.FlushQueuedNotifications()
 		}
 	}()
 }
@@ -129,7 +153,8 @@ func (c *MRReviewerConsumer) getLabelReviewerGroups(mr *models.MergeRequest, exc
 	}
 
 	var users []models.User
-	if err := c.db.Where("id IN ? AND id NOT IN ? AND on_vacation = ?", userIDs, excludeIDs, false).
+	if err := c.db.Scopes(utils.ActiveRepositoryUsers(mr.RepositoryID)).
+		Where("id IN ? AND id NOT IN ? AND on_vacation = ?", userIDs, excludeIDs, false).
 		Find(&users).Error; err != nil {
 		log.Printf("failed to fetch label reviewer users: %v", err)
 		return nil
@@ -183,7 +208,8 @@ func (c *MRReviewerConsumer) getDefaultReviewers(mr *models.MergeRequest, exclud
 	}
 
 	var users []models.User
-	if err := c.db.Where("id IN ? AND id NOT IN ? AND on_vacation = ?", userIDs, excludeIDs, false).
+	if err := c.db.Scopes(utils.ActiveRepositoryUsers(mr.RepositoryID)).
+		Where("id IN ? AND id NOT IN ? AND on_vacation = ?", userIDs, excludeIDs, false).
 		Find(&users).Error; err != nil {
 		log.Printf("failed to fetch default reviewer users: %v", err)
 		return nil
@@ -193,46 +219,208 @@ func (c *MRReviewerConsumer) getDefaultReviewers(mr *models.MergeRequest, exclud
 }
 
 // selectReviewers implements the reviewer selection algorithm:
-// 1. If MR has labels with configured label reviewers:
-//   - Pick exactly 1 from each label group (no reuse across groups)
+// 1. If CODEOWNERS sections or path reviewer rules match the changed paths, or MR has labels with configured label reviewers:
+//   - Pick exactly 1 from each CODEOWNERS section, path group and label group (no reuse across groups)
 //   - If total < minCount, pick additional from combined remaining pool
 //
-// 2. If no label reviewers available, pick minCount from default pool
+// 2. If no group reviewers available, pick minCount from default pool
+//
+// Reviewers missing from the repository's required tier are picked first. Their slots count towards
+// minCount and stay empty rather than going to other reviewers when nobody of the tier is available.
 func (c *MRReviewerConsumer) selectReviewers(mr *models.MergeRequest, minCount int, excludeUsers []models.User) []models.User {
+	selected, _ := c.selectReviewersWithTrace(mr, minCount, excludeUsers)
+	return selected
+}
+
+// selectReviewersWithTrace runs the selection algorithm and records candidate pools,
+// exclusions and pick probabilities so the choice can be explained later.
+func (c *MRReviewerConsumer) selectReviewersWithTrace(mr *models.MergeRequest, minCount int, excludeUsers []models.User) ([]models.User, *selectionTrace) {
 	if minCount <= 0 {
 		minCount = 1
 	}
 
+	trace := &selectionTrace{Needed: minCount}
+	existing := excludeUsers
+	atCapacity := c.getUsersAtCapacity(mr.RepositoryID)
+	c.traceExclusions(mr, excludeUsers, atCapacity, trace)
+	excludeUsers = append(append([]models.User{}, excludeUsers...), atCapacity...)
+
+	groups := make(map[string][]models.User)
+	changedPaths := c.getSelectionPaths(mr)
+	strategy := c.strategyFor(mr, changedPaths)
+	trace.Strategy = strategy.Name()
+
+	tierPicked, tierSlots := c.selectRequiredTier(mr, existing, excludeUsers, strategy, trace)
+	excludeUsers = append(excludeUsers, tierPicked...)
+	remaining := minCount - tierSlots
+	if remaining <= 0 && len(existing) > 0 {
+		// Backfill only for the tier: other groups were covered when the MR was first assigned.
+		return tierPicked, trace
+	}
+
+	codeOwnerGroups := c.getCodeOwnerGroups(mr, changedPaths, excludeUsers)
+	trace.setCodeOwners(codeOwnerGroups)
+	for section, users := range codeOwnerGroups {
+		groups["codeowners "+section] = users
+	}
+	pathGroups := c.getPathReviewerGroups(mr, changedPaths, excludeUsers)
+	trace.setPathGroups(pathGroups)
+	for pattern, users := range pathGroups {
+		groups["path "+pattern] = users
+	}
 	labelGroups := c.getLabelReviewerGroups(mr, excludeUsers)
-	if len(labelGroups) > 0 {
-		return c.selectFromLabelGroups(mr, labelGroups, minCount, excludeUsers)
+	trace.setLabelGroups(labelGroups)
+	for label, users := range labelGroups {
+		groups["label "+label] = users
+	}
+	if len(groups) > 0 {
+		return append(tierPicked, c.selectFromGroups(mr, groups, remaining, excludeUsers, strategy, trace)...), trace
+	}
+	if remaining <= 0 {
+		return tierPicked, trace
 	}
 
 	defaultReviewers := c.getDefaultReviewers(mr, excludeUsers)
 	if len(defaultReviewers) == 0 {
+		return tierPicked, trace
+	}
+
+	userIDs := make([]uint, len(defaultReviewers))
+	for i, u := range defaultReviewers {
+		userIDs[i] = u.ID
+	}
+	reviewCounts := c.getReviewCountsForUserIDs(userIDs)
+
+	selected := c.pickMultipleWith(strategy, defaultReviewers, remaining, reviewCounts)
+	trace.addStep(strategy, "default", defaultReviewers, reviewCounts, selected)
+	return append(tierPicked, selected...), trace
+}
+
+// getUsersAtCapacity returns users who reached their concurrent open review limit
+// for the repository and must be skipped by selection.
+func (c *MRReviewerConsumer) getUsersAtCapacity(repoID uint) []models.User {
+	repoLimit := 0
+	if sla, err := utils.GetRepositorySLA(c.db, repoID); err == nil {
+		repoLimit = sla.MaxOpenReviews
+	}
+	users, err := utils.GetUsersAtCapacity(c.db, repoLimit)
+	if err != nil {
+		log.Printf("failed to fetch reviewers at capacity: %v", err)
 		return nil
 	}
+	return users
+}
+
+// traceExclusions records pool members that were not eligible for selection and why.
+func (c *MRReviewerConsumer) traceExclusions(mr *models.MergeRequest, excludeUsers []models.User, atCapacity []models.User, trace *selectionTrace) {
+	if trace == nil {
+		return
+	}
 
-	return c.pickMultipleReviewers(defaultReviewers, minCount)
+	var poolIDs []uint
+	c.db.Model(&models.PossibleReviewer{}).Where("repository_id = ?", mr.RepositoryID).Pluck("user_id", &poolIDs)
+
+	if len(mr.Labels) > 0 {
+		labelNames := make([]string, len(mr.Labels))
+		for i, label := range mr.Labels {
+			labelNames[i] = label.Name
+		}
+		var labelPoolIDs []uint
+		c.db.Model(&models.LabelReviewer{}).
+			Where("repository_id = ? AND label_name IN ?", mr.RepositoryID, labelNames).
+			Pluck("user_id", &labelPoolIDs)
+		poolIDs = append(poolIDs, labelPoolIDs...)
+	}
+
+	if len(poolIDs) == 0 {
+		return
+	}
+
+	var users []models.User
+	if err := c.db.Where("id IN ?", poolIDs).Order("username").Find(&users).Error; err != nil {
+		log.Printf("failed to fetch pool users for selection trace: %v", err)
+		return
+	}
+
+	excluded := make(map[uint]bool)
+	for _, u := range excludeUsers {
+		excluded[u.ID] = true
+	}
+	full := make(map[uint]bool)
+	for _, u := range atCapacity {
+		full[u.ID] = true
+	}
+
+	var eligibleIDs []uint
+	if err := c.db.Model(&models.User{}).Scopes(utils.ActiveRepositoryUsers(mr.RepositoryID)).
+		Where("users.id IN ?", poolIDs).
+		Pluck("users.id", &eligibleIDs).Error; err != nil {
+		log.Printf("failed to fetch eligible pool users for selection trace: %v", err)
+		return
+	}
+	eligible := make(map[uint]bool, len(eligibleIDs))
+	for _, id := range eligibleIDs {
+		eligible[id] = true
+	}
+
+	for _, u := range users {
+		ineligible := ""
+		if !eligible[u.ID] {
+			if ineligible = utils.UserInactiveState(&u); ineligible == "" {
+				ineligible = utils.InactiveNotMember
+			}
+		}
+		switch {
+		case u.ID == mr.AuthorID:
+			trace.addExclusion(u.Username, exclusionAuthor)
+		case excluded[u.ID]:
+			trace.addExclusion(u.Username, exclusionExistingReviewer)
+		case ineligible != "":
+			trace.addExclusion(u.Username, ineligible)
+		case u.OnVacation:
+			trace.addExclusion(u.Username, exclusionVacation)
+		case full[u.ID]:
+			trace.addExclusion(u.Username, exclusionCapacity)
+		}
+	}
 }
 
-// selectFromLabelGroups picks reviewers from label groups:
+// saveSelectionTrace persists the trace of an assignment round for /why_reviewer.
+func (c *MRReviewerConsumer) saveSelectionTrace(mrID uint, trace *selectionTrace) {
+	if trace == nil {
+		return
+	}
+	data, err := json.Marshal(trace)
+	if err != nil {
+		log.Printf("failed to encode selection trace for MR %d: %v", mrID, err)
+		return
+	}
+	if err := c.db.Create(&models.ReviewerSelectionTrace{
+		MergeRequestID: mrID,
+		Trace:          string(data),
+	}).Error; err != nil {
+		log.Printf("failed to save selection trace for MR %d: %v", mrID, err)
+	}
+}
+
+// selectFromGroups picks reviewers from named groups (label groups, path groups, CODEOWNERS sections):
 // 1. Pick exactly 1 from each group (with no reuse)
-// 2. If total < minCount, pick additional from combined remaining label reviewers + default pool
-func (c *MRReviewerConsumer) selectFromLabelGroups(mr *models.MergeRequest, groups map[string][]models.User, minCount int, excludeUsers []models.User) []models.User {
+// 2. If total < minCount, pick additional from combined remaining group reviewers + default pool
+// Each draw is made by strategy.
+func (c *MRReviewerConsumer) selectFromGroups(mr *models.MergeRequest, groups map[string][]models.User, minCount int, excludeUsers []models.User, strategy ReviewerStrategy, trace *selectionTrace) []models.User {
 	reviewCounts := c.getRecentReviewCounts(groups)
 
 	selectedSet := make(map[uint]bool)
 	var selected []models.User
 
-	labelNames := make([]string, 0, len(groups))
-	for label := range groups {
-		labelNames = append(labelNames, label)
+	groupNames := make([]string, 0, len(groups))
+	for name := range groups {
+		groupNames = append(groupNames, name)
 	}
-	sort.Strings(labelNames)
+	sort.Strings(groupNames)
 
-	for _, label := range labelNames {
-		pool := groups[label]
+	for _, name := range groupNames {
+		pool := groups[name]
 
 		available := make([]models.User, 0)
 		for _, u := range pool {
@@ -242,15 +430,17 @@ func (c *MRReviewerConsumer) selectFromLabelGroups(mr *models.MergeRequest, grou
 		}
 
 		if len(available) == 0 {
-			log.Printf("No available reviewers for label %s (all already selected)", label)
+			log.Printf("No available reviewers for %s (all already selected)", name)
+			trace.addStep(strategy, name, nil, reviewCounts, nil)
 			continue
 		}
 
-		idx := c.pickReviewerFromPool(available, reviewCounts)
+		idx := strategy.Pick(available, reviewCounts)
 		picked := available[idx]
 		selected = append(selected, picked)
 		selectedSet[picked.ID] = true
-		log.Printf("Picked reviewer %s (ID %d) for label %s", picked.Username, picked.ID, label)
+		trace.addStep(strategy, name, available, reviewCounts, []models.User{picked})
+		log.Printf("Picked reviewer %s (ID %d) for %s", picked.Username, picked.ID, name)
 	}
 
 	if len(selected) < minCount {
@@ -285,18 +475,19 @@ func (c *MRReviewerConsumer) selectFromLabelGroups(mr *models.MergeRequest, grou
 		}
 
 		needed := minCount - len(selected)
-		additional := c.pickMultipleFromPool(combinedPool, needed, reviewCounts)
+		additional := c.pickMultipleWith(strategy, combinedPool, needed, reviewCounts)
 		for _, u := range additional {
 			selected = append(selected, u)
 			selectedSet[u.ID] = true
 		}
+		trace.addStep(strategy, "fill", combinedPool, reviewCounts, additional)
 	}
 
 	if len(selected) == 0 {
 		return nil
 	}
 
-	log.Printf("Selected %d reviewer(s) from label groups (min was %d)", len(selected), minCount)
+	log.Printf("Selected %d reviewer(s) from groups (min was %d)", len(selected), minCount)
 	return selected
 }
 
@@ -316,37 +507,19 @@ func (c *MRReviewerConsumer) getRecentReviewCounts(groups map[string][]models.Us
 }
 
 func (c *MRReviewerConsumer) getReviewCountsForUserIDs(userIDs []uint) map[uint]int {
-	reviewCounts := make(map[uint]int)
-
-	if len(userIDs) == 0 {
-		return reviewCounts
+	at := time.Now()
+	if c.replayTime != nil {
+		at = *c.replayTime
 	}
-
-	twoWeeksAgo := time.Now().AddDate(0, 0, -14)
-
-	var counts []struct {
-		UserID uint
-		Count  int
-	}
-
-	if err := c.db.Table("merge_request_reviewers").
-		Joins("JOIN merge_requests ON merge_requests.id = merge_request_reviewers.merge_request_id").
-		Where("merge_requests.gitlab_created_at > ?", twoWeeksAgo).
-		Where("merge_request_reviewers.user_id IN ?", userIDs).
-		Select("merge_request_reviewers.user_id, COUNT(*) as count").
-		Group("merge_request_reviewers.user_id").
-		Find(&counts).Error; err != nil {
+	reviewCounts, err := utils.GetRecentReviewCountsAt(c.db, userIDs, at)
+	if err != nil {
 		log.Printf("failed to fetch recent reviewer counts: %v", err)
-	} else {
-		for _, rc := range counts {
-			reviewCounts[rc.UserID] = rc.Count
-		}
 	}
-
 	return reviewCounts
 }
 
-func (c *MRReviewerConsumer) pickMultipleFromPool(users []models.User, count int, reviewCounts map[uint]int) []models.User {
+// pickMultipleWith draws count distinct users from the pool, one strategy pick at a time.
+func (c *MRReviewerConsumer) pickMultipleWith(strategy ReviewerStrategy, users []models.User, count int, reviewCounts map[uint]int) []models.User {
 	if len(users) == 0 || count <= 0 {
 		return nil
 	}
@@ -360,7 +533,7 @@ func (c *MRReviewerConsumer) pickMultipleFromPool(users []models.User, count int
 	copy(remaining, users)
 
 	for i := 0; i < count && len(remaining) > 0; i++ {
-		idx := c.pickReviewerFromPool(remaining, reviewCounts)
+		idx := strategy.Pick(remaining, reviewCounts)
 		selected = append(selected, remaining[idx])
 		remaining = append(remaining[:idx], remaining[idx+1:]...)
 	}
@@ -380,66 +553,17 @@ func (c *MRReviewerConsumer) getAssignCount(repoID uint) int {
 	return sla.AssignCount
 }
 
-func (c *MRReviewerConsumer) pickMultipleReviewers(users []models.User, count int) []models.User {
-	if len(users) == 0 {
-		return nil
-	}
-
-	if count <= 0 {
-		count = 1
+// formatAuthorMention returns the VK ID to mention an MR author by: their email, else a VKUser
+// matching their username, else the username itself.
+func (c *MRReviewerConsumer) formatAuthorMention(author *models.User) string {
+	if author.Email != "" {
+		return author.Email
 	}
-
-	if len(users) <= count {
-		return users
+	var vkUser models.VKUser
+	if err := c.db.Where("user_id LIKE ?", author.Username+"%").First(&vkUser).Error; err == nil {
+		return vkUser.UserID
 	}
-
-	userIDs := make([]uint, len(users))
-	for i, user := range users {
-		userIDs[i] = user.ID
-	}
-	reviewCounts := c.getReviewCountsForUserIDs(userIDs)
-
-	return c.pickMultipleFromPool(users, count, reviewCounts)
-}
-
-func (c *MRReviewerConsumer) pickReviewerFromPool(users []models.User, reviewCounts map[uint]int) int {
-	if len(users) == 0 {
-		return 0
-	}
-
-	if len(users) == 1 {
-		return 0
-	}
-
-	weights := make([]float64, len(users))
-	totalWeight := 0.0
-
-	for i, user := range users {
-		count := reviewCounts[user.ID]
-		weight := 1.0 / float64(count+1)
-		weights[i] = weight
-		totalWeight += weight
-	}
-
-	if totalWeight <= 0 {
-		return safeRand.Intn(len(users))
-	}
-
-	for i := range weights {
-		weights[i] /= totalWeight
-	}
-
-	r := safeRand.Float64()
-	cumulativeWeight := 0.0
-
-	for i, weight := range weights {
-		cumulativeWeight += weight
-		if r <= cumulativeWeight {
-			return i
-		}
-	}
-
-	return safeRand.Intn(len(users))
+	return author.Username
 }
 
 // formatReviewerMentions uses batch query to avoid N+1 DB queries when looking up VKUsers.
@@ -501,7 +625,9 @@ func (c *MRReviewerConsumer) AssignReviewers() {
 	var mrs []models.MergeRequest
 	if err := c.db.
 		Preload("Repository").Preload("Author").Preload("Labels").Preload("Reviewers").
-		Where("merge_requests.state = ? AND merge_requests.draft = ? AND merge_requests.merged_at IS NULL", "opened", false).
+		Where("merge_requests.state = ? AND merge_requests.merged_at IS NULL", "opened").
+		Where(`merge_requests.draft = ? OR EXISTS (SELECT 1 FROM repository_slas
+			WHERE repository_slas.repository_id = merge_requests.repository_id AND repository_slas.assign_drafts = ? AND repository_slas.deleted_at IS NULL)`, false, true).
 		Where("EXISTS (SELECT 1 FROM repository_subscriptions WHERE repository_subscriptions.repository_id = merge_requests.repository_id)").
 		Where("merge_requests.gitlab_created_at > ?", c.startTime).
 		Find(&mrs).Error; err != nil {
@@ -523,18 +649,31 @@ func (c *MRReviewerConsumer) AssignReviewers() {
 			continue
 		}
 
-		minCount := c.getAssignCount(mr.RepositoryID)
+		minCount := c.getAssignCountForMR(&mr)
 		existingReviewers := mr.Reviewers
 		needed := minCount - len(existingReviewers)
+		if missingTier := c.missingTierReviewers(mr.RepositoryID, existingReviewers); missingTier > needed {
+			needed = missingTier
+		}
 
 		if needed <= 0 {
 			continue
 		}
 
 		isBackfill := len(existingReviewers) > 0
+		if !isBackfill {
+			if sla, err := utils.GetRepositorySLA(c.db, mr.RepositoryID); err == nil && utils.PipelineHoldsAssignment(c.db, sla, &mr) {
+				log.Printf("MR %d waits for a green pipeline before reviewer assignment (status %s)", mr.ID, mr.PipelineStatus)
+				continue
+			}
+		}
 		log.Printf("MR %d needs %d more reviewer(s) (has %d, min %d)", mr.ID, needed, len(existingReviewers), minCount)
 
-		newReviewers := c.selectReviewers(&mr, needed, existingReviewers)
+		newReviewers, trace := c.selectReviewersWithTrace(&mr, needed, existingReviewers)
+		trace.Backfill = isBackfill
+		if len(newReviewers) < needed {
+			c.warnReviewersUnavailable(&mr, trace)
+		}
 		if len(newReviewers) == 0 {
 			log.Printf("no available reviewers for repository %d (MR %d)", mr.RepositoryID, mr.ID)
 			continue
@@ -546,7 +685,7 @@ func (c *MRReviewerConsumer) AssignReviewers() {
 			reviewerIDs[i] = r.GitlabID
 		}
 
-		if _, _, err := c.glClient.MergeRequests.UpdateMergeRequest(
+		if _, _, err := c.mrService.UpdateMergeRequest(
 			mr.Repository.GitlabID, mr.IID,
 			&gitlab.UpdateMergeRequestOptions{ReviewerIDs: &reviewerIDs},
 		); err != nil {
@@ -554,6 +693,8 @@ func (c *MRReviewerConsumer) AssignReviewers() {
 			continue
 		}
 
+		c.saveSelectionTrace(mr.ID, trace)
+
 		var latestNotif models.MRNotificationState
 		if err := c.db.Where("merge_request_id = ?", mr.ID).
 			Order("created_at desc").First(&latestNotif).Error; errors.Is(err, gorm.ErrRecordNotFound) {
@@ -569,18 +710,12 @@ func (c *MRReviewerConsumer) AssignReviewers() {
 			continue
 		}
 
-		authorMention := mr.Author.Email
-		if authorMention == "" {
-			var vkUser models.VKUser
-			if err := c.db.Where("user_id LIKE ?", mr.Author.Username+"%").First(&vkUser).Error; err == nil {
-				authorMention = vkUser.UserID
-			} else {
-				authorMention = mr.Author.Username
-			}
-		}
+		authorMention := c.formatAuthorMention(&mr.Author)
 
 		newReviewerMentions := c.formatReviewerMentions(newReviewers)
 
+		leftDraft := !isBackfill && c.hasLeftDraft(mr.ID)
+
 		reviewerLabel := "reviewer"
 		if len(newReviewers) > 1 {
 			reviewerLabel = "reviewers"
@@ -604,6 +739,9 @@ func (c *MRReviewerConsumer) AssignReviewers() {
 					reviewerLabel,
 					newReviewerMentions,
 				)
+				if leftDraft {
+					text = "✅ Ready for review\n" + text
+				}
 			}
 			msg := c.vkBot.NewTextMessage(sub.Chat.ChatID, text)
 			if err := msg.Send(); err != nil {
@@ -615,8 +753,9 @@ func (c *MRReviewerConsumer) AssignReviewers() {
 			log.Printf("failed to mark MR reviewers: %v", err)
 		}
 
-		for _, reviewer := range newReviewers {
-			c.notifyUserDM(reviewer.Email, fmt.Sprintf(
+		for i := range newReviewers {
+			reviewer := &newReviewers[i]
+			c.notifyUserDM(reviewer, utils.NotifyAssignment, fmt.Sprintf(
 				"🔍 New MR for review [%s]:\n%s\n%s",
 				mr.Repository.Name,
 				mr.Title,
@@ -633,13 +772,71 @@ func (c *MRReviewerConsumer) AssignReviewers() {
 	}
 }
 
-func (c *MRReviewerConsumer) notifyUserDM(userEmail, text string) {
-	if userEmail == "" {
+// hasLeftDraft reports whether the MR was taken out of draft, so its first assignment marks the
+// moment it became ready for review.
+func (c *MRReviewerConsumer) hasLeftDraft(mrID uint) bool {
+	var count int64
+	c.db.Model(&models.MRAction{}).
+		Where("merge_request_id = ? AND action_type = ? AND metadata = ?", mrID, models.ActionDraftToggled, `{"draft":false}`).
+		Count(&count)
+	return count > 0
+}
+
+// warnReviewersUnavailable tells subscribed chats that an MR could not get enough reviewers
+// because pool members are at capacity. Warns once until a reviewer is assigned again.
+func (c *MRReviewerConsumer) warnReviewersUnavailable(mr *models.MergeRequest, trace *selectionTrace) {
+	var atCapacity []string
+	for _, e := range trace.Excluded {
+		if e.Reason == exclusionCapacity {
+			atCapacity = append(atCapacity, e.Username)
+		}
+	}
+	if len(atCapacity) == 0 {
+		return
+	}
+
+	var lastAssigned models.MRAction
+	query := c.db.Model(&models.MRAction{}).
+		Where("merge_request_id = ? AND action_type = ?", mr.ID, models.ActionReviewersUnavailable)
+	if err := c.db.Where("merge_request_id = ? AND action_type = ?", mr.ID, models.ActionReviewerAssigned).
+		Order("timestamp desc").First(&lastAssigned).Error; err == nil {
+		query = query.Where("timestamp > ?", lastAssigned.Timestamp)
+	}
+	var count int64
+	query.Count(&count)
+	if count > 0 {
+		return
+	}
+
+	metadata, _ := json.Marshal(map[string][]string{"at_capacity": atCapacity})
+	if err := c.db.Create(&models.MRAction{
+		MergeRequestID: mr.ID,
+		ActionType:     models.ActionReviewersUnavailable,
+		Timestamp:      time.Now(),
+		Metadata:       string(metadata),
+		Notified:       true,
+	}).Error; err != nil {
+		log.Printf("failed to record reviewers unavailable for MR %d: %v", mr.ID, err)
+		return
+	}
+
+	var subs []models.RepositorySubscription
+	if err := c.db.Preload("Chat").Where("repository_id = ?", mr.RepositoryID).Find(&subs).Error; err != nil {
+		log.Printf("failed to fetch subscriptions: %v", err)
 		return
 	}
-	msg := c.vkBot.NewTextMessage(userEmail, text)
-	if err := msg.Send(); err != nil {
-		log.Printf("DM to %s failed (user may not have messaged bot): %v", userEmail, err)
+
+	text := fmt.Sprintf(
+		"⚠️ Nobody available to review:\n%s\n%s\nAt capacity: %s",
+		mr.Title,
+		mr.WebURL,
+		strings.Join(atCapacity, ", "),
+	)
+	for _, sub := range subs {
+		msg := c.vkBot.NewTextMessage(sub.Chat.ChatID, text)
+		if err := msg.Send(); err != nil {
+			log.Printf("failed to send capacity warning: %v", err)
+		}
 	}
 }
 
@@ -664,7 +861,7 @@ func (c *MRReviewerConsumer) ProcessReviewerRemovalNotifications() {
 			continue
 		}
 
-		c.notifyUserDM(action.TargetUser.Email, fmt.Sprintf(
+		c.notifyUserDM(action.TargetUser, utils.NotifyReviewerRemoved, fmt.Sprintf(
 			"You were removed from review [%s]:\n%s\n%s",
 			action.MergeRequest.Repository.Name,
 			action.MergeRequest.Title,
@@ -693,7 +890,7 @@ func (c *MRReviewerConsumer) ProcessFullyApprovedNotifications() {
 		mr := action.MergeRequest
 
 		if mr.Author.Email != "" {
-			c.notifyUserDM(mr.Author.Email, fmt.Sprintf(
+			c.notifyUserDM(&mr.Author, utils.NotifyFullyApproved, fmt.Sprintf(
 				"Your MR is fully approved [%s]:\n%s\n%s",
 				mr.Repository.Name,
 				mr.Title,
@@ -712,7 +909,7 @@ func (c *MRReviewerConsumer) ProcessFullyApprovedNotifications() {
 			if rm.User.Email == "" {
 				continue
 			}
-			c.notifyUserDM(rm.User.Email, fmt.Sprintf(
+			c.notifyUserDM(&rm.User, utils.NotifyRelease, fmt.Sprintf(
 				"MR ready for release [%s]:\n%s\n%s",
 				mr.Repository.Name,
 				mr.Title,
@@ -754,14 +951,19 @@ func (c *MRReviewerConsumer) ProcessStateChangeNotifications() {
 	for _, actionList := range mrActions {
 		mr := actionList[0].MergeRequest
 
-		if mr.State != "opened" {
+		if mr.State != "opened" || mr.Draft {
 			for _, action := range actionList {
 				c.markActionNotified(action.ID)
 			}
 			continue
 		}
 
-		currentState := string(utils.GetStateInfo(c.db, &mr).State)
+		// Follow the review threads rather than the derived state, so a red
+		// pipeline or a conflict doesn't hide the fixes/re-review messages.
+		currentState := string(utils.StateOnReview)
+		if utils.HasThreadsAwaitingAuthor(c.db, mr.ID, mr.AuthorID) {
+			currentState = string(utils.StateOnFixes)
+		}
 
 		var latestNotif models.MRNotificationState
 		notifErr := c.db.Where("merge_request_id = ?", mr.ID).
@@ -777,7 +979,7 @@ func (c *MRReviewerConsumer) ProcessStateChangeNotifications() {
 		if currentState != latestNotif.NotifiedState {
 			switch utils.MRState(currentState) {
 			case utils.StateOnFixes:
-				c.notifyUserDM(mr.Author.Email, fmt.Sprintf(
+				c.notifyUserDM(&mr.Author, utils.NotifyStateChange, fmt.Sprintf(
 					"🔧 Your MR needs fixes [%s]:\n%s\n%s\nReviewer left comments",
 					mr.Repository.Name,
 					mr.Title,
@@ -790,11 +992,12 @@ func (c *MRReviewerConsumer) ProcessStateChangeNotifications() {
 					for _, approver := range mr.Approvers {
 						approverIDs[approver.ID] = true
 					}
-					for _, reviewer := range mr.Reviewers {
+					for i := range mr.Reviewers {
+						reviewer := &mr.Reviewers[i]
 						if approverIDs[reviewer.ID] {
 							continue
 						}
-						c.notifyUserDM(reviewer.Email, fmt.Sprintf(
+						c.notifyUserDM(reviewer, utils.NotifyStateChange, fmt.Sprintf(
 							"MR ready for re-review [%s]:\n%s\n%s",
 							mr.Repository.Name,
 							mr.Title,
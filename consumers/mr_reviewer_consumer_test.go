@@ -10,39 +10,34 @@ import (
 	"devstreamlinebot/testutils"
 )
 
-// TestPickReviewerFromPool_EmptyPool tests behavior with empty pool.
-func TestPickReviewerFromPool_EmptyPool(t *testing.T) {
-	db := testutils.SetupTestDB(t)
-	consumer := NewMRReviewerConsumer(db, nil, nil, 0, nil)
-
-	idx := consumer.pickReviewerFromPool([]models.User{}, map[uint]int{})
+// TestWeightedStrategyPick_EmptyPool tests behavior with empty pool.
+func TestWeightedStrategyPick_EmptyPool(t *testing.T) {
+	idx := weightedStrategy{}.Pick([]models.User{}, map[uint]int{})
 
 	if idx != 0 {
-		t.Errorf("pickReviewerFromPool with empty pool: got %d, want 0", idx)
+		t.Errorf("weightedStrategy.Pick with empty pool: got %d, want 0", idx)
 	}
 }
 
-// TestPickReviewerFromPool_SingleUser tests behavior with single user.
-func TestPickReviewerFromPool_SingleUser(t *testing.T) {
+// TestWeightedStrategyPick_SingleUser tests behavior with single user.
+func TestWeightedStrategyPick_SingleUser(t *testing.T) {
 	db := testutils.SetupTestDB(t)
 	userFactory := testutils.NewUserFactory(db)
-	consumer := NewMRReviewerConsumer(db, nil, nil, 0, nil)
 
 	user := userFactory.Create()
 	users := []models.User{user}
 
-	idx := consumer.pickReviewerFromPool(users, map[uint]int{})
+	idx := weightedStrategy{}.Pick(users, map[uint]int{})
 
 	if idx != 0 {
-		t.Errorf("pickReviewerFromPool with single user: got %d, want 0", idx)
+		t.Errorf("weightedStrategy.Pick with single user: got %d, want 0", idx)
 	}
 }
 
-// TestPickReviewerFromPool_WeightedSelection tests weighted probability distribution.
-func TestPickReviewerFromPool_WeightedSelection(t *testing.T) {
+// TestWeightedStrategyPick_WeightedSelection tests weighted probability distribution.
+func TestWeightedStrategyPick_WeightedSelection(t *testing.T) {
 	db := testutils.SetupTestDB(t)
 	userFactory := testutils.NewUserFactory(db)
-	consumer := NewMRReviewerConsumer(db, nil, nil, 0, nil)
 
 	heavyLoadUser := userFactory.Create(testutils.WithUsername("heavy"))
 	lightLoadUser := userFactory.Create(testutils.WithUsername("light"))
@@ -56,7 +51,7 @@ func TestPickReviewerFromPool_WeightedSelection(t *testing.T) {
 	lightCount := 0
 	iterations := 1000
 	for i := 0; i < iterations; i++ {
-		idx := consumer.pickReviewerFromPool(users, counts)
+		idx := weightedStrategy{}.Pick(users, counts)
 		if idx == 1 { // lightLoadUser
 			lightCount++
 		}
@@ -71,11 +66,10 @@ func TestPickReviewerFromPool_WeightedSelection(t *testing.T) {
 	}
 }
 
-// TestPickReviewerFromPool_UserMissingFromCounts tests default weight for missing users.
-func TestPickReviewerFromPool_UserMissingFromCounts(t *testing.T) {
+// TestWeightedStrategyPick_UserMissingFromCounts tests default weight for missing users.
+func TestWeightedStrategyPick_UserMissingFromCounts(t *testing.T) {
 	db := testutils.SetupTestDB(t)
 	userFactory := testutils.NewUserFactory(db)
-	consumer := NewMRReviewerConsumer(db, nil, nil, 0, nil)
 
 	user1 := userFactory.Create(testutils.WithUsername("user1"))
 	user2 := userFactory.Create(testutils.WithUsername("user2"))
@@ -88,7 +82,7 @@ func TestPickReviewerFromPool_UserMissingFromCounts(t *testing.T) {
 	user1Count := 0
 	iterations := 1000
 	for i := 0; i < iterations; i++ {
-		idx := consumer.pickReviewerFromPool(users, counts)
+		idx := weightedStrategy{}.Pick(users, counts)
 		if idx == 0 {
 			user1Count++
 		}
@@ -100,8 +94,8 @@ func TestPickReviewerFromPool_UserMissingFromCounts(t *testing.T) {
 	}
 }
 
-// TestPickMultipleFromPool_PoolSmallerThanCount tests when pool is smaller than requested count.
-func TestPickMultipleFromPool_PoolSmallerThanCount(t *testing.T) {
+// TestPickMultipleWith_PoolSmallerThanCount tests when pool is smaller than requested count.
+func TestPickMultipleWith_PoolSmallerThanCount(t *testing.T) {
 	db := testutils.SetupTestDB(t)
 	userFactory := testutils.NewUserFactory(db)
 	consumer := NewMRReviewerConsumer(db, nil, nil, 0, nil)
@@ -111,15 +105,15 @@ func TestPickMultipleFromPool_PoolSmallerThanCount(t *testing.T) {
 	users := []models.User{user1, user2}
 	counts := map[uint]int{}
 
-	selected := consumer.pickMultipleFromPool(users, 5, counts)
+	selected := consumer.pickMultipleWith(weightedStrategy{}, users, 5, counts)
 
 	if len(selected) != 2 {
-		t.Errorf("pickMultipleFromPool with pool < count: got %d users, want 2", len(selected))
+		t.Errorf("pickMultipleWith with pool < count: got %d users, want 2", len(selected))
 	}
 }
 
-// TestPickMultipleFromPool_PoolEqualsCount tests when pool equals requested count.
-func TestPickMultipleFromPool_PoolEqualsCount(t *testing.T) {
+// TestPickMultipleWith_PoolEqualsCount tests when pool equals requested count.
+func TestPickMultipleWith_PoolEqualsCount(t *testing.T) {
 	db := testutils.SetupTestDB(t)
 	userFactory := testutils.NewUserFactory(db)
 	consumer := NewMRReviewerConsumer(db, nil, nil, 0, nil)
@@ -130,15 +124,15 @@ func TestPickMultipleFromPool_PoolEqualsCount(t *testing.T) {
 	users := []models.User{user1, user2, user3}
 	counts := map[uint]int{}
 
-	selected := consumer.pickMultipleFromPool(users, 3, counts)
+	selected := consumer.pickMultipleWith(weightedStrategy{}, users, 3, counts)
 
 	if len(selected) != 3 {
-		t.Errorf("pickMultipleFromPool with pool == count: got %d users, want 3", len(selected))
+		t.Errorf("pickMultipleWith with pool == count: got %d users, want 3", len(selected))
 	}
 }
 
-// TestPickMultipleFromPool_PoolLargerThanCount tests when pool is larger than requested count.
-func TestPickMultipleFromPool_PoolLargerThanCount(t *testing.T) {
+// TestPickMultipleWith_PoolLargerThanCount tests when pool is larger than requested count.
+func TestPickMultipleWith_PoolLargerThanCount(t *testing.T) {
 	db := testutils.SetupTestDB(t)
 	userFactory := testutils.NewUserFactory(db)
 	consumer := NewMRReviewerConsumer(db, nil, nil, 0, nil)
@@ -149,15 +143,15 @@ func TestPickMultipleFromPool_PoolLargerThanCount(t *testing.T) {
 	}
 	counts := map[uint]int{}
 
-	selected := consumer.pickMultipleFromPool(users, 3, counts)
+	selected := consumer.pickMultipleWith(weightedStrategy{}, users, 3, counts)
 
 	if len(selected) != 3 {
-		t.Errorf("pickMultipleFromPool with pool > count: got %d users, want 3", len(selected))
+		t.Errorf("pickMultipleWith with pool > count: got %d users, want 3", len(selected))
 	}
 }
 
-// TestPickMultipleFromPool_NoDuplicates tests that selected users are unique.
-func TestPickMultipleFromPool_NoDuplicates(t *testing.T) {
+// TestPickMultipleWith_NoDuplicates tests that selected users are unique.
+func TestPickMultipleWith_NoDuplicates(t *testing.T) {
 	db := testutils.SetupTestDB(t)
 	userFactory := testutils.NewUserFactory(db)
 	consumer := NewMRReviewerConsumer(db, nil, nil, 0, nil)
@@ -170,7 +164,7 @@ func TestPickMultipleFromPool_NoDuplicates(t *testing.T) {
 
 	// Run multiple times to catch potential duplicates
 	for i := 0; i < 100; i++ {
-		selected := consumer.pickMultipleFromPool(users, 3, counts)
+		selected := consumer.pickMultipleWith(weightedStrategy{}, users, 3, counts)
 
 		seen := make(map[uint]bool)
 		for _, u := range selected {
@@ -182,30 +176,30 @@ func TestPickMultipleFromPool_NoDuplicates(t *testing.T) {
 	}
 }
 
-// TestPickMultipleFromPool_EmptyPool tests behavior with empty pool.
-func TestPickMultipleFromPool_EmptyPool(t *testing.T) {
+// TestPickMultipleWith_EmptyPool tests behavior with empty pool.
+func TestPickMultipleWith_EmptyPool(t *testing.T) {
 	db := testutils.SetupTestDB(t)
 	consumer := NewMRReviewerConsumer(db, nil, nil, 0, nil)
 
-	selected := consumer.pickMultipleFromPool([]models.User{}, 3, map[uint]int{})
+	selected := consumer.pickMultipleWith(weightedStrategy{}, []models.User{}, 3, map[uint]int{})
 
 	if selected != nil {
-		t.Errorf("pickMultipleFromPool with empty pool: got %v, want nil", selected)
+		t.Errorf("pickMultipleWith with empty pool: got %v, want nil", selected)
 	}
 }
 
-// TestPickMultipleFromPool_ZeroCount tests behavior with zero count.
-func TestPickMultipleFromPool_ZeroCount(t *testing.T) {
+// TestPickMultipleWith_ZeroCount tests behavior with zero count.
+func TestPickMultipleWith_ZeroCount(t *testing.T) {
 	db := testutils.SetupTestDB(t)
 	userFactory := testutils.NewUserFactory(db)
 	consumer := NewMRReviewerConsumer(db, nil, nil, 0, nil)
 
 	users := []models.User{userFactory.Create(), userFactory.Create()}
 
-	selected := consumer.pickMultipleFromPool(users, 0, map[uint]int{})
+	selected := consumer.pickMultipleWith(weightedStrategy{}, users, 0, map[uint]int{})
 
 	if selected != nil {
-		t.Errorf("pickMultipleFromPool with zero count: got %v, want nil", selected)
+		t.Errorf("pickMultipleWith with zero count: got %v, want nil", selected)
 	}
 }
 
@@ -524,8 +518,8 @@ func TestSelectReviewers_FallsBackToDefaultPool(t *testing.T) {
 	}
 }
 
-// TestSelectFromLabelGroups_OneLabel tests picking from single label group.
-func TestSelectFromLabelGroups_OneLabel(t *testing.T) {
+// TestSelectFromGroups_OneLabel tests picking from single label group.
+func TestSelectFromGroups_OneLabel(t *testing.T) {
 	db := testutils.SetupTestDB(t)
 	repoFactory := testutils.NewRepositoryFactory(db)
 	userFactory := testutils.NewUserFactory(db)
@@ -541,7 +535,7 @@ func TestSelectFromLabelGroups_OneLabel(t *testing.T) {
 
 	consumer := NewMRReviewerConsumer(db, nil, nil, 0, nil)
 	groups := consumer.getLabelReviewerGroups(&mr, nil)
-	selected := consumer.selectFromLabelGroups(&mr, groups, 1, nil)
+	selected := consumer.selectFromGroups(&mr, groups, 1, nil, weightedStrategy{}, nil)
 
 	if len(selected) != 1 {
 		t.Fatalf("Expected 1 reviewer, got %d", len(selected))
@@ -551,8 +545,8 @@ func TestSelectFromLabelGroups_OneLabel(t *testing.T) {
 	}
 }
 
-// TestSelectFromLabelGroups_TwoLabels tests picking from two label groups.
-func TestSelectFromLabelGroups_TwoLabels(t *testing.T) {
+// TestSelectFromGroups_TwoLabels tests picking from two label groups.
+func TestSelectFromGroups_TwoLabels(t *testing.T) {
 	db := testutils.SetupTestDB(t)
 	repoFactory := testutils.NewRepositoryFactory(db)
 	userFactory := testutils.NewUserFactory(db)
@@ -570,7 +564,7 @@ func TestSelectFromLabelGroups_TwoLabels(t *testing.T) {
 
 	consumer := NewMRReviewerConsumer(db, nil, nil, 0, nil)
 	groups := consumer.getLabelReviewerGroups(&mr, nil)
-	selected := consumer.selectFromLabelGroups(&mr, groups, 2, nil)
+	selected := consumer.selectFromGroups(&mr, groups, 2, nil, weightedStrategy{}, nil)
 
 	if len(selected) != 2 {
 		t.Fatalf("Expected 2 reviewers, got %d", len(selected))
@@ -586,8 +580,8 @@ func TestSelectFromLabelGroups_TwoLabels(t *testing.T) {
 	}
 }
 
-// TestSelectFromLabelGroups_NoReuseAcrossLabels tests that same user isn't picked twice.
-func TestSelectFromLabelGroups_NoReuseAcrossLabels(t *testing.T) {
+// TestSelectFromGroups_NoReuseAcrossLabels tests that same user isn't picked twice.
+func TestSelectFromGroups_NoReuseAcrossLabels(t *testing.T) {
 	db := testutils.SetupTestDB(t)
 	repoFactory := testutils.NewRepositoryFactory(db)
 	userFactory := testutils.NewUserFactory(db)
@@ -610,7 +604,7 @@ func TestSelectFromLabelGroups_NoReuseAcrossLabels(t *testing.T) {
 	// Run multiple times to ensure no duplicates
 	for i := 0; i < 50; i++ {
 		groups := consumer.getLabelReviewerGroups(&mr, nil)
-		selected := consumer.selectFromLabelGroups(&mr, groups, 2, nil)
+		selected := consumer.selectFromGroups(&mr, groups, 2, nil, weightedStrategy{}, nil)
 
 		// Check for duplicates
 		seen := make(map[uint]bool)
@@ -623,8 +617,8 @@ func TestSelectFromLabelGroups_NoReuseAcrossLabels(t *testing.T) {
 	}
 }
 
-// TestSelectFromLabelGroups_FillsFromDefaultPool tests filling from default pool when < minCount.
-func TestSelectFromLabelGroups_FillsFromDefaultPool(t *testing.T) {
+// TestSelectFromGroups_FillsFromDefaultPool tests filling from default pool when < minCount.
+func TestSelectFromGroups_FillsFromDefaultPool(t *testing.T) {
 	db := testutils.SetupTestDB(t)
 	repoFactory := testutils.NewRepositoryFactory(db)
 	userFactory := testutils.NewUserFactory(db)
@@ -644,7 +638,7 @@ func TestSelectFromLabelGroups_FillsFromDefaultPool(t *testing.T) {
 
 	consumer := NewMRReviewerConsumer(db, nil, nil, 0, nil)
 	groups := consumer.getLabelReviewerGroups(&mr, nil)
-	selected := consumer.selectFromLabelGroups(&mr, groups, 3, nil)
+	selected := consumer.selectFromGroups(&mr, groups, 3, nil, weightedStrategy{}, nil)
 
 	if len(selected) != 3 {
 		t.Fatalf("Expected 3 reviewers, got %d", len(selected))
@@ -797,6 +791,63 @@ func TestStateChange_OnReviewToOnFixes(t *testing.T) {
 	}
 }
 
+// TestStateChange_OnFixesWithFailedPipeline verifies the author is notified about fixes even when the pipeline is red.
+func TestStateChange_OnFixesWithFailedPipeline(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	mockBot := mocks.NewMockVKBot()
+	userFactory := testutils.NewUserFactory(db)
+	repoFactory := testutils.NewRepositoryFactory(db)
+	mrFactory := testutils.NewMergeRequestFactory(db)
+
+	repo := repoFactory.Create()
+	author := userFactory.Create(testutils.WithEmail("author@example.com"))
+	reviewer := userFactory.Create(testutils.WithEmail("reviewer@example.com"))
+
+	mr := mrFactory.Create(repo, author)
+	testutils.AssignReviewers(db, &mr, reviewer)
+	db.Model(&mr).Update("pipeline_status", "failed")
+
+	testutils.CreateNotificationState(db, mr, "on_review", "")
+
+	comment := testutils.CreateMRComment(db, mr, reviewer, 123, testutils.WithResolvable())
+	testutils.CreateMRAction(db, mr, models.ActionCommentAdded,
+		testutils.WithActor(reviewer),
+		testutils.WithCommentID(comment.ID),
+	)
+
+	consumer := NewMRReviewerConsumerWithBot(db, mockBot, nil, 0, nil)
+	consumer.ProcessStateChangeNotifications()
+
+	sentMessages := mockBot.GetSentMessages()
+	if len(sentMessages) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(sentMessages))
+	}
+	if sentMessages[0].ChatID != "author@example.com" || !strings.Contains(sentMessages[0].Text, "needs fixes") {
+		t.Errorf("Expected fixes notification to the author, got %s: %q", sentMessages[0].ChatID, sentMessages[0].Text)
+	}
+
+	var updatedState models.MRNotificationState
+	db.Where("merge_request_id = ?", mr.ID).Order("created_at desc").First(&updatedState)
+	if updatedState.NotifiedState != "on_fixes" {
+		t.Errorf("Expected NotifiedState='on_fixes', got '%s'", updatedState.NotifiedState)
+	}
+
+	// Resolving the thread while the pipeline is still red asks for re-review.
+	mockBot.Reset()
+	db.Model(&comment).Updates(map[string]interface{}{"resolved": true, "resolved_by_id": author.ID})
+	testutils.CreateMRAction(db, mr, models.ActionCommentResolved,
+		testutils.WithActor(author),
+		testutils.WithCommentID(comment.ID),
+	)
+	consumer.ProcessStateChangeNotifications()
+
+	sentMessages = mockBot.GetSentMessages()
+	if len(sentMessages) != 1 || sentMessages[0].ChatID != "reviewer@example.com" ||
+		!strings.Contains(sentMessages[0].Text, "ready for re-review") {
+		t.Errorf("Expected re-review notification to the reviewer, got %+v", sentMessages)
+	}
+}
+
 // TestStateChange_OnFixesToOnReview verifies reviewers are notified when MR transitions back to on_review.
 func TestStateChange_OnFixesToOnReview(t *testing.T) {
 	db := testutils.SetupTestDB(t)
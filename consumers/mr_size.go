@@ -0,0 +1,98 @@
+package consumers
+
+import (
+	"log"
+	"strings"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/utils"
+)
+
+// getAssignCountForMR returns how many reviewers an MR needs: the repository AssignCount,
+// raised by the size rule with the highest MinLines the MR reaches.
+func (c *MRReviewerConsumer) getAssignCountForMR(mr *models.MergeRequest) int {
+	count := c.getAssignCount(mr.RepositoryID)
+	if mr.DiffStatsSHA == "" {
+		return count
+	}
+
+	var rule models.SizeAssignRule
+	if err := c.db.Where("repository_id = ? AND min_lines <= ?", mr.RepositoryID, utils.ChangedLines(mr)).
+		Order("min_lines desc").
+		First(&rule).Error; err != nil {
+		return count
+	}
+	if rule.AssignCount > count {
+		log.Printf("MR %d has %d changed lines, size rule ≥%d raises reviewers to %d", mr.ID, utils.ChangedLines(mr), rule.MinLines, rule.AssignCount)
+		return rule.AssignCount
+	}
+	return count
+}
+
+// ApplySizeLabels keeps exactly one size label (size/S..size/XL) on open MRs of repositories
+// with size labels enabled, based on the synced diff stats.
+func (c *MRReviewerConsumer) ApplySizeLabels() {
+	var mrs []models.MergeRequest
+	if err := c.db.
+		Preload("Repository").Preload("Labels").
+		Where("merge_requests.state = ? AND merge_requests.merged_at IS NULL AND merge_requests.diff_stats_sha <> ''", "opened").
+		Where("EXISTS (SELECT 1 FROM size_label_configs WHERE size_label_configs.repository_id = merge_requests.repository_id AND size_label_configs.deleted_at IS NULL)").
+		Find(&mrs).Error; err != nil {
+		log.Printf("failed to fetch merge requests for size labels: %v", err)
+		return
+	}
+
+	for _, mr := range mrs {
+		desired := utils.MRSizeLabel(utils.ChangedLines(&mr))
+
+		hasDesired := false
+		var stale []string
+		var staleLabels []models.Label
+		for _, label := range mr.Labels {
+			if !strings.HasPrefix(label.Name, utils.SizeLabelPrefix) {
+				continue
+			}
+			if label.Name == desired {
+				hasDesired = true
+			} else {
+				stale = append(stale, label.Name)
+				staleLabels = append(staleLabels, label)
+			}
+		}
+		if hasDesired && len(stale) == 0 {
+			continue
+		}
+
+		opts := &gitlab.UpdateMergeRequestOptions{}
+		if !hasDesired {
+			opts.AddLabels = &gitlab.LabelOptions{desired}
+		}
+		if len(stale) > 0 {
+			removeLabels := gitlab.LabelOptions(stale)
+			opts.RemoveLabels = &removeLabels
+		}
+		if _, _, err := c.mrService.UpdateMergeRequest(mr.Repository.GitlabID, mr.IID, opts); err != nil {
+			log.Printf("failed to update size label of MR %d: %v", mr.ID, err)
+			continue
+		}
+
+		if len(staleLabels) > 0 {
+			if err := c.db.Model(&mr).Association("Labels").Delete(staleLabels); err != nil {
+				log.Printf("failed to remove local size labels of MR %d: %v", mr.ID, err)
+			}
+		}
+		if !hasDesired {
+			var label models.Label
+			if err := c.db.Where(models.Label{Name: desired}).FirstOrCreate(&label).Error; err != nil {
+				log.Printf("failed to upsert label %s: %v", desired, err)
+				continue
+			}
+			if err := c.db.Model(&mr).Association("Labels").Append(&label); err != nil {
+				log.Printf("failed to add local size label to MR %d: %v", mr.ID, err)
+			}
+		}
+		log.Printf("Set size label %s on MR %d", desired, mr.ID)
+	}
+}
@@ -0,0 +1,86 @@
+package consumers
+
+import (
+	"testing"
+
+	"devstreamlinebot/mocks"
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+// TestGetAssignCountForMR tests that the highest reached size rule raises the reviewer count.
+func TestGetAssignCountForMR(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := testutils.NewUserFactory(db).Create()
+	testutils.CreateRepositorySLA(db, repo, 1)
+	db.Create(&models.SizeAssignRule{RepositoryID: repo.ID, MinLines: 500, AssignCount: 2})
+	db.Create(&models.SizeAssignRule{RepositoryID: repo.ID, MinLines: 1500, AssignCount: 3})
+
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author)
+	consumer := NewMRReviewerConsumerWithServices(db, nil, nil, nil, 0, nil)
+
+	cases := []struct {
+		added, removed int
+		sha            string
+		want           int
+	}{
+		{600, 0, "", 1},
+		{100, 50, "sha", 1},
+		{400, 100, "sha", 2},
+		{1400, 200, "sha", 3},
+	}
+	for _, tc := range cases {
+		mr.LinesAdded, mr.LinesRemoved, mr.DiffStatsSHA = tc.added, tc.removed, tc.sha
+		if got := consumer.getAssignCountForMR(&mr); got != tc.want {
+			t.Errorf("+%d/-%d (sha %q): expected %d reviewers, got %d", tc.added, tc.removed, tc.sha, tc.want, got)
+		}
+	}
+}
+
+// TestApplySizeLabels tests that the size label is set, stale size labels are removed and disabled repos are skipped.
+func TestApplySizeLabels(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repoFactory := testutils.NewRepositoryFactory(db)
+	mrFactory := testutils.NewMergeRequestFactory(db)
+	author := testutils.NewUserFactory(db).Create()
+
+	repo := repoFactory.Create()
+	db.Create(&models.SizeLabelConfig{RepositoryID: repo.ID})
+	disabled := repoFactory.Create()
+
+	mr := mrFactory.Create(repo, author, testutils.WithLabels(db, "size/S", "backend"))
+	db.Model(&mr).Updates(map[string]interface{}{"lines_added": 300, "lines_removed": 20, "diff_stats_sha": "sha"})
+	upToDate := mrFactory.Create(repo, author, testutils.WithLabels(db, "size/S"))
+	db.Model(&upToDate).Updates(map[string]interface{}{"lines_added": 10, "diff_stats_sha": "sha"})
+	other := mrFactory.Create(disabled, author)
+	db.Model(&other).Updates(map[string]interface{}{"lines_added": 10, "diff_stats_sha": "sha"})
+
+	mrService := &mocks.MockMergeRequestsService{}
+	consumer := NewMRReviewerConsumerWithServices(db, nil, mrService, nil, 0, nil)
+	consumer.ApplySizeLabels()
+
+	if len(mrService.UpdateMergeRequestCalls) != 1 {
+		t.Fatalf("expected 1 label update, got %d", len(mrService.UpdateMergeRequestCalls))
+	}
+	call := mrService.UpdateMergeRequestCalls[0]
+	if call.MergeRequest != mr.IID {
+		t.Errorf("expected update of MR %d, got %d", mr.IID, call.MergeRequest)
+	}
+	if call.Opt.AddLabels == nil || len(*call.Opt.AddLabels) != 1 || (*call.Opt.AddLabels)[0] != "size/L" {
+		t.Errorf("expected size/L added, got %v", call.Opt.AddLabels)
+	}
+	if call.Opt.RemoveLabels == nil || len(*call.Opt.RemoveLabels) != 1 || (*call.Opt.RemoveLabels)[0] != "size/S" {
+		t.Errorf("expected size/S removed, got %v", call.Opt.RemoveLabels)
+	}
+
+	var reloaded models.MergeRequest
+	db.Preload("Labels").First(&reloaded, mr.ID)
+	names := make(map[string]bool)
+	for _, l := range reloaded.Labels {
+		names[l.Name] = true
+	}
+	if len(names) != 2 || !names["size/L"] || !names["backend"] {
+		t.Errorf("expected local labels size/L and backend, got %v", names)
+	}
+}
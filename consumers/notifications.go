@@ -0,0 +1,332 @@
+package consumers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/utils"
+)
+
+// notifyUserDM sends a DM about event unless the user turned that event off with /notify.
+// During the user's quiet hours the message is queued and sent by FlushQueuedNotifications.
+func (c *MRReviewerConsumer) notifyUserDM(user *models.User, event utils.NotificationEvent, text string) {
+	if user == nil || user.Email == "" {
+		return
+	}
+
+	pref := utils.GetNotificationPreference(c.db, user.ID)
+	if !utils.NotificationEnabled(pref, event) {
+		return
+	}
+	if utils.InQuietHours(pref, time.Now()) {
+		if err := c.db.Create(&models.QueuedNotification{UserID: user.ID, Text: text}).Error; err != nil {
+			log.Printf("failed to queue DM for %s: %v", user.Username, err)
+		}
+		return
+	}
+
+	msg := c.vkBot.NewTextMessage(user.Email, text)
+	if err := msg.Send(); err != nil {
+		log.Printf("DM to %s failed (user may not have messaged bot): %v", user.Email, err)
+	}
+}
+
+// FlushQueuedNotifications sends DMs queued during quiet hours as one message per user once
+// their quiet hours are over.
+func (c *MRReviewerConsumer) FlushQueuedNotifications() {
+	var queued []models.QueuedNotification
+	if err := c.db.Preload("User").Order("created_at").Find(&queued).Error; err != nil {
+		log.Printf("failed to fetch queued notifications: %v", err)
+		return
+	}
+
+	byUser := make(map[uint][]models.QueuedNotification)
+	var order []uint
+	for _, q := range queued {
+		if _, ok := byUser[q.UserID]; !ok {
+			order = append(order, q.UserID)
+		}
+		byUser[q.UserID] = append(byUser[q.UserID], q)
+	}
+
+	now := time.Now()
+	for _, userID := range order {
+		items := byUser[userID]
+		if utils.InQuietHours(utils.GetNotificationPreference(c.db, userID), now) {
+			continue
+		}
+
+		texts := make([]string, len(items))
+		ids := make([]uint, len(items))
+		for i, q := range items {
+			texts[i] = q.Text
+			ids[i] = q.ID
+		}
+		user := items[0].User
+		if user.Email != "" {
+			text := fmt.Sprintf("While you were away (%d):\n\n%s", len(items), strings.Join(texts, "\n\n"))
+			if err := c.vkBot.NewTextMessage(user.Email, text).Send(); err != nil {
+				log.Printf("DM to %s failed (user may not have messaged bot): %v", user.Email, err)
+			}
+		}
+		if err := c.db.Unscoped().Where("id IN ?", ids).Delete(&models.QueuedNotification{}).Error; err != nil {
+			log.Printf("failed to delete queued notifications of user %d: %v", userID, err)
+		}
+	}
+}
+
+// ProcessSLAWarnings DMs the people an MR is waiting on once its current state exceeds the
+// repository SLA: reviewers who have not approved for on_review, the author for on_fixes,
+// conflicted and pipeline_failed.
+// Each state entry is warned about once. States entered before the consumer's start time are
+// skipped, so MRs that were already overdue when the bot started are not warned about at once.
+func (c *MRReviewerConsumer) ProcessSLAWarnings() {
+	var mrs []models.MergeRequest
+	if err := c.db.
+		Preload("Repository").Preload("Author").Preload("Labels").Preload("Reviewers").Preload("Approvers").
+		Where("merge_requests.state = ? AND merge_requests.draft = ? AND merge_requests.merged_at IS NULL", "opened", false).
+		Where("EXISTS (SELECT 1 FROM repository_subscriptions WHERE repository_subscriptions.repository_id = merge_requests.repository_id)").
+		Where("EXISTS (SELECT 1 FROM merge_request_reviewers mrr WHERE mrr.merge_request_id = merge_requests.id)").
+		Find(&mrs).Error; err != nil {
+		log.Printf("failed to fetch merge requests for SLA warnings: %v", err)
+		return
+	}
+
+	for i := range mrs {
+		mr := &mrs[i]
+		if utils.HasReleaseLabel(c.db, mr) || utils.IsMRBlocked(c.db, mr) {
+			continue
+		}
+
+		info := utils.GetStateInfo(c.db, mr)
+		if info.StateSince == nil || info.StateSince.Before(c.startTime) {
+			continue
+		}
+		sla, err := utils.GetRepositorySLA(c.db, mr.RepositoryID)
+		if err != nil {
+			log.Printf("failed to get SLA for repository %d: %v", mr.RepositoryID, err)
+			continue
+		}
+
+		threshold := utils.SLAThresholdForState(sla, info.State)
+		if threshold == 0 {
+			continue
+		}
+		if exceeded, _ := utils.CheckSLAStatus(info.WorkingTime, threshold); !exceeded {
+			continue
+		}
+
+		var warned int64
+		c.db.Model(&models.MRAction{}).
+			Where("merge_request_id = ? AND action_type = ? AND timestamp >= ?", mr.ID, models.ActionSLAWarning, *info.StateSince).
+			Count(&warned)
+		if warned > 0 {
+			continue
+		}
+		if err := c.db.Create(&models.MRAction{
+			MergeRequestID: mr.ID,
+			ActionType:     models.ActionSLAWarning,
+			Timestamp:      time.Now(),
+			Metadata:       fmt.Sprintf(`{"state":%q}`, info.State),
+			Notified:       true,
+		}).Error; err != nil {
+			log.Printf("failed to record SLA warning for MR %d: %v", mr.ID, err)
+			continue
+		}
+
+		if utils.IsAuthorActionState(info.State) {
+			c.notifyUserDM(&mr.Author, utils.NotifySLAWarning, fmt.Sprintf(
+				"⏰ Fixes SLA exceeded on your MR [%s]:\n%s\n%s",
+				mr.Repository.Name, mr.Title, mr.WebURL,
+			))
+			continue
+		}
+
+		approved := make(map[uint]bool, len(mr.Approvers))
+		for _, a := range mr.Approvers {
+			approved[a.ID] = true
+		}
+		for j := range mr.Reviewers {
+			reviewer := &mr.Reviewers[j]
+			if approved[reviewer.ID] {
+				continue
+			}
+			c.notifyUserDM(reviewer, utils.NotifySLAWarning, fmt.Sprintf(
+				"⏰ Review SLA exceeded [%s]:\n%s\n%s",
+				mr.Repository.Name, mr.Title, mr.WebURL,
+			))
+		}
+	}
+}
+
+// ProcessPipelineNotifications DMs authors whose MR pipeline failed. A failure that was already
+// fixed or whose MR was closed by the time it is processed is skipped.
+func (c *MRReviewerConsumer) ProcessPipelineNotifications() {
+	var actions []models.MRAction
+	if err := c.db.
+		Preload("MergeRequest").
+		Preload("MergeRequest.Repository").
+		Preload("MergeRequest.Author").
+		Where("notified = ? AND action_type IN ?", false, []models.MRActionType{models.ActionPipelineFailed, models.ActionPipelineFixed}).
+		Order("timestamp ASC").
+		Limit(100).
+		Find(&actions).Error; err != nil {
+		log.Printf("failed to fetch unnotified pipeline actions: %v", err)
+		return
+	}
+
+	for _, action := range actions {
+		mr := action.MergeRequest
+		if action.ActionType == models.ActionPipelineFailed && mr.State == "opened" && mr.PipelineStatus == utils.PipelineFailed {
+			c.notifyUserDM(&mr.Author, utils.NotifyPipeline, fmt.Sprintf(
+				"❌ Pipeline failed on your MR [%s]:\n%s\n%s\nPipeline: %s",
+				mr.Repository.Name, mr.Title, mr.WebURL, mr.PipelineWebURL,
+			))
+		}
+		c.markActionNotified(action.ID)
+	}
+}
+
+// ProcessConflictNotifications DMs authors whose MR got merge conflicts or needs a rebase.
+// Conflicts already resolved by the time they are processed are skipped.
+func (c *MRReviewerConsumer) ProcessConflictNotifications() {
+	var actions []models.MRAction
+	if err := c.db.
+		Preload("MergeRequest").
+		Preload("MergeRequest.Repository").
+		Preload("MergeRequest.Author").
+		Where("notified = ? AND action_type IN ?", false, []models.MRActionType{models.ActionConflictDetected, models.ActionConflictResolved}).
+		Order("timestamp ASC").
+		Limit(100).
+		Find(&actions).Error; err != nil {
+		log.Printf("failed to fetch unnotified conflict actions: %v", err)
+		return
+	}
+
+	for _, action := range actions {
+		mr := action.MergeRequest
+		if action.ActionType == models.ActionConflictDetected && mr.State == "opened" {
+			switch utils.MergeBlocker(mr.HasConflicts, mr.DetailedMergeStatus) {
+			case utils.MergeBlockerConflict:
+				c.notifyUserDM(&mr.Author, utils.NotifyConflict, fmt.Sprintf(
+					"⚠️ Your MR has merge conflicts with %s [%s]:\n%s\n%s",
+					mr.TargetBranch, mr.Repository.Name, mr.Title, mr.WebURL,
+				))
+			case utils.MergeBlockerNeedRebase:
+				if c.autoRebaseHandles(&mr) {
+					break
+				}
+				c.notifyUserDM(&mr.Author, utils.NotifyConflict, fmt.Sprintf(
+					"🔁 Your MR needs a rebase onto %s [%s]:\n%s\n%s",
+					mr.TargetBranch, mr.Repository.Name, mr.Title, mr.WebURL,
+				))
+			}
+		}
+		c.markActionNotified(action.ID)
+	}
+}
+
+// ProcessReReviewRequests DMs approvers whose approval was reset by new commits, asking them to
+// review the changes again. Approvals reset by a rebase the bot started only need to be given again.
+func (c *MRReviewerConsumer) ProcessReReviewRequests() {
+	var actions []models.MRAction
+	if err := c.db.
+		Preload("MergeRequest").
+		Preload("MergeRequest.Repository").
+		Preload("Actor").
+		Where("notified = ? AND action_type = ? AND metadata IN ?", false, models.ActionUnapproved,
+			[]string{`{"reason":"commits_pushed"}`, `{"reason":"rebased"}`}).
+		Order("timestamp ASC").
+		Limit(100).
+		Find(&actions).Error; err != nil {
+		log.Printf("failed to fetch approvals reset by pushes: %v", err)
+		return
+	}
+
+	for _, action := range actions {
+		mr := action.MergeRequest
+		if mr.State == "opened" && action.Actor != nil {
+			text := fmt.Sprintf(
+				"🔄 New commits reset your approval [%s]:\n%s\n%s\nPlease review the changes again.",
+				mr.Repository.Name, mr.Title, mr.WebURL,
+			)
+			if action.Metadata == `{"reason":"rebased"}` {
+				text = fmt.Sprintf(
+					"🔄 Rebase onto %s reset your approval [%s]:\n%s\n%s\nPlease approve it again.",
+					mr.TargetBranch, mr.Repository.Name, mr.Title, mr.WebURL,
+				)
+			}
+			c.notifyUserDM(action.Actor, utils.NotifyStateChange, text)
+		}
+		c.markActionNotified(action.ID)
+	}
+}
+
+// ProcessDraftReadyNotifications announces MRs that left draft: their reviewers who have not
+// approved yet get a DM and subscribed chats get a "ready for review" post. MRs without reviewers
+// are announced by AssignReviewers when it assigns them, so this runs before it.
+func (c *MRReviewerConsumer) ProcessDraftReadyNotifications() {
+	recentCutoff := time.Now().UTC().Add(-30 * time.Minute)
+
+	var actions []models.MRAction
+	if err := c.db.
+		Preload("MergeRequest").
+		Preload("MergeRequest.Repository").
+		Preload("MergeRequest.Author").
+		Preload("MergeRequest.Reviewers").
+		Preload("MergeRequest.Approvers").
+		Where("notified = ? AND action_type = ?", false, models.ActionDraftToggled).
+		Order("timestamp ASC").
+		Limit(100).
+		Find(&actions).Error; err != nil {
+		log.Printf("failed to fetch unnotified draft toggles: %v", err)
+		return
+	}
+
+	for _, action := range actions {
+		mr := action.MergeRequest
+		if action.Metadata == `{"draft":false}` && action.Timestamp.After(recentCutoff) &&
+			mr.State == "opened" && !mr.Draft && len(mr.Reviewers) > 0 {
+			c.announceReadyForReview(&mr)
+		}
+		c.markActionNotified(action.ID)
+	}
+}
+
+// announceReadyForReview DMs the MR's reviewers who have not approved and posts to its subscribed chats.
+func (c *MRReviewerConsumer) announceReadyForReview(mr *models.MergeRequest) {
+	approved := make(map[uint]bool, len(mr.Approvers))
+	for _, a := range mr.Approvers {
+		approved[a.ID] = true
+	}
+	var pending []models.User
+	for _, r := range mr.Reviewers {
+		if !approved[r.ID] {
+			pending = append(pending, r)
+		}
+	}
+	for i := range pending {
+		c.notifyUserDM(&pending[i], utils.NotifyStateChange, fmt.Sprintf(
+			"✅ MR is ready for review [%s]:\n%s\n%s",
+			mr.Repository.Name, mr.Title, mr.WebURL,
+		))
+	}
+
+	var subs []models.RepositorySubscription
+	if err := c.db.Preload("Chat").Where("repository_id = ?", mr.RepositoryID).Find(&subs).Error; err != nil {
+		log.Printf("failed to fetch subscriptions: %v", err)
+		return
+	}
+	text := fmt.Sprintf("✅ Ready for review\n%s\n%s\nby @[%s]", mr.Title, mr.WebURL, c.formatAuthorMention(&mr.Author))
+	if len(pending) > 0 {
+		text += "\nreviewers: " + c.formatReviewerMentions(pending)
+	}
+	for _, sub := range subs {
+		if err := c.vkBot.NewTextMessage(sub.Chat.ChatID, text).Send(); err != nil {
+			log.Printf("failed to send ready for review post: %v", err)
+		}
+	}
+}
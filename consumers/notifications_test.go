@@ -0,0 +1,147 @@
+package consumers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"devstreamlinebot/mocks"
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+	"devstreamlinebot/utils"
+)
+
+// TestNotifyUserDM_Preferences tests that disabled events are dropped and DMs during quiet hours are batched afterwards.
+func TestNotifyUserDM_Preferences(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	mockBot := mocks.NewMockVKBot()
+	user := testutils.NewUserFactory(db).Create(testutils.WithEmail("dev@example.com"))
+	consumer := NewMRReviewerConsumerWithBot(db, mockBot, nil, 0, nil)
+
+	utils.UpdateNotificationPreference(db, user.ID, map[string]interface{}{"reviewer_removed": false})
+	consumer.notifyUserDM(&user, utils.NotifyReviewerRemoved, "removed")
+	if len(mockBot.GetSentMessages()) != 0 {
+		t.Fatal("expected disabled event not sent")
+	}
+
+	hour := time.Now().UTC().Hour()
+	utils.UpdateNotificationPreference(db, user.ID, map[string]interface{}{
+		"quiet_start": hour, "quiet_end": (hour + 1) % 24, "timezone_offset": 0,
+	})
+	consumer.notifyUserDM(&user, utils.NotifyAssignment, "first")
+	consumer.notifyUserDM(&user, utils.NotifyStateChange, "second")
+	consumer.FlushQueuedNotifications()
+	if len(mockBot.GetSentMessages()) != 0 {
+		t.Fatal("expected DMs held during quiet hours")
+	}
+
+	utils.UpdateNotificationPreference(db, user.ID, map[string]interface{}{"quiet_start": -1, "quiet_end": -1})
+	consumer.FlushQueuedNotifications()
+	sent := mockBot.GetSentMessages()
+	if len(sent) != 1 || sent[0].ChatID != "dev@example.com" {
+		t.Fatalf("expected one batched DM, got %d", len(sent))
+	}
+	if text := sent[0].Text; !containsAll(text, "first", "second") {
+		t.Errorf("expected both queued messages in the batch, got %q", text)
+	}
+
+	var remaining int64
+	db.Model(&models.QueuedNotification{}).Count(&remaining)
+	if remaining != 0 {
+		t.Errorf("expected queue emptied, got %d", remaining)
+	}
+}
+
+// TestProcessSLAWarnings tests that reviewers who have not approved are warned once when the review SLA is exceeded.
+func TestProcessSLAWarnings(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	mockBot := mocks.NewMockVKBot()
+	userFactory := testutils.NewUserFactory(db)
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	testutils.CreateSubscription(db, repo, testutils.NewChatFactory(db).Create(), testutils.NewVKUserFactory(db).Create())
+	db.Create(&models.RepositorySLA{RepositoryID: repo.ID, ReviewDuration: models.Duration(time.Hour), FixesDuration: models.Duration(time.Hour), AssignCount: 1})
+	author := userFactory.Create()
+	pending := userFactory.Create(testutils.WithEmail("pending@example.com"))
+	approved := userFactory.Create(testutils.WithEmail("approved@example.com"))
+
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author, testutils.WithCreatedAt(time.Now().AddDate(0, 0, -10)))
+	testutils.AssignReviewers(db, &mr, pending, approved)
+	testutils.AssignApprovers(db, &mr, approved)
+
+	NewMRReviewerConsumerWithBot(db, mockBot, nil, 0, nil).ProcessSLAWarnings()
+	if sent := mockBot.GetSentMessages(); len(sent) != 0 {
+		t.Fatalf("expected no warning for a state entered before the start time, got %d", len(sent))
+	}
+
+	startTime := time.Now().AddDate(0, 0, -30)
+	consumer := NewMRReviewerConsumerWithBot(db, mockBot, nil, 0, &startTime)
+	consumer.ProcessSLAWarnings()
+	consumer.ProcessSLAWarnings()
+
+	sent := mockBot.GetSentMessages()
+	if len(sent) != 1 || sent[0].ChatID != "pending@example.com" {
+		t.Fatalf("expected one warning to the pending reviewer, got %d", len(sent))
+	}
+}
+
+func containsAll(s string, parts ...string) bool {
+	for _, p := range parts {
+		if !strings.Contains(s, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// TestProcessReReviewRequests tests that approvers whose approval was reset by new commits are asked to review again.
+func TestProcessReReviewRequests(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	mockBot := mocks.NewMockVKBot()
+	userFactory := testutils.NewUserFactory(db)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	reset := userFactory.Create(testutils.WithEmail("reset@example.com"))
+	revoked := userFactory.Create(testutils.WithEmail("revoked@example.com"))
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author)
+
+	testutils.CreateMRAction(db, mr, models.ActionUnapproved, testutils.WithActor(reset), testutils.WithMetadata(`{"reason":"commits_pushed"}`))
+	testutils.CreateMRAction(db, mr, models.ActionUnapproved, testutils.WithActor(revoked))
+
+	consumer := NewMRReviewerConsumerWithBot(db, mockBot, nil, 0, nil)
+	consumer.ProcessReReviewRequests()
+	consumer.ProcessReReviewRequests()
+
+	sent := mockBot.GetSentMessages()
+	if len(sent) != 1 || sent[0].ChatID != "reset@example.com" {
+		t.Fatalf("expected one DM to the reset approver, got %d", len(sent))
+	}
+	if !containsAll(sent[0].Text, "reset your approval", mr.WebURL) {
+		t.Errorf("unexpected DM text %q", sent[0].Text)
+	}
+}
+
+// TestProcessReReviewRequests_Rebased tests that approvers whose approval was reset by a bot rebase are asked to approve again.
+func TestProcessReReviewRequests_Rebased(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	mockBot := mocks.NewMockVKBot()
+	userFactory := testutils.NewUserFactory(db)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	approver := userFactory.Create(testutils.WithEmail("approver@example.com"))
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author)
+
+	testutils.CreateMRAction(db, mr, models.ActionUnapproved, testutils.WithActor(approver), testutils.WithMetadata(`{"reason":"rebased"}`))
+
+	consumer := NewMRReviewerConsumerWithBot(db, mockBot, nil, 0, nil)
+	consumer.ProcessReReviewRequests()
+	consumer.ProcessReReviewRequests()
+
+	sent := mockBot.GetSentMessages()
+	if len(sent) != 1 || sent[0].ChatID != "approver@example.com" {
+		t.Fatalf("expected one DM to the approver, got %d", len(sent))
+	}
+	if !containsAll(sent[0].Text, "Rebase onto", "approve it again", mr.WebURL) {
+		t.Errorf("unexpected DM text %q", sent[0].Text)
+	}
+}
@@ -0,0 +1,84 @@
+package consumers
+
+import (
+	"log"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/utils"
+)
+
+// getPathReviewerGroups returns eligible reviewers per path reviewer pattern matching
+// any of the changed paths. Returns nil when no rule matches.
+func (c *MRReviewerConsumer) getPathReviewerGroups(mr *models.MergeRequest, paths []string, excludeUsers []models.User) map[string][]models.User {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	var rules []models.PathReviewer
+	if err := c.db.Where("repository_id = ?", mr.RepositoryID).Find(&rules).Error; err != nil {
+		log.Printf("failed to fetch path reviewers: %v", err)
+		return nil
+	}
+
+	matchedPatterns := make(map[string]bool)
+	userIDSet := make(map[uint]bool)
+	for _, rule := range rules {
+		if !matchedPatterns[rule.Pattern] && !matchesAnyPath(rule.Pattern, paths) {
+			continue
+		}
+		matchedPatterns[rule.Pattern] = true
+		userIDSet[rule.UserID] = true
+	}
+	if len(userIDSet) == 0 {
+		return nil
+	}
+
+	userIDs := make([]uint, 0, len(userIDSet))
+	for id := range userIDSet {
+		userIDs = append(userIDs, id)
+	}
+
+	excludeIDs := []uint{mr.AuthorID}
+	for _, u := range excludeUsers {
+		excludeIDs = append(excludeIDs, u.ID)
+	}
+
+	var users []models.User
+	if err := c.db.Scopes(utils.ActiveRepositoryUsers(mr.RepositoryID)).
+		Where("id IN ? AND id NOT IN ? AND on_vacation = ?", userIDs, excludeIDs, false).
+		Find(&users).Error; err != nil {
+		log.Printf("failed to fetch path reviewer users: %v", err)
+		return nil
+	}
+
+	userMap := make(map[uint]models.User)
+	for _, u := range users {
+		userMap[u.ID] = u
+	}
+
+	groups := make(map[string][]models.User)
+	for _, rule := range rules {
+		if !matchedPatterns[rule.Pattern] {
+			continue
+		}
+		if user, ok := userMap[rule.UserID]; ok {
+			groups[rule.Pattern] = append(groups[rule.Pattern], user)
+		}
+	}
+
+	if len(groups) == 0 {
+		return nil
+	}
+
+	log.Printf("Found %d path groups with reviewers for MR %d", len(groups), mr.ID)
+	return groups
+}
+
+func matchesAnyPath(pattern string, paths []string) bool {
+	for _, p := range paths {
+		if utils.MatchPathGlob(pattern, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package consumers
+
+import (
+	"reflect"
+	"testing"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+// TestGetPathReviewerGroups tests glob matching per rule and exclusion of author, excluded and vacationing users.
+func TestGetPathReviewerGroups(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	dba := userFactory.Create(testutils.WithUsername("dba"))
+	excluded := userFactory.Create(testutils.WithUsername("excluded"))
+	away := userFactory.Create(testutils.WithUsername("away"), testutils.WithOnVacation())
+	webDev := userFactory.Create(testutils.WithUsername("web-dev"))
+	testutils.CreatePathReviewer(db, repo, "**/migrations/*.sql", dba)
+	testutils.CreatePathReviewer(db, repo, "**/migrations/*.sql", excluded)
+	testutils.CreatePathReviewer(db, repo, "**/migrations/*.sql", away)
+	testutils.CreatePathReviewer(db, repo, "**/migrations/*.sql", author)
+	testutils.CreatePathReviewer(db, repo, "web/**", webDev)
+
+	created := testutils.NewMergeRequestFactory(db).Create(repo, author)
+	consumer := NewMRReviewerConsumerWithServices(db, nil, nil, nil, 0, nil)
+	mr := loadMRForReassign(t, consumer, created.ID)
+
+	groups := consumer.getPathReviewerGroups(&mr, []string{"db/migrations/001_init.sql", "cmd/main.go"}, []models.User{excluded})
+
+	if len(groups) != 1 {
+		t.Fatalf("expected only the migrations group, got %v", groups)
+	}
+	users := groups["**/migrations/*.sql"]
+	if len(users) != 1 || users[0].ID != dba.ID {
+		t.Errorf("expected only dba eligible, got %v", users)
+	}
+
+	if groups := consumer.getPathReviewerGroups(&mr, nil, nil); groups != nil {
+		t.Errorf("expected nil groups without changed paths, got %v", groups)
+	}
+}
+
+// TestSelectReviewersWithTrace_UsesPathReviewers tests that a matching path rule supplies a required reviewer.
+func TestSelectReviewersWithTrace_UsesPathReviewers(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	dba := userFactory.Create(testutils.WithUsername("dba"))
+	poolMember := userFactory.Create(testutils.WithUsername("pool-member"))
+	testutils.CreatePossibleReviewer(db, repo, poolMember)
+	testutils.CreatePathReviewer(db, repo, "**/migrations/*.sql", dba)
+
+	created := testutils.NewMergeRequestFactory(db).Create(repo, author)
+	mrService, filesService := newCodeOwnersMocks("", "db/migrations/002_users.sql")
+	consumer := NewMRReviewerConsumerWithServices(db, nil, mrService, filesService, 0, nil)
+	mr := loadMRForReassign(t, consumer, created.ID)
+
+	selected, trace := consumer.selectReviewersWithTrace(&mr, 2, nil)
+
+	if len(selected) != 2 || selected[0].ID != dba.ID || selected[1].ID != poolMember.ID {
+		t.Fatalf("expected path reviewer then pool fill, got %v", selected)
+	}
+	if names := trace.PathGroups["**/migrations/*.sql"]; len(names) != 1 || names[0] != "dba" {
+		t.Errorf("expected migrations group traced, got %v", trace.PathGroups)
+	}
+	if trace.Steps[0].Pool != "path **/migrations/*.sql" {
+		t.Errorf("expected path step first, got %s", trace.Steps[0].Pool)
+	}
+}
+
+// TestParsePathReviewersArgs tests quoted and bare globs with optional user lists.
+func TestParsePathReviewersArgs(t *testing.T) {
+	cases := []struct {
+		args      string
+		pattern   string
+		usernames []string
+	}{
+		{`"**/migrations/*.sql" alice,@bob`, "**/migrations/*.sql", []string{"alice", "bob"}},
+		{`"docs/**"`, "docs/**", nil},
+		{`web/** carol, carol`, "web/**", []string{"carol"}},
+		{`"unterminated`, "unterminated", nil},
+	}
+	for _, tc := range cases {
+		pattern, usernames := parsePathReviewersArgs(tc.args)
+		if pattern != tc.pattern || !reflect.DeepEqual(usernames, tc.usernames) {
+			t.Errorf("parsePathReviewersArgs(%q) = %q, %v; want %q, %v", tc.args, pattern, usernames, tc.pattern, tc.usernames)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package consumers
+
+import (
+	"testing"
+	"time"
+
+	"devstreamlinebot/mocks"
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+// TestProcessPipelineNotifications tests that the author is DMed about a failed pipeline once,
+// and that a failure already fixed by the time it is processed is skipped.
+func TestProcessPipelineNotifications(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	mockBot := mocks.NewMockVKBot()
+	userFactory := testutils.NewUserFactory(db)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create(testutils.WithEmail("author@example.com"))
+	other := userFactory.Create(testutils.WithEmail("other@example.com"))
+
+	failing := testutils.NewMergeRequestFactory(db).Create(repo, author)
+	db.Model(&failing).UpdateColumns(map[string]interface{}{"pipeline_status": "failed", "pipeline_web_url": "https://gitlab.example.com/p/1"})
+	fixed := testutils.NewMergeRequestFactory(db).Create(repo, other)
+	db.Model(&fixed).UpdateColumn("pipeline_status", "success")
+
+	now := time.Now()
+	testutils.CreateMRAction(db, failing, models.ActionPipelineFailed, testutils.WithTimestamp(now))
+	testutils.CreateMRAction(db, fixed, models.ActionPipelineFailed, testutils.WithTimestamp(now.Add(-time.Minute)))
+	testutils.CreateMRAction(db, fixed, models.ActionPipelineFixed, testutils.WithTimestamp(now))
+
+	consumer := NewMRReviewerConsumerWithBot(db, mockBot, nil, 0, nil)
+	consumer.ProcessPipelineNotifications()
+	consumer.ProcessPipelineNotifications()
+
+	sent := mockBot.GetSentMessages()
+	if len(sent) != 1 || sent[0].ChatID != "author@example.com" {
+		t.Fatalf("expected one DM to the author, got %d", len(sent))
+	}
+	if !containsAll(sent[0].Text, "Pipeline failed", "https://gitlab.example.com/p/1") {
+		t.Errorf("expected pipeline link in the DM, got %q", sent[0].Text)
+	}
+
+	var pending int64
+	db.Model(&models.MRAction{}).Where("notified = ?", false).Count(&pending)
+	if pending != 0 {
+		t.Errorf("expected all pipeline actions marked notified, got %d pending", pending)
+	}
+}
+
+// TestAssignReviewers_WaitsForPipeline tests that with wait_for_pipeline on, reviewers are assigned
+// only once the MR's pipeline succeeds.
+func TestAssignReviewers_WaitsForPipeline(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+	mockBot := mocks.NewMockVKBot()
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	chat := testutils.NewChatFactory(db).Create()
+	testutils.CreateSubscription(db, repo, chat, testutils.NewVKUserFactory(db).Create())
+	author := userFactory.Create()
+	testutils.CreatePossibleReviewer(db, repo, userFactory.Create(testutils.WithUsername("bob")))
+	db.Create(&models.RepositorySLA{RepositoryID: repo.ID, AssignCount: 1, MaxAutoReassigns: 1, WaitForPipeline: true})
+
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author)
+	db.Model(&mr).UpdateColumn("pipeline_status", "running")
+
+	mrService := &mocks.MockMergeRequestsService{}
+	consumer := NewMRReviewerConsumerWithServices(db, mockBot, mrService, nil, 0, nil)
+
+	consumer.AssignReviewers()
+	if len(mrService.UpdateMergeRequestCalls) != 0 {
+		t.Fatalf("expected assignment held while the pipeline runs, got %d updates", len(mrService.UpdateMergeRequestCalls))
+	}
+
+	db.Model(&mr).UpdateColumn("pipeline_status", "success")
+	consumer.AssignReviewers()
+	if len(mrService.UpdateMergeRequestCalls) != 1 {
+		t.Errorf("expected reviewers assigned after the pipeline succeeded, got %d updates", len(mrService.UpdateMergeRequestCalls))
+	}
+}
+
+// TestAssignReviewers_WaitsForPipelineNotCreatedYet tests that with wait_for_pipeline on, a new MR
+// polled before GitLab created its pipeline is held in a repository that has CI.
+func TestAssignReviewers_WaitsForPipelineNotCreatedYet(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+	mrFactory := testutils.NewMergeRequestFactory(db)
+	mockBot := mocks.NewMockVKBot()
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	chat := testutils.NewChatFactory(db).Create()
+	testutils.CreateSubscription(db, repo, chat, testutils.NewVKUserFactory(db).Create())
+	author := userFactory.Create()
+	testutils.CreatePossibleReviewer(db, repo, userFactory.Create(testutils.WithUsername("bob")))
+	db.Create(&models.RepositorySLA{RepositoryID: repo.ID, AssignCount: 1, MaxAutoReassigns: 1, WaitForPipeline: true})
+
+	merged := mrFactory.Create(repo, author, testutils.WithMRState("merged"))
+	db.Model(&merged).UpdateColumn("pipeline_id", 7)
+
+	mr := mrFactory.Create(repo, author)
+	db.Model(&mr).UpdateColumn("sha", "abc123")
+
+	mrService := &mocks.MockMergeRequestsService{}
+	consumer := NewMRReviewerConsumerWithServices(db, mockBot, mrService, nil, 0, nil)
+
+	consumer.AssignReviewers()
+	if len(mrService.UpdateMergeRequestCalls) != 0 {
+		t.Fatalf("expected assignment held until the pipeline exists, got %d updates", len(mrService.UpdateMergeRequestCalls))
+	}
+
+	db.Model(&mr).UpdateColumns(map[string]interface{}{"pipeline_sha": "abc123", "pipeline_status": "success"})
+	consumer.AssignReviewers()
+	if len(mrService.UpdateMergeRequestCalls) != 1 {
+		t.Errorf("expected reviewers assigned after the pipeline succeeded, got %d updates", len(mrService.UpdateMergeRequestCalls))
+	}
+}
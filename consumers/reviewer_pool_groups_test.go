@@ -0,0 +1,23 @@
+package consumers
+
+import (
+	"testing"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// TestParseAccessLevel tests role name parsing and formatting of GitLab access levels.
+func TestParseAccessLevel(t *testing.T) {
+	if level, ok := parseAccessLevel("Maintainer"); !ok || level != gitlab.MaintainerPermissions {
+		t.Errorf("unexpected parse: %v %v", level, ok)
+	}
+	if _, ok := parseAccessLevel("admin"); ok {
+		t.Error("expected unknown role rejected")
+	}
+	if name := accessLevelName(int(gitlab.DeveloperPermissions)); name != "developer" {
+		t.Errorf("unexpected name %q", name)
+	}
+	if name := accessLevelName(15); name != "level 15" {
+		t.Errorf("unexpected name %q", name)
+	}
+}
@@ -0,0 +1,468 @@
+package consumers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/utils"
+)
+
+var errNoReplacementAvailable = errors.New("no available reviewers to pick a replacement from")
+
+// Kinds of automatic reassignments. Only stale ones count against MaxAutoReassigns.
+const (
+	reassignStale    = "stale"
+	reassignInactive = "inactive"
+)
+
+// reassignMetadata is stored on reviewer_assigned actions of reassignments.
+type reassignMetadata struct {
+	ReassignedFrom string `json:"reassigned_from"`
+	Inactive       bool   `json:"inactive,omitempty"` // Replacement of an inactive reviewer
+}
+
+// ReassignReviewer replaces removed with replacement on the MR, or with a weighted pick from
+// the repository pools when replacement is nil. GitLab and the local reviewer list are updated,
+// removal/assignment actions recorded and the new reviewer and subscribed chats notified.
+// mr must be loaded with Repository, Labels and Reviewers.
+func (c *MRReviewerConsumer) ReassignReviewer(mr *models.MergeRequest, removed models.User, replacement *models.User, actorID *uint) (models.User, error) {
+	return c.reassignReviewer(mr, removed, replacement, actorID, "", "")
+}
+
+// reassignReviewer implements ReassignReviewer. A non-empty autoKind (reassignStale or
+// reassignInactive) marks the reassignment as automatic and autoReason is included in the chat
+// post. Stale reassignments are recorded as reviewer_auto_reassigned and count against MaxAutoReassigns.
+func (c *MRReviewerConsumer) reassignReviewer(mr *models.MergeRequest, removed models.User, replacement *models.User, actorID *uint, autoKind, autoReason string) (models.User, error) {
+	var remaining []models.User
+	isReviewer := false
+	for _, r := range mr.Reviewers {
+		if r.ID == removed.ID {
+			isReviewer = true
+			continue
+		}
+		remaining = append(remaining, r)
+	}
+	if !isReviewer {
+		return models.User{}, fmt.Errorf("%s is not a reviewer of this MR", removed.Username)
+	}
+
+	var newReviewer models.User
+	var trace *selectionTrace
+	if replacement != nil {
+		if replacement.ID == mr.AuthorID {
+			return models.User{}, fmt.Errorf("%s is the author of this MR", replacement.Username)
+		}
+		for _, r := range mr.Reviewers {
+			if r.ID == replacement.ID {
+				return models.User{}, fmt.Errorf("%s is already a reviewer of this MR", replacement.Username)
+			}
+		}
+		if reason := utils.UserIneligibleReason(c.db, mr.RepositoryID, replacement); reason != "" {
+			return models.User{}, fmt.Errorf("%s cannot review: %s", replacement.Username, reason)
+		}
+		newReviewer = *replacement
+	} else {
+		var picked *models.User
+		picked, trace = c.selectReplacement(mr, removed)
+		if picked == nil {
+			return models.User{}, errNoReplacementAvailable
+		}
+		newReviewer = *picked
+	}
+
+	reviewerIDs := make([]int, 0, len(remaining)+1)
+	for _, r := range remaining {
+		reviewerIDs = append(reviewerIDs, r.GitlabID)
+	}
+	reviewerIDs = append(reviewerIDs, newReviewer.GitlabID)
+
+	if _, _, err := c.mrService.UpdateMergeRequest(
+		mr.Repository.GitlabID, mr.IID,
+		&gitlab.UpdateMergeRequestOptions{ReviewerIDs: &reviewerIDs},
+	); err != nil {
+		return models.User{}, fmt.Errorf("failed to update reviewers in GitLab: %w", err)
+	}
+
+	c.saveSelectionTrace(mr.ID, trace)
+
+	if err := c.db.Model(mr).Association("Reviewers").Delete(&removed); err != nil {
+		log.Printf("failed to remove reviewer %d from MR %d: %v", removed.ID, mr.ID, err)
+	}
+	if err := c.db.Model(mr).Association("Reviewers").Append(&newReviewer); err != nil {
+		log.Printf("failed to add reviewer %d to MR %d: %v", newReviewer.ID, mr.ID, err)
+	}
+	mr.Reviewers = append(remaining, newReviewer)
+
+	// Sync sees no diff once the local association is updated, so actions are recorded here.
+	// The removal stays unnotified so ProcessReviewerRemovalNotifications DMs the removed reviewer.
+	now := time.Now().UTC()
+	removedID := removed.ID
+	newID := newReviewer.ID
+	metadata, _ := json.Marshal(reassignMetadata{
+		ReassignedFrom: removed.Username,
+		Inactive:       autoKind == reassignInactive,
+	})
+	if err := c.db.Create(&models.MRAction{
+		MergeRequestID: mr.ID,
+		ActionType:     models.ActionReviewerRemoved,
+		ActorID:        actorID,
+		TargetUserID:   &removedID,
+		Timestamp:      now,
+	}).Error; err != nil {
+		log.Printf("failed to record reviewer removal for MR %d: %v", mr.ID, err)
+	}
+	if err := c.db.Create(&models.MRAction{
+		MergeRequestID: mr.ID,
+		ActionType:     models.ActionReviewerAssigned,
+		ActorID:        actorID,
+		TargetUserID:   &newID,
+		Timestamp:      now,
+		Metadata:       string(metadata),
+		Notified:       true,
+	}).Error; err != nil {
+		log.Printf("failed to record reviewer assignment for MR %d: %v", mr.ID, err)
+	}
+	if autoKind == reassignStale {
+		if err := c.db.Create(&models.MRAction{
+			MergeRequestID: mr.ID,
+			ActionType:     models.ActionReviewerAutoReassigned,
+			TargetUserID:   &newID,
+			Timestamp:      now,
+			Metadata:       string(metadata),
+			Notified:       true,
+		}).Error; err != nil {
+			log.Printf("failed to record auto-reassignment for MR %d: %v", mr.ID, err)
+		}
+	}
+
+	var subs []models.RepositorySubscription
+	if err := c.db.Preload("Chat").Where("repository_id = ?", mr.RepositoryID).Find(&subs).Error; err != nil {
+		log.Printf("failed to fetch subscriptions: %v", err)
+	}
+	text := fmt.Sprintf(
+		"%s\n%s\nReviewer reassigned: %s → %s",
+		mr.Title,
+		mr.WebURL,
+		c.formatReviewerMentions([]models.User{removed}),
+		c.formatReviewerMentions([]models.User{newReviewer}),
+	)
+	if autoReason != "" {
+		text += "\n" + autoReason
+	}
+	for _, sub := range subs {
+		msg := c.vkBot.NewTextMessage(sub.Chat.ChatID, text)
+		if err := msg.Send(); err != nil {
+			log.Printf("failed to send reassignment notification: %v", err)
+		}
+	}
+
+	c.notifyUserDM(&newReviewer, utils.NotifyAssignment, fmt.Sprintf(
+		"🔍 MR reassigned to you for review [%s]:\n%s\n%s",
+		mr.Repository.Name,
+		mr.Title,
+		mr.WebURL,
+	))
+
+	log.Printf("Reassigned MR %d reviewer %s -> %s", mr.ID, removed.Username, newReviewer.Username)
+	return newReviewer, nil
+}
+
+// selectReplacement picks one new reviewer for an MR losing removed. Label and path groups the
+// removed reviewer covered are preferred so coverage is kept; otherwise the regular cascade is used.
+// If losing removed leaves the MR short of the required reviewer tier, the replacement comes from that tier.
+func (c *MRReviewerConsumer) selectReplacement(mr *models.MergeRequest, removed models.User) (*models.User, *selectionTrace) {
+	atCapacity := c.getUsersAtCapacity(mr.RepositoryID)
+	exclude := append(append([]models.User{}, mr.Reviewers...), atCapacity...)
+
+	var kept []models.User
+	for _, r := range mr.Reviewers {
+		if r.ID != removed.ID {
+			kept = append(kept, r)
+		}
+	}
+	if c.missingTierReviewers(mr.RepositoryID, kept) > 0 {
+		strategy := c.strategyFor(mr, c.getSelectionPaths(mr))
+		trace := &selectionTrace{Needed: 1, Backfill: true, Strategy: strategy.Name()}
+		c.traceExclusions(mr, mr.Reviewers, atCapacity, trace)
+		if picked, _ := c.selectRequiredTier(mr, kept, exclude, strategy, trace); len(picked) > 0 {
+			return &picked[0], trace
+		}
+	}
+
+	labelGroups := c.getLabelReviewerGroups(mr, exclude)
+
+	var coveredLabels []string
+	if len(labelGroups) > 0 {
+		labelNames := make([]string, len(mr.Labels))
+		for i, label := range mr.Labels {
+			labelNames[i] = label.Name
+		}
+		c.db.Model(&models.LabelReviewer{}).
+			Where("repository_id = ? AND user_id = ? AND label_name IN ?", mr.RepositoryID, removed.ID, labelNames).
+			Order("label_name").
+			Pluck("label_name", &coveredLabels)
+	}
+
+	var coveredPatterns []string
+	var pathGroups map[string][]models.User
+	c.db.Model(&models.PathReviewer{}).
+		Where("repository_id = ? AND user_id = ?", mr.RepositoryID, removed.ID).
+		Order("pattern").
+		Pluck("pattern", &coveredPatterns)
+	changedPaths := c.getSelectionPaths(mr)
+	if len(coveredPatterns) > 0 {
+		pathGroups = c.getPathReviewerGroups(mr, changedPaths, exclude)
+	}
+
+	var pool []models.User
+	var poolNames []string
+	seen := make(map[uint]bool)
+	addGroup := func(name string, users []models.User) {
+		if len(users) == 0 {
+			return
+		}
+		poolNames = append(poolNames, name)
+		for _, u := range users {
+			if !seen[u.ID] {
+				pool = append(pool, u)
+				seen[u.ID] = true
+			}
+		}
+	}
+	for _, label := range coveredLabels {
+		addGroup("label "+label, labelGroups[label])
+	}
+	for _, pattern := range coveredPatterns {
+		addGroup("path "+pattern, pathGroups[pattern])
+	}
+
+	if len(pool) > 0 {
+		strategy := c.strategyFor(mr, changedPaths)
+		trace := &selectionTrace{Needed: 1, Backfill: true, Strategy: strategy.Name()}
+		c.traceExclusions(mr, mr.Reviewers, atCapacity, trace)
+		trace.setLabelGroups(labelGroups)
+		trace.setPathGroups(pathGroups)
+
+		userIDs := make([]uint, len(pool))
+		for i, u := range pool {
+			userIDs[i] = u.ID
+		}
+		reviewCounts := c.getReviewCountsForUserIDs(userIDs)
+		picked := c.pickMultipleWith(strategy, pool, 1, reviewCounts)
+		trace.addStep(strategy, strings.Join(poolNames, ", "), pool, reviewCounts, picked)
+		return &picked[0], trace
+	}
+
+	selected, trace := c.selectReviewersWithTrace(mr, 1, mr.Reviewers)
+	trace.Backfill = true
+	if len(selected) == 0 {
+		return nil, trace
+	}
+	return &selected[0], trace
+}
+
+// ReassignStaleReviews swaps out reviewers who have not acted within the configured share
+// of the review SLA (or are on vacation), up to MaxAutoReassigns per MR.
+// Reviewers waiting for the author, approvers and MRs not on review are left alone.
+func (c *MRReviewerConsumer) ReassignStaleReviews() {
+	var slas []models.RepositorySLA
+	if err := c.db.Where("reassign_threshold > ?", 0).Find(&slas).Error; err != nil {
+		log.Printf("failed to fetch auto-reassign settings: %v", err)
+		return
+	}
+	if len(slas) == 0 {
+		return
+	}
+
+	slaByRepo := make(map[uint]models.RepositorySLA, len(slas))
+	repoIDs := make([]uint, len(slas))
+	for i, sla := range slas {
+		slaByRepo[sla.RepositoryID] = sla
+		repoIDs[i] = sla.RepositoryID
+	}
+
+	var mrs []models.MergeRequest
+	if err := c.db.
+		Preload("Repository").Preload("Author").Preload("Labels").Preload("Reviewers").Preload("Approvers").
+		Where("merge_requests.state = ? AND merge_requests.draft = ? AND merge_requests.merged_at IS NULL", "opened", false).
+		Where("merge_requests.repository_id IN ?", repoIDs).
+		Where("EXISTS (SELECT 1 FROM repository_subscriptions WHERE repository_subscriptions.repository_id = merge_requests.repository_id)").
+		Where("merge_requests.gitlab_created_at > ?", c.startTime).
+		Find(&mrs).Error; err != nil {
+		log.Printf("failed to fetch merge requests for auto-reassign: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, mr := range mrs {
+		if len(mr.Reviewers) == 0 {
+			continue
+		}
+		if utils.HasReleaseLabel(c.db, &mr) || utils.IsMRBlocked(c.db, &mr) {
+			continue
+		}
+		if utils.GetStateInfo(c.db, &mr).State != utils.StateOnReview {
+			continue
+		}
+
+		sla := slaByRepo[mr.RepositoryID]
+		remaining := sla.MaxAutoReassigns - c.countAutoReassigns(mr.ID)
+		if remaining <= 0 {
+			continue
+		}
+		threshold := sla.ReviewDuration.ToDuration() * time.Duration(sla.ReassignThreshold) / 100
+
+		for _, reviewer := range c.findStaleReviewers(&mr, threshold, now) {
+			if remaining <= 0 {
+				break
+			}
+			reason := fmt.Sprintf("No response for %s", utils.FormatDuration(threshold))
+			if reviewer.OnVacation {
+				reason = "Reviewer is on vacation"
+			}
+			if _, err := c.reassignReviewer(&mr, reviewer, nil, nil, reassignStale, reason); err != nil {
+				log.Printf("failed to auto-reassign %s on MR %d: %v", reviewer.Username, mr.ID, err)
+				continue
+			}
+			remaining--
+		}
+	}
+}
+
+// findStaleReviewers returns reviewers who have not approved and whose pending working time
+// exceeds threshold. Reviewers on vacation are always considered stale.
+func (c *MRReviewerConsumer) findStaleReviewers(mr *models.MergeRequest, threshold time.Duration, now time.Time) []models.User {
+	approved := make(map[uint]bool)
+	for _, a := range mr.Approvers {
+		approved[a.ID] = true
+	}
+
+	var stale []models.User
+	for _, reviewer := range mr.Reviewers {
+		if approved[reviewer.ID] {
+			continue
+		}
+		if reviewer.OnVacation {
+			stale = append(stale, reviewer)
+			continue
+		}
+		since := utils.GetReviewerPendingSince(c.db, mr, reviewer.ID)
+		if since == nil {
+			continue
+		}
+		if utils.CalculateWorkingTime(c.db, mr.RepositoryID, *since, now) >= threshold {
+			stale = append(stale, reviewer)
+		}
+	}
+	return stale
+}
+
+// ReplaceInactiveReviewers swaps out reviewers who have not approved yet and whose GitLab
+// account became inactive (blocked, deactivated, locked) or who left the project.
+// Unlike stale reassignment this needs no /sla reassign setting and does not count against
+// MaxAutoReassigns. If nobody can take over, subscribed chats are warned once per reviewer.
+func (c *MRReviewerConsumer) ReplaceInactiveReviewers() {
+	var mrs []models.MergeRequest
+	if err := c.db.
+		Preload("Repository").Preload("Author").Preload("Labels").Preload("Reviewers").Preload("Approvers").
+		Where("merge_requests.state = ? AND merge_requests.merged_at IS NULL", "opened").
+		Where("EXISTS (SELECT 1 FROM repository_subscriptions WHERE repository_subscriptions.repository_id = merge_requests.repository_id)").
+		Where("merge_requests.gitlab_created_at > ?", c.startTime).
+		Find(&mrs).Error; err != nil {
+		log.Printf("failed to fetch merge requests for inactive reviewer check: %v", err)
+		return
+	}
+
+	for _, mr := range mrs {
+		if len(mr.Reviewers) == 0 || utils.HasReleaseLabel(c.db, &mr) {
+			continue
+		}
+		approved := make(map[uint]bool, len(mr.Approvers))
+		for _, a := range mr.Approvers {
+			approved[a.ID] = true
+		}
+
+		reviewers := append([]models.User{}, mr.Reviewers...)
+		for _, reviewer := range reviewers {
+			if approved[reviewer.ID] {
+				continue
+			}
+			reason := utils.UserIneligibleReason(c.db, mr.RepositoryID, &reviewer)
+			if reason == "" {
+				continue
+			}
+			text := fmt.Sprintf("Reviewer account is %s", reason)
+			if reason == utils.InactiveNotMember {
+				text = "Reviewer is no longer a project member"
+			}
+			_, err := c.reassignReviewer(&mr, reviewer, nil, nil, reassignInactive, text)
+			if errors.Is(err, errNoReplacementAvailable) {
+				c.warnNoReplacement(&mr, reviewer, text)
+			} else if err != nil {
+				log.Printf("failed to replace inactive reviewer %s on MR %d: %v", reviewer.Username, mr.ID, err)
+			}
+		}
+	}
+}
+
+// warnNoReplacement tells subscribed chats that an inactive reviewer could not be replaced.
+// Warns once per MR and reviewer.
+func (c *MRReviewerConsumer) warnNoReplacement(mr *models.MergeRequest, reviewer models.User, reason string) {
+	var count int64
+	c.db.Model(&models.MRAction{}).
+		Where("merge_request_id = ? AND action_type = ? AND target_user_id = ?", mr.ID, models.ActionReviewerInactive, reviewer.ID).
+		Count(&count)
+	if count > 0 {
+		return
+	}
+
+	reviewerID := reviewer.ID
+	if err := c.db.Create(&models.MRAction{
+		MergeRequestID: mr.ID,
+		ActionType:     models.ActionReviewerInactive,
+		TargetUserID:   &reviewerID,
+		Timestamp:      time.Now().UTC(),
+		Metadata:       fmt.Sprintf(`{"reason":%q}`, reason),
+		Notified:       true,
+	}).Error; err != nil {
+		log.Printf("failed to record inactive reviewer %s on MR %d: %v", reviewer.Username, mr.ID, err)
+		return
+	}
+
+	var subs []models.RepositorySubscription
+	if err := c.db.Preload("Chat").Where("repository_id = ?", mr.RepositoryID).Find(&subs).Error; err != nil {
+		log.Printf("failed to fetch subscriptions: %v", err)
+		return
+	}
+	text := fmt.Sprintf(
+		"⚠️ No replacement available for reviewer %s:\n%s\n%s\n%s",
+		c.formatReviewerMentions([]models.User{reviewer}),
+		mr.Title,
+		mr.WebURL,
+		reason,
+	)
+	for _, sub := range subs {
+		msg := c.vkBot.NewTextMessage(sub.Chat.ChatID, text)
+		if err := msg.Send(); err != nil {
+			log.Printf("failed to send inactive reviewer warning: %v", err)
+		}
+	}
+}
+
+// countAutoReassigns returns how many automatic reassignments were made on the MR.
+func (c *MRReviewerConsumer) countAutoReassigns(mrID uint) int {
+	var count int64
+	if err := c.db.Model(&models.MRAction{}).
+		Where("merge_request_id = ? AND action_type = ?", mrID, models.ActionReviewerAutoReassigned).
+		Count(&count).Error; err != nil {
+		log.Printf("failed to count auto-reassignments for MR %d: %v", mrID, err)
+	}
+	return int(count)
+}
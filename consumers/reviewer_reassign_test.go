@@ -0,0 +1,293 @@
+package consumers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"gorm.io/gorm"
+
+	"devstreamlinebot/mocks"
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+func loadMRForReassign(t *testing.T, consumer *MRReviewerConsumer, mrID uint) models.MergeRequest {
+	t.Helper()
+	var mr models.MergeRequest
+	if err := consumer.db.Preload("Repository").Preload("Author").Preload("Labels").Preload("Reviewers").
+		First(&mr, mrID).Error; err != nil {
+		t.Fatalf("failed to load MR: %v", err)
+	}
+	return mr
+}
+
+// TestReassignReviewer_ExplicitReplacement tests GitLab update, local reviewers, actions and notifications.
+func TestReassignReviewer_ExplicitReplacement(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	mockBot := mocks.NewMockVKBot()
+	mrService := &mocks.MockMergeRequestsService{}
+	userFactory := testutils.NewUserFactory(db)
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	keeper := userFactory.Create(testutils.WithUsername("keeper"))
+	leaving := userFactory.Create(testutils.WithUsername("leaving"))
+	incoming := userFactory.Create(testutils.WithUsername("incoming"), testutils.WithEmail("incoming@example.com"))
+
+	created := testutils.NewMergeRequestFactory(db).Create(repo, author)
+	testutils.AssignReviewers(db, &created, keeper, leaving)
+
+	chat := testutils.NewChatFactory(db).Create()
+	testutils.CreateSubscription(db, repo, chat, testutils.NewVKUserFactory(db).Create())
+
+	consumer := NewMRReviewerConsumerWithServices(db, mockBot, mrService, nil, 0, nil)
+	mr := loadMRForReassign(t, consumer, created.ID)
+
+	newReviewer, err := consumer.ReassignReviewer(&mr, leaving, &incoming, &keeper.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if newReviewer.ID != incoming.ID {
+		t.Errorf("expected incoming as new reviewer, got %s", newReviewer.Username)
+	}
+
+	if len(mrService.UpdateMergeRequestCalls) != 1 {
+		t.Fatalf("expected 1 GitLab update, got %d", len(mrService.UpdateMergeRequestCalls))
+	}
+	ids := *mrService.UpdateMergeRequestCalls[0].Opt.ReviewerIDs
+	if len(ids) != 2 || ids[0] != keeper.GitlabID || ids[1] != incoming.GitlabID {
+		t.Errorf("unexpected reviewer IDs sent to GitLab: %v", ids)
+	}
+
+	reloaded := loadMRForReassign(t, consumer, mr.ID)
+	if len(reloaded.Reviewers) != 2 || containsUser(reloaded.Reviewers, leaving.ID) || !containsUser(reloaded.Reviewers, incoming.ID) {
+		t.Errorf("unexpected local reviewers: %v", reloaded.Reviewers)
+	}
+
+	var removal models.MRAction
+	if err := db.Where("merge_request_id = ? AND action_type = ? AND target_user_id = ?", mr.ID, models.ActionReviewerRemoved, leaving.ID).First(&removal).Error; err != nil {
+		t.Fatalf("expected removal action: %v", err)
+	}
+	if removal.Notified {
+		t.Error("removal action should stay unnotified for the removal DM flow")
+	}
+	var assignment models.MRAction
+	if err := db.Where("merge_request_id = ? AND action_type = ? AND target_user_id = ?", mr.ID, models.ActionReviewerAssigned, incoming.ID).First(&assignment).Error; err != nil {
+		t.Fatalf("expected assignment action: %v", err)
+	}
+	if assignment.ActorID == nil || *assignment.ActorID != keeper.ID {
+		t.Errorf("expected actor %d on assignment, got %v", keeper.ID, assignment.ActorID)
+	}
+
+	sent := mockBot.GetSentMessages()
+	if len(sent) != 2 {
+		t.Fatalf("expected chat post and DM, got %d messages", len(sent))
+	}
+	if sent[0].ChatID != chat.ChatID || !strings.Contains(sent[0].Text, "Reviewer reassigned") {
+		t.Errorf("unexpected chat message: %+v", sent[0])
+	}
+	if sent[1].ChatID != "incoming@example.com" {
+		t.Errorf("expected DM to incoming reviewer, got %s", sent[1].ChatID)
+	}
+}
+
+// TestReassignReviewer_PicksFromPool tests that a replacement is selected excluding current reviewers and the author.
+func TestReassignReviewer_PicksFromPool(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	leaving := userFactory.Create()
+	other := userFactory.Create()
+	candidate := userFactory.Create()
+	for _, u := range []models.User{author, leaving, other, candidate} {
+		testutils.CreatePossibleReviewer(db, repo, u)
+	}
+
+	created := testutils.NewMergeRequestFactory(db).Create(repo, author)
+	testutils.AssignReviewers(db, &created, leaving, other)
+
+	consumer := NewMRReviewerConsumerWithServices(db, mocks.NewMockVKBot(), &mocks.MockMergeRequestsService{}, nil, 0, nil)
+	mr := loadMRForReassign(t, consumer, created.ID)
+
+	newReviewer, err := consumer.ReassignReviewer(&mr, leaving, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if newReviewer.ID != candidate.ID {
+		t.Errorf("expected the only eligible candidate, got %s", newReviewer.Username)
+	}
+
+	var traces int64
+	db.Model(&models.ReviewerSelectionTrace{}).Where("merge_request_id = ?", mr.ID).Count(&traces)
+	if traces != 1 {
+		t.Errorf("expected selection trace for pool pick, got %d", traces)
+	}
+}
+
+// TestReassignReviewer_Errors tests validation and GitLab failure handling.
+func TestReassignReviewer_Errors(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	reviewer := userFactory.Create()
+	outsider := userFactory.Create()
+
+	created := testutils.NewMergeRequestFactory(db).Create(repo, author)
+	testutils.AssignReviewers(db, &created, reviewer)
+
+	mrService := &mocks.MockMergeRequestsService{}
+	consumer := NewMRReviewerConsumerWithServices(db, mocks.NewMockVKBot(), mrService, nil, 0, nil)
+	mr := loadMRForReassign(t, consumer, created.ID)
+
+	if _, err := consumer.ReassignReviewer(&mr, outsider, nil, nil); err == nil {
+		t.Error("expected error when removing a non-reviewer")
+	}
+	if _, err := consumer.ReassignReviewer(&mr, reviewer, &author, nil); err == nil {
+		t.Error("expected error when replacing with the author")
+	}
+	if _, err := consumer.ReassignReviewer(&mr, reviewer, nil, nil); !errors.Is(err, errNoReplacementAvailable) {
+		t.Errorf("expected errNoReplacementAvailable with empty pools, got %v", err)
+	}
+
+	mrService.UpdateMergeRequestFunc = func(pid interface{}, mergeRequest int, opt *gitlab.UpdateMergeRequestOptions, options ...gitlab.RequestOptionFunc) (*gitlab.MergeRequest, *gitlab.Response, error) {
+		return nil, nil, errors.New("gitlab down")
+	}
+	if _, err := consumer.ReassignReviewer(&mr, reviewer, &outsider, nil); err == nil {
+		t.Fatal("expected error when GitLab update fails")
+	}
+	reloaded := loadMRForReassign(t, consumer, mr.ID)
+	if len(reloaded.Reviewers) != 1 || reloaded.Reviewers[0].ID != reviewer.ID {
+		t.Errorf("local reviewers must not change on GitLab failure: %v", reloaded.Reviewers)
+	}
+}
+
+func setupAutoReassign(t *testing.T, threshold, maxReassigns int) (*gorm.DB, models.Repository, models.Chat) {
+	t.Helper()
+	db := testutils.SetupTestDB(t)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	sla := testutils.CreateRepositorySLA(db, repo, 1)
+	db.Model(&sla).Updates(map[string]interface{}{"reassign_threshold": threshold, "max_auto_reassigns": maxReassigns})
+	chat := testutils.NewChatFactory(db).Create()
+	testutils.CreateSubscription(db, repo, chat, testutils.NewVKUserFactory(db).Create())
+	return db, repo, chat
+}
+
+// TestReassignStaleReviews_SwapsInactiveReviewer tests that an inactive reviewer is swapped and the chat is told why.
+func TestReassignStaleReviews_SwapsInactiveReviewer(t *testing.T) {
+	db, repo, chat := setupAutoReassign(t, 50, 1)
+	mockBot := mocks.NewMockVKBot()
+	mrService := &mocks.MockMergeRequestsService{}
+	userFactory := testutils.NewUserFactory(db)
+
+	author := userFactory.Create()
+	stale := userFactory.Create(testutils.WithUsername("stale"))
+	fresh := userFactory.Create(testutils.WithUsername("fresh"))
+	testutils.CreatePossibleReviewer(db, repo, stale)
+	testutils.CreatePossibleReviewer(db, repo, fresh)
+
+	weekAgo := time.Now().Add(-7 * 24 * time.Hour)
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author, testutils.WithCreatedAt(weekAgo))
+	testutils.AssignReviewers(db, &mr, stale)
+	testutils.CreateMRAction(db, mr, models.ActionReviewerAssigned,
+		testutils.WithTargetUser(stale), testutils.WithTimestamp(weekAgo))
+
+	startTime := time.Now().Add(-30 * 24 * time.Hour)
+	consumer := NewMRReviewerConsumerWithServices(db, mockBot, mrService, nil, 0, &startTime)
+	consumer.ReassignStaleReviews()
+
+	if len(mrService.UpdateMergeRequestCalls) != 1 {
+		t.Fatalf("expected 1 GitLab update, got %d", len(mrService.UpdateMergeRequestCalls))
+	}
+	reloaded := loadMRForReassign(t, consumer, mr.ID)
+	if len(reloaded.Reviewers) != 1 || reloaded.Reviewers[0].ID != fresh.ID {
+		t.Errorf("expected fresh to replace stale, got %v", reloaded.Reviewers)
+	}
+	if consumer.countAutoReassigns(mr.ID) != 1 {
+		t.Errorf("expected 1 auto-reassignment recorded, got %d", consumer.countAutoReassigns(mr.ID))
+	}
+
+	sent := mockBot.GetSentMessages()
+	if len(sent) == 0 || sent[0].ChatID != chat.ChatID || !strings.Contains(sent[0].Text, "No response for 1d") {
+		t.Errorf("expected chat announcement with reason, got %+v", sent)
+	}
+
+	// The cap of 1 stops further reassignment even once the new reviewer goes stale.
+	db.Model(&models.MRAction{}).Where("merge_request_id = ? AND target_user_id = ?", mr.ID, fresh.ID).
+		Update("timestamp", weekAgo)
+	consumer.ReassignStaleReviews()
+	if len(mrService.UpdateMergeRequestCalls) != 1 {
+		t.Errorf("expected cap to prevent another reassignment, got %d updates", len(mrService.UpdateMergeRequestCalls))
+	}
+}
+
+// TestReassignStaleReviews_SkipsActiveAndApproved tests that recent, approved and disabled cases are left alone.
+func TestReassignStaleReviews_SkipsActiveAndApproved(t *testing.T) {
+	db, repo, _ := setupAutoReassign(t, 50, 3)
+	mrService := &mocks.MockMergeRequestsService{}
+	userFactory := testutils.NewUserFactory(db)
+	mrFactory := testutils.NewMergeRequestFactory(db)
+
+	author := userFactory.Create()
+	recent := userFactory.Create()
+	approver := userFactory.Create()
+	spare := userFactory.Create()
+	testutils.CreatePossibleReviewer(db, repo, spare)
+
+	recentMR := mrFactory.Create(repo, author, testutils.WithCreatedAt(time.Now().Add(-time.Hour)))
+	testutils.AssignReviewers(db, &recentMR, recent)
+
+	weekAgo := time.Now().Add(-7 * 24 * time.Hour)
+	approvedMR := mrFactory.Create(repo, author, testutils.WithCreatedAt(weekAgo))
+	testutils.AssignReviewers(db, &approvedMR, approver, recent)
+	testutils.AssignApprovers(db, &approvedMR, approver, recent)
+
+	otherRepo := testutils.NewRepositoryFactory(db).Create()
+	disabledMR := mrFactory.Create(otherRepo, author, testutils.WithCreatedAt(weekAgo))
+	testutils.AssignReviewers(db, &disabledMR, recent)
+
+	startTime := time.Now().Add(-30 * 24 * time.Hour)
+	consumer := NewMRReviewerConsumerWithServices(db, mocks.NewMockVKBot(), mrService, nil, 0, &startTime)
+	consumer.ReassignStaleReviews()
+
+	if len(mrService.UpdateMergeRequestCalls) != 0 {
+		t.Errorf("expected no reassignments, got %d", len(mrService.UpdateMergeRequestCalls))
+	}
+}
+
+// TestSelectReplacement_PrefersCoveredLabelGroup tests that the replacement keeps the removed reviewer's label coverage.
+func TestSelectReplacement_PrefersCoveredLabelGroup(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	backendOld := userFactory.Create()
+	backendNew := userFactory.Create()
+	frontend := userFactory.Create()
+	testutils.CreateLabelReviewer(db, repo, "backend", backendOld)
+	testutils.CreateLabelReviewer(db, repo, "backend", backendNew)
+	testutils.CreateLabelReviewer(db, repo, "frontend", frontend)
+
+	created := testutils.NewMergeRequestFactory(db).Create(repo, author, testutils.WithLabels(db, "backend", "frontend"))
+	testutils.AssignReviewers(db, &created, backendOld)
+
+	consumer := NewMRReviewerConsumer(db, nil, nil, 0, nil)
+	mr := loadMRForReassign(t, consumer, created.ID)
+
+	for i := 0; i < 10; i++ {
+		picked, trace := consumer.selectReplacement(&mr, backendOld)
+		if picked == nil || picked.ID != backendNew.ID {
+			t.Fatalf("expected backend replacement, got %v", picked)
+		}
+		if len(trace.Steps) != 1 || trace.Steps[0].Pool != "label backend" {
+			t.Errorf("unexpected trace steps: %+v", trace.Steps)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package consumers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/utils"
+)
+
+// SimulationOptions configures a dry-run replay of historical MRs.
+type SimulationOptions struct {
+	Since       time.Time
+	Strategy    string // Overrides the repository strategy when set
+	AssignCount int    // Overrides the repository AssignCount when > 0
+}
+
+// SimulationReport summarizes the outcome of a replay.
+type SimulationReport struct {
+	RepositoryName string
+	Strategy       string
+	AssignCount    int
+	MRCount        int
+	Shortfalls     int // MRs that got fewer reviewers than needed
+	Loads          []SimulatedLoad
+	Gini           float64
+	StdDev         float64
+	LabelGroups    []LabelGroupFill
+}
+
+// SimulatedLoad is the number of simulated assignments of one user.
+type SimulatedLoad struct {
+	Username string
+	Assigned int
+}
+
+// LabelGroupFill counts MRs carrying a label with configured reviewers and how often nobody was picked for it.
+type LabelGroupFill struct {
+	Label    string
+	MRs      int
+	Unfilled int
+}
+
+var errSimulationRollback = errors.New("simulation rollback")
+
+// SimulateAssignments replays MRs of a repository created since opts.Since through reviewer
+// selection in creation order. The replay runs on a snapshot of the database, so the live
+// database is only read once: reviewers of replayed MRs are replaced by simulated picks as the
+// replay advances, so load-aware strategies and capacity limits see the simulated history, and
+// recent review counts are taken at each MR's creation time. No GitLab calls or notifications
+// are made, so CODEOWNERS and path rules (which need changed files) are not applied.
+func (c *MRReviewerConsumer) SimulateAssignments(repoID uint, opts SimulationOptions) (*SimulationReport, error) {
+	if opts.Strategy != "" && !IsValidReviewerStrategy(opts.Strategy) {
+		return nil, fmt.Errorf("unknown strategy: %s", opts.Strategy)
+	}
+
+	snapshot, cleanup, err := openSimulationSnapshot(c.db)
+	if err != nil {
+		return nil, fmt.Errorf("copying database: %w", err)
+	}
+	defer cleanup()
+
+	var report *SimulationReport
+	err = snapshot.Transaction(func(tx *gorm.DB) error {
+		var err error
+		report, err = simulateInTx(tx, repoID, opts)
+		if err != nil {
+			return err
+		}
+		return errSimulationRollback
+	})
+	if err != nil && !errors.Is(err, errSimulationRollback) {
+		return nil, err
+	}
+	return report, nil
+}
+
+// openSimulationSnapshot copies the database into a temporary SQLite file with VACUUM INTO.
+// The returned cleanup closes and removes the copy.
+func openSimulationSnapshot(db *gorm.DB) (*gorm.DB, func(), error) {
+	dir, err := os.MkdirTemp("", "devstreamlinebot-simulation-")
+	if err != nil {
+		return nil, nil, err
+	}
+	path := filepath.Join(dir, "snapshot.db")
+	if err := db.Exec("VACUUM INTO ?", path).Error; err != nil {
+		os.RemoveAll(dir)
+		return nil, nil, err
+	}
+	snapshot, err := gorm.Open(sqlite.Open(path), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, nil, err
+	}
+	return snapshot, func() {
+		if sqlDB, err := snapshot.DB(); err == nil {
+			sqlDB.Close()
+		}
+		os.RemoveAll(dir)
+	}, nil
+}
+
+func simulateInTx(tx *gorm.DB, repoID uint, opts SimulationOptions) (*SimulationReport, error) {
+	var repo models.Repository
+	if err := tx.First(&repo, repoID).Error; err != nil {
+		return nil, fmt.Errorf("loading repository: %w", err)
+	}
+
+	var sla models.RepositorySLA
+	if err := tx.Where(models.RepositorySLA{RepositoryID: repoID}).FirstOrCreate(&sla).Error; err != nil {
+		return nil, fmt.Errorf("loading SLA: %w", err)
+	}
+	if opts.Strategy != "" {
+		sla.ReviewerStrategy = opts.Strategy
+	}
+	if opts.AssignCount > 0 {
+		sla.AssignCount = opts.AssignCount
+	}
+	if err := tx.Save(&sla).Error; err != nil {
+		return nil, fmt.Errorf("applying overrides: %w", err)
+	}
+
+	var mrs []models.MergeRequest
+	if err := tx.Preload("Repository").Preload("Author").Preload("Labels").
+		Where("repository_id = ? AND gitlab_created_at >= ?", repoID, opts.Since).
+		Order("gitlab_created_at").
+		Find(&mrs).Error; err != nil {
+		return nil, fmt.Errorf("loading merge requests: %w", err)
+	}
+
+	var replay []models.MergeRequest
+	for _, mr := range mrs {
+		if !utils.HasReleaseLabel(tx, &mr) {
+			replay = append(replay, mr)
+		}
+	}
+
+	if len(replay) > 0 {
+		mrIDs := make([]uint, len(replay))
+		for i, mr := range replay {
+			mrIDs[i] = mr.ID
+		}
+		if err := tx.Exec("DELETE FROM merge_request_reviewers WHERE merge_request_id IN ?", mrIDs).Error; err != nil {
+			return nil, fmt.Errorf("clearing reviewers: %w", err)
+		}
+	}
+
+	var configuredLabels []string
+	tx.Model(&models.LabelReviewer{}).Where("repository_id = ?", repoID).Distinct().Pluck("label_name", &configuredLabels)
+	labelConfigured := make(map[string]bool, len(configuredLabels))
+	for _, l := range configuredLabels {
+		labelConfigured[l] = true
+	}
+
+	sim := &MRReviewerConsumer{db: tx}
+	strategy := sla.ReviewerStrategy
+	if strategy == "" {
+		strategy = StrategyWeighted
+	}
+	report := &SimulationReport{
+		RepositoryName: repo.Name,
+		Strategy:       strategy,
+		AssignCount:    sim.getAssignCount(repoID),
+		MRCount:        len(replay),
+	}
+
+	assigned := make(map[uint]int)
+	usernames := make(map[uint]string)
+	labelFills := make(map[string]*LabelGroupFill)
+
+	for i := range replay {
+		mr := &replay[i]
+		sim.replayTime = mr.GitlabCreatedAt
+		needed := sim.getAssignCountForMR(mr)
+		selected, trace := sim.selectReviewersWithTrace(mr, needed, nil)
+		if len(selected) < needed {
+			report.Shortfalls++
+		}
+		if len(selected) > 0 {
+			if err := tx.Model(mr).Association("Reviewers").Append(selected); err != nil {
+				return nil, fmt.Errorf("assigning simulated reviewers: %w", err)
+			}
+		}
+		for _, u := range selected {
+			assigned[u.ID]++
+			usernames[u.ID] = u.Username
+		}
+
+		for _, label := range mr.Labels {
+			if !labelConfigured[label.Name] {
+				continue
+			}
+			fill, ok := labelFills[label.Name]
+			if !ok {
+				fill = &LabelGroupFill{Label: label.Name}
+				labelFills[label.Name] = fill
+			}
+			fill.MRs++
+			if !traceFilledPool(trace, "label "+label.Name) {
+				fill.Unfilled++
+			}
+		}
+	}
+
+	var poolUsers []models.User
+	tx.Where("id IN (?) OR id IN (?)",
+		tx.Model(&models.PossibleReviewer{}).Select("user_id").Where("repository_id = ?", repoID),
+		tx.Model(&models.LabelReviewer{}).Select("user_id").Where("repository_id = ?", repoID),
+	).Find(&poolUsers)
+	for _, u := range poolUsers {
+		usernames[u.ID] = u.Username
+	}
+
+	values := make([]float64, 0, len(usernames))
+	for userID, username := range usernames {
+		report.Loads = append(report.Loads, SimulatedLoad{Username: username, Assigned: assigned[userID]})
+		values = append(values, float64(assigned[userID]))
+	}
+	sort.Slice(report.Loads, func(i, j int) bool {
+		if report.Loads[i].Assigned != report.Loads[j].Assigned {
+			return report.Loads[i].Assigned > report.Loads[j].Assigned
+		}
+		return report.Loads[i].Username < report.Loads[j].Username
+	})
+	report.Gini = utils.GiniCoefficient(values)
+	report.StdDev = utils.StdDev(values)
+
+	for _, fill := range labelFills {
+		report.LabelGroups = append(report.LabelGroups, *fill)
+	}
+	sort.Slice(report.LabelGroups, func(i, j int) bool {
+		return report.LabelGroups[i].Label < report.LabelGroups[j].Label
+	})
+
+	return report, nil
+}
+
+// traceFilledPool reports whether a trace step for pool picked someone.
+func traceFilledPool(trace *selectionTrace, pool string) bool {
+	for _, step := range trace.Steps {
+		if step.Pool == pool && len(step.Picked) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatSimulationReport renders a simulation report for chat or terminal output.
+func FormatSimulationReport(report *SimulationReport, since time.Time) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("SIMULATION: %s since %s\n", report.RepositoryName, since.Format("2006-01-02")))
+	sb.WriteString(fmt.Sprintf("Strategy: %s, assign count: %d\n", report.Strategy, report.AssignCount))
+	sb.WriteString(fmt.Sprintf("MRs replayed: %d, short of reviewers: %d\n", report.MRCount, report.Shortfalls))
+
+	if len(report.Loads) > 0 {
+		sb.WriteString("\nLoad:\n")
+		for _, l := range report.Loads {
+			sb.WriteString(fmt.Sprintf("- %s: %d\n", l.Username, l.Assigned))
+		}
+		sb.WriteString(fmt.Sprintf("Fairness: gini %.2f, stdev %.2f\n", report.Gini, report.StdDev))
+	}
+
+	if len(report.LabelGroups) > 0 {
+		sb.WriteString("\nLabel groups:\n")
+		for _, g := range report.LabelGroups {
+			sb.WriteString(fmt.Sprintf("- %s: unfilled %d of %d MRs\n", g.Label, g.Unfilled, g.MRs))
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,101 @@
+package consumers
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+// TestSimulateAssignments_ReportsLoadAndRollsBack tests the report contents and that no data is changed.
+func TestSimulateAssignments_ReportsLoadAndRollsBack(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+	mrFactory := testutils.NewMergeRequestFactory(db)
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	alice := userFactory.Create(testutils.WithUsername("alice"))
+	bob := userFactory.Create(testutils.WithUsername("bob"))
+	carol := userFactory.Create(testutils.WithUsername("carol"), testutils.WithOnVacation())
+	testutils.CreatePossibleReviewer(db, repo, alice)
+	testutils.CreatePossibleReviewer(db, repo, bob)
+	testutils.CreateLabelReviewer(db, repo, "db", carol)
+
+	var mrs []models.MergeRequest
+	for i := 0; i < 4; i++ {
+		opts := []testutils.MROption{testutils.WithCreatedAt(time.Now().Add(time.Duration(i-10) * time.Hour))}
+		if i < 2 {
+			opts = append(opts, testutils.WithLabels(db, "db"))
+		}
+		mr := mrFactory.Create(repo, author, opts...)
+		testutils.AssignReviewers(db, &mr, alice)
+		mrs = append(mrs, mr)
+	}
+	mrFactory.Create(repo, author, testutils.WithCreatedAt(time.Now().AddDate(0, 0, -60)))
+
+	consumer := NewMRReviewerConsumerWithServices(db, nil, nil, nil, 0, nil)
+	report, err := consumer.SimulateAssignments(repo.ID, SimulationOptions{
+		Since:    time.Now().AddDate(0, 0, -30),
+		Strategy: StrategyRoundRobin,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if report.MRCount != 4 || report.Shortfalls != 0 || report.Strategy != StrategyRoundRobin || report.AssignCount != 1 {
+		t.Errorf("unexpected summary: %+v", report)
+	}
+	loads := make(map[string]int)
+	for _, l := range report.Loads {
+		loads[l.Username] = l.Assigned
+	}
+	if loads["alice"] != 2 || loads["bob"] != 2 || loads["carol"] != 0 || len(loads) != 3 {
+		t.Errorf("expected even rotation over alice and bob, got %v", loads)
+	}
+	if math.Abs(report.Gini-1.0/3) > 1e-9 {
+		t.Errorf("expected gini 1/3, got %v", report.Gini)
+	}
+	if len(report.LabelGroups) != 1 || report.LabelGroups[0] != (LabelGroupFill{Label: "db", MRs: 2, Unfilled: 2}) {
+		t.Errorf("expected db label group unfilled twice, got %v", report.LabelGroups)
+	}
+
+	for _, mr := range mrs {
+		reloaded := loadMRForReassign(t, consumer, mr.ID)
+		if len(reloaded.Reviewers) != 1 || reloaded.Reviewers[0].ID != alice.ID {
+			t.Errorf("expected reviewers of MR %d untouched, got %v", mr.ID, reloaded.Reviewers)
+		}
+	}
+	var slaCount int64
+	db.Model(&models.RepositorySLA{}).Count(&slaCount)
+	if slaCount != 0 {
+		t.Error("expected strategy override to be rolled back")
+	}
+
+	text := FormatSimulationReport(report, time.Now())
+	if !strings.Contains(text, "MRs replayed: 4") || !strings.Contains(text, "- db: unfilled 2 of 2 MRs") {
+		t.Errorf("unexpected report text: %q", text)
+	}
+}
+
+// TestParseSimulateArgs tests argument parsing in any order and invalid input.
+func TestParseSimulateArgs(t *testing.T) {
+	days, opts, err := parseSimulateArgs([]string{"count=2", "least_open", "14d"})
+	if err != nil || days != 14 || opts.AssignCount != 2 || opts.Strategy != StrategyLeastOpen {
+		t.Errorf("unexpected parse: %d %+v %v", days, opts, err)
+	}
+
+	days, _, err = parseSimulateArgs(nil)
+	if err != nil || days != defaultSimulationDays {
+		t.Errorf("expected default days, got %d %v", days, err)
+	}
+
+	for _, args := range [][]string{{"count=0"}, {"sometimes"}, {"-5"}} {
+		if _, _, err := parseSimulateArgs(args); err == nil {
+			t.Errorf("expected error for %v", args)
+		}
+	}
+}
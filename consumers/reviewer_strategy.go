@@ -0,0 +1,259 @@
+package consumers
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/utils"
+)
+
+// Reviewer selection strategy names stored in RepositorySLA.ReviewerStrategy.
+const (
+	StrategyWeighted   = "weighted"
+	StrategyRoundRobin = "round_robin"
+	StrategyLeastOpen  = "least_open"
+	StrategyExpertise  = "expertise"
+)
+
+// ReviewerStrategies lists the available strategy names.
+var ReviewerStrategies = []string{StrategyWeighted, StrategyRoundRobin, StrategyLeastOpen, StrategyExpertise}
+
+// ReviewerStrategy decides which candidate of an eligible pool is picked next.
+// reviewCounts holds recent review counts of the candidates (see utils.GetRecentReviewCounts).
+type ReviewerStrategy interface {
+	Name() string
+	// Pick returns the index of the chosen candidate. candidates is never empty.
+	Pick(candidates []models.User, reviewCounts map[uint]int) int
+	// Probabilities returns each candidate's chance of being picked, for selection traces.
+	Probabilities(candidates []models.User, reviewCounts map[uint]int) []float64
+}
+
+// IsValidReviewerStrategy reports whether name is a known strategy.
+func IsValidReviewerStrategy(name string) bool {
+	for _, s := range ReviewerStrategies {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// getReviewerStrategyName returns the configured strategy of a repository (weighted by default).
+func (c *MRReviewerConsumer) getReviewerStrategyName(repoID uint) string {
+	sla, err := utils.GetRepositorySLA(c.db, repoID)
+	if err != nil || sla.ReviewerStrategy == "" {
+		return StrategyWeighted
+	}
+	return sla.ReviewerStrategy
+}
+
+// strategyFor builds the configured strategy for an MR.
+func (c *MRReviewerConsumer) strategyFor(mr *models.MergeRequest, changedPaths []string) ReviewerStrategy {
+	switch c.getReviewerStrategyName(mr.RepositoryID) {
+	case StrategyRoundRobin:
+		return newRankedStrategy(StrategyRoundRobin, c.roundRobinRanks(mr))
+	case StrategyLeastOpen:
+		return newRankedStrategy(StrategyLeastOpen, c.leastOpenRanks)
+	case StrategyExpertise:
+		return newRankedStrategy(StrategyExpertise, c.expertiseRanks(mr, changedPaths))
+	default:
+		return weightedStrategy{}
+	}
+}
+
+// weightedStrategy draws randomly with weights favouring users with fewer recent reviews.
+type weightedStrategy struct{}
+
+func (weightedStrategy) Name() string { return StrategyWeighted }
+
+func (weightedStrategy) Pick(users []models.User, reviewCounts map[uint]int) int {
+	if len(users) <= 1 {
+		return 0
+	}
+
+	weights := make([]float64, len(users))
+	totalWeight := 0.0
+
+	for i, user := range users {
+		weight := utils.SelectionWeight(reviewCounts[user.ID])
+		weights[i] = weight
+		totalWeight += weight
+	}
+
+	if totalWeight <= 0 {
+		return safeRand.Intn(len(users))
+	}
+
+	for i := range weights {
+		weights[i] /= totalWeight
+	}
+
+	r := safeRand.Float64()
+	cumulativeWeight := 0.0
+
+	for i, weight := range weights {
+		cumulativeWeight += weight
+		if r <= cumulativeWeight {
+			return i
+		}
+	}
+
+	return safeRand.Intn(len(users))
+}
+
+func (weightedStrategy) Probabilities(users []models.User, reviewCounts map[uint]int) []float64 {
+	return selectionProbabilities(users, reviewCounts)
+}
+
+// rankedStrategy deterministically picks the candidate with the lowest rank.
+// Ties go to fewer recent reviews, then to the alphabetically first username.
+type rankedStrategy struct {
+	name string
+	rank func(candidates []models.User) map[uint]float64
+}
+
+func newRankedStrategy(name string, rank func(candidates []models.User) map[uint]float64) rankedStrategy {
+	return rankedStrategy{name: name, rank: rank}
+}
+
+func (s rankedStrategy) Name() string { return s.name }
+
+func (s rankedStrategy) Pick(candidates []models.User, reviewCounts map[uint]int) int {
+	if len(candidates) <= 1 {
+		return 0
+	}
+	ranks := s.rank(candidates)
+	order := make([]int, len(candidates))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ua, ub := candidates[order[a]], candidates[order[b]]
+		if ranks[ua.ID] != ranks[ub.ID] {
+			return ranks[ua.ID] < ranks[ub.ID]
+		}
+		if reviewCounts[ua.ID] != reviewCounts[ub.ID] {
+			return reviewCounts[ua.ID] < reviewCounts[ub.ID]
+		}
+		return ua.Username < ub.Username
+	})
+	return order[0]
+}
+
+func (s rankedStrategy) Probabilities(candidates []models.User, reviewCounts map[uint]int) []float64 {
+	probabilities := make([]float64, len(candidates))
+	if len(candidates) > 0 {
+		probabilities[s.Pick(candidates, reviewCounts)] = 1
+	}
+	return probabilities
+}
+
+// roundRobinRanks ranks candidates by when they last got a review in the repository
+// (creation time of the latest MR they review), so members rotate in a fixed order.
+func (c *MRReviewerConsumer) roundRobinRanks(mr *models.MergeRequest) func([]models.User) map[uint]float64 {
+	return func(candidates []models.User) map[uint]float64 {
+		ranks := make(map[uint]float64, len(candidates))
+
+		var rows []struct {
+			UserID          uint
+			GitlabCreatedAt *time.Time
+		}
+		if err := c.db.Table("merge_request_reviewers mrr").
+			Select("mrr.user_id, merge_requests.gitlab_created_at").
+			Joins("JOIN merge_requests ON merge_requests.id = mrr.merge_request_id").
+			Where("merge_requests.repository_id = ? AND merge_requests.id <> ?", mr.RepositoryID, mr.ID).
+			Where("mrr.user_id IN ?", userIDsOf(candidates)).
+			Scan(&rows).Error; err != nil {
+			log.Printf("failed to fetch last reviews for round robin: %v", err)
+			return ranks
+		}
+
+		for _, row := range rows {
+			if row.GitlabCreatedAt == nil {
+				continue
+			}
+			if at := float64(row.GitlabCreatedAt.Unix()); at > ranks[row.UserID] {
+				ranks[row.UserID] = at
+			}
+		}
+		return ranks
+	}
+}
+
+// leastOpenRanks ranks candidates by their open, not yet approved reviews.
+func (c *MRReviewerConsumer) leastOpenRanks(candidates []models.User) map[uint]float64 {
+	ranks := make(map[uint]float64, len(candidates))
+	openCounts, _, err := utils.GetOpenReviewStats(c.db, userIDsOf(candidates))
+	if err != nil {
+		log.Printf("failed to fetch open reviews for least open strategy: %v", err)
+	}
+	for userID, count := range openCounts {
+		ranks[userID] = float64(count)
+	}
+	return ranks
+}
+
+// expertiseRanks ranks candidates by prior reviews in the repository of MRs sharing
+// a label or a changed file with this MR; more matching reviews rank higher.
+func (c *MRReviewerConsumer) expertiseRanks(mr *models.MergeRequest, changedPaths []string) func([]models.User) map[uint]float64 {
+	labelNames := make([]string, len(mr.Labels))
+	for i, label := range mr.Labels {
+		labelNames[i] = label.Name
+	}
+
+	return func(candidates []models.User) map[uint]float64 {
+		ranks := make(map[uint]float64, len(candidates))
+		userIDs := userIDsOf(candidates)
+
+		priorReviews := func() *gorm.DB {
+			return c.db.Table("merge_request_reviewers mrr").
+				Select("mrr.user_id, COUNT(DISTINCT mrr.merge_request_id) as count").
+				Joins("JOIN merge_requests ON merge_requests.id = mrr.merge_request_id").
+				Where("merge_requests.repository_id = ? AND merge_requests.id <> ?", mr.RepositoryID, mr.ID).
+				Where("mrr.user_id IN ?", userIDs).
+				Group("mrr.user_id")
+		}
+
+		var counts []struct {
+			UserID uint
+			Count  int
+		}
+		if len(labelNames) > 0 {
+			if err := priorReviews().
+				Joins("JOIN merge_request_labels mrl ON mrl.merge_request_id = mrr.merge_request_id").
+				Joins("JOIN labels ON labels.id = mrl.label_id").
+				Where("labels.name IN ?", labelNames).
+				Scan(&counts).Error; err != nil {
+				log.Printf("failed to fetch label expertise: %v", err)
+			}
+			for _, rc := range counts {
+				ranks[rc.UserID] -= float64(rc.Count)
+			}
+		}
+
+		if len(changedPaths) > 0 {
+			counts = nil
+			if err := priorReviews().
+				Where("EXISTS (SELECT 1 FROM merge_request_files mrf WHERE mrf.merge_request_id = mrr.merge_request_id AND mrf.path IN ? AND mrf.deleted_at IS NULL)", changedPaths).
+				Scan(&counts).Error; err != nil {
+				log.Printf("failed to fetch file expertise: %v", err)
+			}
+			for _, rc := range counts {
+				ranks[rc.UserID] -= float64(rc.Count)
+			}
+		}
+		return ranks
+	}
+}
+
+func userIDsOf(users []models.User) []uint {
+	ids := make([]uint, len(users))
+	for i, u := range users {
+		ids[i] = u.ID
+	}
+	return ids
+}
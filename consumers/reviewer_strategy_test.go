@@ -0,0 +1,146 @@
+package consumers
+
+import (
+	"testing"
+	"time"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+func setReviewerStrategy(t *testing.T, consumer *MRReviewerConsumer, repoID uint, strategy string) {
+	t.Helper()
+	if err := consumer.db.Create(&models.RepositorySLA{RepositoryID: repoID, AssignCount: 1, ReviewerStrategy: strategy}).Error; err != nil {
+		t.Fatalf("failed to set strategy: %v", err)
+	}
+}
+
+// TestRoundRobinStrategy_RotatesByLastReview tests that the member who reviewed least recently is picked.
+func TestRoundRobinStrategy_RotatesByLastReview(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+	mrFactory := testutils.NewMergeRequestFactory(db)
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	alice := userFactory.Create(testutils.WithUsername("alice"))
+	bob := userFactory.Create(testutils.WithUsername("bob"))
+	carol := userFactory.Create(testutils.WithUsername("carol"))
+	for _, u := range []models.User{alice, bob, carol} {
+		testutils.CreatePossibleReviewer(db, repo, u)
+	}
+
+	older := mrFactory.Create(repo, author, testutils.WithCreatedAt(time.Now().Add(-48*time.Hour)))
+	testutils.AssignReviewers(db, &older, bob)
+	newer := mrFactory.Create(repo, author, testutils.WithCreatedAt(time.Now().Add(-24*time.Hour)))
+	testutils.AssignReviewers(db, &newer, alice)
+
+	created := mrFactory.Create(repo, author)
+	consumer := NewMRReviewerConsumerWithServices(db, nil, nil, nil, 0, nil)
+	setReviewerStrategy(t, consumer, repo.ID, StrategyRoundRobin)
+	mr := loadMRForReassign(t, consumer, created.ID)
+
+	selected, trace := consumer.selectReviewersWithTrace(&mr, 2, nil)
+
+	if len(selected) != 2 || selected[0].ID != carol.ID || selected[1].ID != bob.ID {
+		t.Fatalf("expected carol then bob, got %v", selected)
+	}
+	if trace.Strategy != StrategyRoundRobin {
+		t.Errorf("expected strategy traced, got %q", trace.Strategy)
+	}
+	for _, cand := range trace.Steps[0].Candidates {
+		want := 0.0
+		if cand.Username == "carol" {
+			want = 1
+		}
+		if cand.Probability != want {
+			t.Errorf("expected %s probability %v, got %v", cand.Username, want, cand.Probability)
+		}
+	}
+}
+
+// TestLeastOpenStrategy_PicksFewestOpenReviews tests that open, not yet approved reviews decide the pick.
+func TestLeastOpenStrategy_PicksFewestOpenReviews(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+	mrFactory := testutils.NewMergeRequestFactory(db)
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	alice := userFactory.Create(testutils.WithUsername("alice"))
+	bob := userFactory.Create(testutils.WithUsername("bob"))
+	testutils.CreatePossibleReviewer(db, repo, alice)
+	testutils.CreatePossibleReviewer(db, repo, bob)
+
+	open := mrFactory.Create(repo, author)
+	testutils.AssignReviewers(db, &open, alice)
+	approved := mrFactory.Create(repo, author)
+	testutils.AssignReviewers(db, &approved, bob)
+	testutils.AssignApprovers(db, &approved, bob)
+	approved2 := mrFactory.Create(repo, author)
+	testutils.AssignReviewers(db, &approved2, bob)
+	testutils.AssignApprovers(db, &approved2, bob)
+
+	created := mrFactory.Create(repo, author)
+	consumer := NewMRReviewerConsumerWithServices(db, nil, nil, nil, 0, nil)
+	setReviewerStrategy(t, consumer, repo.ID, StrategyLeastOpen)
+	mr := loadMRForReassign(t, consumer, created.ID)
+
+	selected := consumer.selectReviewers(&mr, 1, nil)
+	if len(selected) != 1 || selected[0].ID != bob.ID {
+		t.Errorf("expected bob with no open reviews, got %v", selected)
+	}
+}
+
+// TestExpertiseStrategy_PrefersPriorReviewsOfSameLabelsAndFiles tests label and file matching without writing changed files.
+func TestExpertiseStrategy_PrefersPriorReviewsOfSameLabelsAndFiles(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+	mrFactory := testutils.NewMergeRequestFactory(db)
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	alice := userFactory.Create(testutils.WithUsername("alice"))
+	bob := userFactory.Create(testutils.WithUsername("bob"))
+	carol := userFactory.Create(testutils.WithUsername("carol"))
+	for _, u := range []models.User{alice, bob, carol} {
+		testutils.CreatePossibleReviewer(db, repo, u)
+	}
+
+	labelled := mrFactory.Create(repo, author, testutils.WithLabels(db, "payments"))
+	testutils.AssignReviewers(db, &labelled, alice)
+	for i := 0; i < 2; i++ {
+		touched := mrFactory.Create(repo, author)
+		testutils.AssignReviewers(db, &touched, bob)
+		db.Create(&models.MergeRequestFile{MergeRequestID: touched.ID, Path: "billing/invoice.go"})
+	}
+
+	created := mrFactory.Create(repo, author, testutils.WithLabels(db, "payments"))
+	mrService, filesService := newCodeOwnersMocks("", "billing/invoice.go")
+	consumer := NewMRReviewerConsumerWithServices(db, nil, mrService, filesService, 0, nil)
+	setReviewerStrategy(t, consumer, repo.ID, StrategyExpertise)
+	mr := loadMRForReassign(t, consumer, created.ID)
+
+	selected := consumer.selectReviewers(&mr, 2, nil)
+	if len(selected) != 2 || selected[0].ID != bob.ID || selected[1].ID != alice.ID {
+		t.Fatalf("expected bob then alice by expertise, got %v", selected)
+	}
+
+	var count int64
+	db.Model(&models.MergeRequestFile{}).Where("merge_request_id = ?", mr.ID).Count(&count)
+	if count != 0 {
+		t.Errorf("expected selection not to record changed files, got %d", count)
+	}
+}
+
+// TestIsValidReviewerStrategy tests strategy name validation.
+func TestIsValidReviewerStrategy(t *testing.T) {
+	for _, name := range ReviewerStrategies {
+		if !IsValidReviewerStrategy(name) {
+			t.Errorf("expected %s to be valid", name)
+		}
+	}
+	if IsValidReviewerStrategy("random") {
+		t.Error("expected unknown strategy to be invalid")
+	}
+}
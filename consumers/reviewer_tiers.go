@@ -0,0 +1,106 @@
+package consumers
+
+import (
+	"log"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/utils"
+)
+
+// getTierRequirement returns the reviewer tier a repository requires on every MR and how many
+// reviewers from it are needed. An empty tier means no requirement.
+func (c *MRReviewerConsumer) getTierRequirement(repoID uint) (string, int) {
+	sla, err := utils.GetRepositorySLA(c.db, repoID)
+	if err != nil || sla.RequiredTier == "" || sla.RequiredTierCount <= 0 {
+		return "", 0
+	}
+	return sla.RequiredTier, sla.RequiredTierCount
+}
+
+// tierMemberIDs returns users tagged with tier in the default or any label pool of the repository.
+func (c *MRReviewerConsumer) tierMemberIDs(repoID uint, tier string) map[uint]bool {
+	var ids []uint
+	if err := c.db.Model(&models.PossibleReviewer{}).
+		Where("repository_id = ? AND tier = ?", repoID, tier).
+		Pluck("user_id", &ids).Error; err != nil {
+		log.Printf("failed to fetch %s tier reviewers: %v", tier, err)
+	}
+	var labelIDs []uint
+	if err := c.db.Model(&models.LabelReviewer{}).
+		Where("repository_id = ? AND tier = ?", repoID, tier).
+		Pluck("user_id", &labelIDs).Error; err != nil {
+		log.Printf("failed to fetch %s tier label reviewers: %v", tier, err)
+	}
+
+	members := make(map[uint]bool, len(ids)+len(labelIDs))
+	for _, id := range append(ids, labelIDs...) {
+		members[id] = true
+	}
+	return members
+}
+
+// missingTierReviewers returns how many more reviewers from the required tier an MR with
+// the given reviewers needs.
+func (c *MRReviewerConsumer) missingTierReviewers(repoID uint, reviewers []models.User) int {
+	tier, required := c.getTierRequirement(repoID)
+	if tier == "" {
+		return 0
+	}
+	members := c.tierMemberIDs(repoID, tier)
+	for _, r := range reviewers {
+		if members[r.ID] {
+			required--
+		}
+	}
+	if required < 0 {
+		return 0
+	}
+	return required
+}
+
+// getTierReviewers returns eligible users of a tier: not the author, not excluded, not on vacation.
+func (c *MRReviewerConsumer) getTierReviewers(mr *models.MergeRequest, tier string, excludeUsers []models.User) []models.User {
+	members := c.tierMemberIDs(mr.RepositoryID, tier)
+	if len(members) == 0 {
+		return nil
+	}
+	userIDs := make([]uint, 0, len(members))
+	for id := range members {
+		userIDs = append(userIDs, id)
+	}
+
+	excludeIDs := []uint{mr.AuthorID}
+	for _, u := range excludeUsers {
+		excludeIDs = append(excludeIDs, u.ID)
+	}
+
+	var users []models.User
+	if err := c.db.Scopes(utils.ActiveRepositoryUsers(mr.RepositoryID)).
+		Where("id IN ? AND id NOT IN ? AND on_vacation = ?", userIDs, excludeIDs, false).
+		Order("username").
+		Find(&users).Error; err != nil {
+		log.Printf("failed to fetch %s tier users: %v", tier, err)
+		return nil
+	}
+	return users
+}
+
+// selectRequiredTier picks the reviewers still missing from the repository's required tier
+// and returns them with the number of slots reserved for the tier.
+// existing are the MR's current reviewers; excludeUsers are never picked.
+func (c *MRReviewerConsumer) selectRequiredTier(mr *models.MergeRequest, existing, excludeUsers []models.User, strategy ReviewerStrategy, trace *selectionTrace) ([]models.User, int) {
+	missing := c.missingTierReviewers(mr.RepositoryID, existing)
+	if missing == 0 {
+		return nil, 0
+	}
+	tier, _ := c.getTierRequirement(mr.RepositoryID)
+
+	pool := c.getTierReviewers(mr, tier, excludeUsers)
+	reviewCounts := c.getReviewCountsForUserIDs(userIDsOf(pool))
+	picked := c.pickMultipleWith(strategy, pool, missing, reviewCounts)
+	trace.addStep(strategy, "tier "+tier, pool, reviewCounts, picked)
+	if len(picked) < missing {
+		log.Printf("MR %d needs %d %s reviewer(s), only %d available", mr.ID, missing, tier, len(picked))
+	}
+	return picked, missing
+}
@@ -0,0 +1,142 @@
+package consumers
+
+import (
+	"testing"
+
+	"devstreamlinebot/mocks"
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+func setTierRequirement(t *testing.T, consumer *MRReviewerConsumer, repoID uint, assignCount int, tier string, count int) {
+	t.Helper()
+	if err := consumer.db.Create(&models.RepositorySLA{
+		RepositoryID:      repoID,
+		AssignCount:       assignCount,
+		RequiredTier:      tier,
+		RequiredTierCount: count,
+	}).Error; err != nil {
+		t.Fatalf("failed to set tier requirement: %v", err)
+	}
+}
+
+// TestSelectReviewers_RequiredTier tests that every selection includes a reviewer of the required tier.
+func TestSelectReviewers_RequiredTier(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	senior := userFactory.Create(testutils.WithUsername("senior"))
+	for _, name := range []string{"bob", "carol", "dave"} {
+		testutils.CreatePossibleReviewer(db, repo, userFactory.Create(testutils.WithUsername(name)))
+	}
+	db.Create(&models.PossibleReviewer{RepositoryID: repo.ID, UserID: senior.ID, Tier: "senior"})
+
+	created := testutils.NewMergeRequestFactory(db).Create(repo, author)
+	consumer := NewMRReviewerConsumerWithServices(db, nil, nil, nil, 0, nil)
+	setTierRequirement(t, consumer, repo.ID, 2, "senior", 1)
+	mr := loadMRForReassign(t, consumer, created.ID)
+
+	for i := 0; i < 20; i++ {
+		selected, trace := consumer.selectReviewersWithTrace(&mr, 2, nil)
+		if len(selected) != 2 || selected[0].ID != senior.ID || selected[1].ID == senior.ID {
+			t.Fatalf("expected senior plus one other reviewer, got %v", selected)
+		}
+		if trace.Steps[0].Pool != "tier senior" {
+			t.Fatalf("expected tier step first, got %q", trace.Steps[0].Pool)
+		}
+	}
+
+	db.Model(&senior).Update("on_vacation", true)
+	selected := consumer.selectReviewers(&mr, 2, nil)
+	if len(selected) != 1 {
+		t.Errorf("expected the tier slot to stay empty without an available senior, got %v", selected)
+	}
+}
+
+// TestAssignReviewers_BackfillsRequiredTier tests that an MR with enough reviewers but none of the tier gets one added.
+func TestAssignReviewers_BackfillsRequiredTier(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+	mockBot := mocks.NewMockVKBot()
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	chat := testutils.NewChatFactory(db).Create()
+	testutils.CreateSubscription(db, repo, chat, testutils.NewVKUserFactory(db).Create())
+	author := userFactory.Create()
+	bob := userFactory.Create(testutils.WithUsername("bob"))
+	carol := userFactory.Create(testutils.WithUsername("carol"))
+	lead := userFactory.Create(testutils.WithUsername("lead"))
+	testutils.CreatePossibleReviewer(db, repo, bob)
+	testutils.CreatePossibleReviewer(db, repo, carol)
+	testutils.CreateLabelReviewer(db, repo, "backend", bob)
+	db.Create(&models.LabelReviewer{RepositoryID: repo.ID, LabelName: "frontend", UserID: lead.ID, Tier: "maintainer"})
+
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author, testutils.WithLabels(db, "backend"))
+	testutils.AssignReviewers(db, &mr, bob, carol)
+
+	mrService := &mocks.MockMergeRequestsService{}
+	consumer := NewMRReviewerConsumerWithServices(db, mockBot, mrService, nil, 0, nil)
+	setTierRequirement(t, consumer, repo.ID, 2, "maintainer", 1)
+
+	consumer.AssignReviewers()
+
+	reloaded := loadMRForReassign(t, consumer, mr.ID)
+	if len(reloaded.Reviewers) != 3 {
+		t.Fatalf("expected only the maintainer added, got %v", reloaded.Reviewers)
+	}
+	found := false
+	for _, r := range reloaded.Reviewers {
+		found = found || r.ID == lead.ID
+	}
+	if !found {
+		t.Errorf("expected maintainer lead assigned, got %v", reloaded.Reviewers)
+	}
+
+	consumer.AssignReviewers()
+	if len(mrService.UpdateMergeRequestCalls) != 1 {
+		t.Errorf("expected no further assignment once the tier is covered, got %d updates", len(mrService.UpdateMergeRequestCalls))
+	}
+}
+
+// TestSelectReplacement_KeepsRequiredTier tests that replacing the only tier reviewer picks another one of the tier.
+func TestSelectReplacement_KeepsRequiredTier(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	senior := userFactory.Create(testutils.WithUsername("senior"))
+	other := userFactory.Create(testutils.WithUsername("other"))
+	junior := userFactory.Create(testutils.WithUsername("junior"))
+	testutils.CreatePossibleReviewer(db, repo, junior)
+	db.Create(&models.PossibleReviewer{RepositoryID: repo.ID, UserID: senior.ID, Tier: "senior"})
+	db.Create(&models.PossibleReviewer{RepositoryID: repo.ID, UserID: other.ID, Tier: "senior"})
+
+	created := testutils.NewMergeRequestFactory(db).Create(repo, author)
+	testutils.AssignReviewers(db, &created, senior)
+	consumer := NewMRReviewerConsumerWithServices(db, nil, nil, nil, 0, nil)
+	setTierRequirement(t, consumer, repo.ID, 1, "senior", 1)
+	mr := loadMRForReassign(t, consumer, created.ID)
+
+	for i := 0; i < 10; i++ {
+		picked, _ := consumer.selectReplacement(&mr, senior)
+		if picked == nil || picked.ID != other.ID {
+			t.Fatalf("expected the other senior as replacement, got %v", picked)
+		}
+	}
+}
+
+// TestParseReviewerEntry tests username and tier parsing of reviewer lists.
+func TestParseReviewerEntry(t *testing.T) {
+	if u, tier := parseReviewerEntry(" alice:Senior "); u != "alice" || tier != "senior" {
+		t.Errorf("unexpected parse: %q %q", u, tier)
+	}
+	if u, tier := parseReviewerEntry("bob"); u != "bob" || tier != "" {
+		t.Errorf("unexpected parse: %q %q", u, tier)
+	}
+	if isValidTierName("senior dev") || !isValidTierName("tech-lead") {
+		t.Error("unexpected tier name validation")
+	}
+}
@@ -0,0 +1,208 @@
+package consumers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/utils"
+)
+
+// Exclusion reasons recorded in selection traces.
+const (
+	exclusionAuthor           = "author"
+	exclusionExistingReviewer = "already reviewer"
+	exclusionVacation         = "vacation"
+	exclusionCapacity         = "at capacity"
+)
+
+// selectionTrace explains a single reviewer selection round.
+// All methods are nil-safe so selection code can run without tracing.
+type selectionTrace struct {
+	Needed      int                 `json:"needed"`
+	Backfill    bool                `json:"backfill,omitempty"`
+	Strategy    string              `json:"strategy,omitempty"`
+	Excluded    []traceExclusion    `json:"excluded,omitempty"`
+	LabelGroups map[string][]string `json:"label_groups,omitempty"`
+	CodeOwners  map[string][]string `json:"codeowners,omitempty"`
+	PathGroups  map[string][]string `json:"path_groups,omitempty"`
+	Steps       []traceStep         `json:"steps,omitempty"`
+}
+
+type traceExclusion struct {
+	Username string `json:"username"`
+	Reason   string `json:"reason"`
+}
+
+// traceStep records one draw pool with per-candidate probabilities of the first draw.
+type traceStep struct {
+	Pool       string           `json:"pool"`
+	Candidates []traceCandidate `json:"candidates"`
+	Picked     []string         `json:"picked"`
+}
+
+type traceCandidate struct {
+	Username    string  `json:"username"`
+	RecentCount int     `json:"recent_count"`
+	Probability float64 `json:"probability"`
+}
+
+func (t *selectionTrace) addExclusion(username, reason string) {
+	if t == nil {
+		return
+	}
+	t.Excluded = append(t.Excluded, traceExclusion{Username: username, Reason: reason})
+}
+
+func (t *selectionTrace) setLabelGroups(groups map[string][]models.User) {
+	if t == nil || len(groups) == 0 {
+		return
+	}
+	t.LabelGroups = groupUsernames(groups)
+}
+
+func (t *selectionTrace) setCodeOwners(groups map[string][]models.User) {
+	if t == nil || len(groups) == 0 {
+		return
+	}
+	t.CodeOwners = groupUsernames(groups)
+}
+
+func (t *selectionTrace) setPathGroups(groups map[string][]models.User) {
+	if t == nil || len(groups) == 0 {
+		return
+	}
+	t.PathGroups = groupUsernames(groups)
+}
+
+func groupUsernames(groups map[string][]models.User) map[string][]string {
+	result := make(map[string][]string, len(groups))
+	for name, users := range groups {
+		names := make([]string, len(users))
+		for i, u := range users {
+			names[i] = u.Username
+		}
+		result[name] = names
+	}
+	return result
+}
+
+// addStep records a draw; probabilities come from strategy (weighted when nil).
+func (t *selectionTrace) addStep(strategy ReviewerStrategy, pool string, candidates []models.User, reviewCounts map[uint]int, picked []models.User) {
+	if t == nil {
+		return
+	}
+	if strategy == nil {
+		strategy = weightedStrategy{}
+	}
+	probabilities := strategy.Probabilities(candidates, reviewCounts)
+	step := traceStep{Pool: pool}
+	for i, u := range candidates {
+		step.Candidates = append(step.Candidates, traceCandidate{
+			Username:    u.Username,
+			RecentCount: reviewCounts[u.ID],
+			Probability: probabilities[i],
+		})
+	}
+	for _, u := range picked {
+		step.Picked = append(step.Picked, u.Username)
+	}
+	t.Steps = append(t.Steps, step)
+}
+
+// selectionProbabilities returns the chance of each user being picked in a single weighted draw.
+func selectionProbabilities(users []models.User, reviewCounts map[uint]int) []float64 {
+	probabilities := make([]float64, len(users))
+	total := 0.0
+	for i, u := range users {
+		probabilities[i] = utils.SelectionWeight(reviewCounts[u.ID])
+		total += probabilities[i]
+	}
+	if total <= 0 {
+		return probabilities
+	}
+	for i := range probabilities {
+		probabilities[i] /= total
+	}
+	return probabilities
+}
+
+// formatSelectionTrace renders a stored trace for chat output.
+func formatSelectionTrace(trace *selectionTrace, createdAt time.Time) string {
+	var sb strings.Builder
+
+	kind := "initial"
+	if trace.Backfill {
+		kind = "backfill"
+	}
+	sb.WriteString(fmt.Sprintf("[%s] %s, needed %d\n", createdAt.Format("2006-01-02 15:04"), kind, trace.Needed))
+
+	if trace.Strategy != "" && trace.Strategy != StrategyWeighted {
+		sb.WriteString("Strategy: " + trace.Strategy + "\n")
+	}
+
+	if len(trace.Excluded) > 0 {
+		parts := make([]string, len(trace.Excluded))
+		for i, e := range trace.Excluded {
+			parts[i] = fmt.Sprintf("%s (%s)", e.Username, e.Reason)
+		}
+		sb.WriteString("Excluded: " + strings.Join(parts, ", ") + "\n")
+	}
+
+	if len(trace.CodeOwners) > 0 {
+		sb.WriteString("CODEOWNERS: " + formatTraceGroups(trace.CodeOwners) + "\n")
+	}
+
+	if len(trace.PathGroups) > 0 {
+		sb.WriteString("Path groups: " + formatTraceGroups(trace.PathGroups) + "\n")
+	}
+
+	if len(trace.LabelGroups) > 0 {
+		sb.WriteString("Label groups: " + formatTraceGroups(trace.LabelGroups) + "\n")
+	} else {
+		sb.WriteString("Label groups: none matched\n")
+	}
+
+	for _, step := range trace.Steps {
+		parts := make([]string, len(step.Candidates))
+		for i, cand := range step.Candidates {
+			parts[i] = fmt.Sprintf("%s %.0f%% (recent %d)", cand.Username, cand.Probability*100, cand.RecentCount)
+		}
+		candidates := strings.Join(parts, ", ")
+		if candidates == "" {
+			candidates = "no available candidates"
+		}
+		picked := "nobody"
+		if len(step.Picked) > 0 {
+			picked = strings.Join(step.Picked, ", ")
+		}
+		sb.WriteString(fmt.Sprintf("%s: %s → %s\n", step.Pool, candidates, picked))
+	}
+
+	return sb.String()
+}
+
+func formatTraceGroups(groups map[string][]string) string {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s [%s]", name, strings.Join(groups[name], ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// decodeSelectionTrace parses a stored trace JSON.
+func decodeSelectionTrace(data string) (*selectionTrace, error) {
+	var trace selectionTrace
+	if err := json.Unmarshal([]byte(data), &trace); err != nil {
+		return nil, err
+	}
+	return &trace, nil
+}
@@ -0,0 +1,162 @@
+package consumers
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+// TestSelectReviewersWithTrace_RecordsExclusions tests that author, existing reviewers and vacationers are traced.
+func TestSelectReviewersWithTrace_RecordsExclusions(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repoFactory := testutils.NewRepositoryFactory(db)
+	userFactory := testutils.NewUserFactory(db)
+	mrFactory := testutils.NewMergeRequestFactory(db)
+
+	repo := repoFactory.Create()
+	author := userFactory.Create(testutils.WithUsername("author"))
+	existing := userFactory.Create(testutils.WithUsername("existing"))
+	away := userFactory.Create(testutils.WithUsername("away"), testutils.WithOnVacation())
+	free := userFactory.Create(testutils.WithUsername("free"))
+
+	for _, u := range []models.User{author, existing, away, free} {
+		testutils.CreatePossibleReviewer(db, repo, u)
+	}
+
+	mr := mrFactory.Create(repo, author)
+
+	consumer := NewMRReviewerConsumer(db, nil, nil, 0, nil)
+	selected, trace := consumer.selectReviewersWithTrace(&mr, 1, []models.User{existing})
+
+	if len(selected) != 1 || selected[0].ID != free.ID {
+		t.Fatalf("expected only 'free' to be selected, got %v", selected)
+	}
+
+	reasons := make(map[string]string)
+	for _, e := range trace.Excluded {
+		reasons[e.Username] = e.Reason
+	}
+	if reasons["author"] != exclusionAuthor {
+		t.Errorf("expected author exclusion, got %q", reasons["author"])
+	}
+	if reasons["existing"] != exclusionExistingReviewer {
+		t.Errorf("expected existing reviewer exclusion, got %q", reasons["existing"])
+	}
+	if reasons["away"] != exclusionVacation {
+		t.Errorf("expected vacation exclusion, got %q", reasons["away"])
+	}
+	if _, ok := reasons["free"]; ok {
+		t.Error("eligible user should not be excluded")
+	}
+
+	if len(trace.Steps) != 1 || trace.Steps[0].Pool != "default" {
+		t.Fatalf("expected a single default step, got %+v", trace.Steps)
+	}
+	if len(trace.Steps[0].Picked) != 1 || trace.Steps[0].Picked[0] != "free" {
+		t.Errorf("expected 'free' picked in trace, got %v", trace.Steps[0].Picked)
+	}
+}
+
+// TestSelectReviewersWithTrace_RecordsLabelGroups tests that matched label groups and per-label steps are traced.
+func TestSelectReviewersWithTrace_RecordsLabelGroups(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repoFactory := testutils.NewRepositoryFactory(db)
+	userFactory := testutils.NewUserFactory(db)
+	mrFactory := testutils.NewMergeRequestFactory(db)
+
+	repo := repoFactory.Create()
+	author := userFactory.Create()
+	backend := userFactory.Create(testutils.WithUsername("backend-dev"))
+	fallback := userFactory.Create(testutils.WithUsername("fallback"))
+
+	testutils.CreateLabelReviewer(db, repo, "backend", backend)
+	testutils.CreatePossibleReviewer(db, repo, fallback)
+
+	mr := mrFactory.Create(repo, author, testutils.WithLabels(db, "backend"))
+
+	consumer := NewMRReviewerConsumer(db, nil, nil, 0, nil)
+	selected, trace := consumer.selectReviewersWithTrace(&mr, 2, nil)
+
+	if len(selected) != 2 {
+		t.Fatalf("expected 2 reviewers, got %d", len(selected))
+	}
+	if names := trace.LabelGroups["backend"]; len(names) != 1 || names[0] != "backend-dev" {
+		t.Errorf("expected backend label group with backend-dev, got %v", names)
+	}
+	if len(trace.Steps) != 2 {
+		t.Fatalf("expected label and fill steps, got %d", len(trace.Steps))
+	}
+	if trace.Steps[0].Pool != "label backend" || trace.Steps[1].Pool != "fill" {
+		t.Errorf("unexpected step pools: %s, %s", trace.Steps[0].Pool, trace.Steps[1].Pool)
+	}
+}
+
+// TestSelectionProbabilities_FavorsLessLoaded tests normalized single-draw probabilities.
+func TestSelectionProbabilities_FavorsLessLoaded(t *testing.T) {
+	users := []models.User{{}, {}}
+	users[0].ID = 1
+	users[1].ID = 2
+
+	probabilities := selectionProbabilities(users, map[uint]int{1: 0, 2: 1})
+
+	if math.Abs(probabilities[0]-2.0/3.0) > 1e-9 || math.Abs(probabilities[1]-1.0/3.0) > 1e-9 {
+		t.Errorf("expected 2/3 and 1/3, got %v", probabilities)
+	}
+}
+
+// TestFormatSelectionTrace_RendersAllSections tests chat rendering of a decoded trace.
+func TestFormatSelectionTrace_RendersAllSections(t *testing.T) {
+	trace := &selectionTrace{
+		Needed:      1,
+		Backfill:    true,
+		Excluded:    []traceExclusion{{Username: "alice", Reason: exclusionAuthor}},
+		LabelGroups: map[string][]string{"backend": {"bob"}},
+		CodeOwners:  map[string][]string{"API": {"dave"}},
+		Steps: []traceStep{
+			{Pool: "label backend", Candidates: []traceCandidate{{Username: "bob", RecentCount: 2, Probability: 1}}, Picked: []string{"bob"}},
+			{Pool: "label frontend"},
+		},
+	}
+
+	text := formatSelectionTrace(trace, time.Date(2025, 1, 2, 10, 30, 0, 0, time.UTC))
+
+	for _, want := range []string{
+		"[2025-01-02 10:30] backfill, needed 1",
+		"Excluded: alice (author)",
+		"CODEOWNERS: API [dave]",
+		"Label groups: backend [bob]",
+		"label backend: bob 100% (recent 2) → bob",
+		"label frontend: no available candidates → nobody",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output:\n%s", want, text)
+		}
+	}
+}
+
+// TestSaveSelectionTrace_RoundTrip tests that the trace round-trips through the database.
+func TestSaveSelectionTrace_RoundTrip(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := testutils.NewUserFactory(db).Create()
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author)
+
+	consumer := NewMRReviewerConsumer(db, nil, nil, 0, nil)
+	consumer.saveSelectionTrace(mr.ID, &selectionTrace{Needed: 2, LabelGroups: map[string][]string{"qa": {"carol"}}})
+
+	var stored models.ReviewerSelectionTrace
+	if err := db.Where("merge_request_id = ?", mr.ID).First(&stored).Error; err != nil {
+		t.Fatalf("expected stored trace: %v", err)
+	}
+	decoded, err := decodeSelectionTrace(stored.Trace)
+	if err != nil {
+		t.Fatalf("failed to decode trace: %v", err)
+	}
+	if decoded.Needed != 2 || decoded.LabelGroups["qa"][0] != "carol" {
+		t.Errorf("unexpected decoded trace: %+v", decoded)
+	}
+}
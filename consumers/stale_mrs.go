@@ -0,0 +1,218 @@
+package consumers
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	botgolang "github.com/mail-ru-im/bot-golang"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"gorm.io/gorm"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/utils"
+)
+
+// ProcessStaleMRs marks open MRs of repositories with /stale enabled as stale once they have been
+// idle for the configured working days: the author gets a DM and, with /stale label on, the MR
+// gets the stale label, which is removed again on new activity. Stale drafts are closed once
+// CloseDraftsAfterDays working days have passed since the DM.
+func (c *MRReviewerConsumer) ProcessStaleMRs() {
+	var configs []models.StaleConfig
+	if err := c.db.Find(&configs).Error; err != nil {
+		log.Printf("failed to fetch stale configs: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for i := range configs {
+		cfg := &configs[i]
+		var mrs []models.MergeRequest
+		if err := c.db.
+			Preload("Repository").Preload("Author").Preload("Labels").
+			Where("merge_requests.state = ? AND merge_requests.merged_at IS NULL", "opened").
+			Where("repository_id = ?", cfg.RepositoryID).
+			Find(&mrs).Error; err != nil {
+			log.Printf("failed to fetch merge requests for stale detection: %v", err)
+			continue
+		}
+
+		for j := range mrs {
+			mr := &mrs[j]
+			if utils.HasReleaseLabel(c.db, mr) || utils.IsMRBlocked(c.db, mr) {
+				continue
+			}
+
+			markedSince := utils.StaleMarkedSince(c.db, mr)
+			if markedSince == nil {
+				idle := utils.CalculateWorkingTime(c.db, mr.RepositoryID, utils.LastActivityTime(c.db, mr), now)
+				if idle >= utils.StaleThreshold(cfg) {
+					c.markMRStale(cfg, mr, idle, now)
+				} else if cfg.ApplyLabel && hasLocalLabel(mr, utils.StaleLabel) {
+					c.setStaleLabel(mr, false)
+				}
+				continue
+			}
+
+			if mr.Draft && cfg.CloseDraftsAfterDays > 0 &&
+				utils.CalculateWorkingTime(c.db, mr.RepositoryID, *markedSince, now) >= time.Duration(cfg.CloseDraftsAfterDays)*24*time.Hour {
+				c.closeAbandonedDraft(mr)
+			}
+		}
+	}
+}
+
+// markMRStale records the MR as stale, DMs its author and adds the stale label if enabled.
+func (c *MRReviewerConsumer) markMRStale(cfg *models.StaleConfig, mr *models.MergeRequest, idle time.Duration, now time.Time) {
+	if err := c.db.Create(&models.MRAction{
+		MergeRequestID: mr.ID,
+		ActionType:     models.ActionStaleDetected,
+		Timestamp:      now,
+		Metadata:       fmt.Sprintf(`{"idle":%q}`, utils.FormatDuration(idle)),
+		Notified:       true,
+	}).Error; err != nil {
+		log.Printf("failed to record stale MR %d: %v", mr.ID, err)
+		return
+	}
+
+	text := fmt.Sprintf(
+		"💤 Your MR has had no activity for %s [%s]:\n%s\n%s\nPush, comment or close it if it is no longer needed.",
+		utils.FormatDuration(idle), mr.Repository.Name, mr.Title, mr.WebURL,
+	)
+	if mr.Draft && cfg.CloseDraftsAfterDays > 0 {
+		text += fmt.Sprintf("\nThis draft will be closed in %d working days without activity.", cfg.CloseDraftsAfterDays)
+	}
+	c.notifyUserDM(&mr.Author, utils.NotifyStale, text)
+
+	if cfg.ApplyLabel && !hasLocalLabel(mr, utils.StaleLabel) {
+		c.setStaleLabel(mr, true)
+	}
+	log.Printf("Marked MR %d stale after %s without activity", mr.ID, utils.FormatDuration(idle))
+}
+
+// setStaleLabel adds or removes the stale label in GitLab and locally.
+func (c *MRReviewerConsumer) setStaleLabel(mr *models.MergeRequest, add bool) {
+	labels := gitlab.LabelOptions{utils.StaleLabel}
+	opts := &gitlab.UpdateMergeRequestOptions{}
+	if add {
+		opts.AddLabels = &labels
+	} else {
+		opts.RemoveLabels = &labels
+	}
+	if _, _, err := c.mrService.UpdateMergeRequest(mr.Repository.GitlabID, mr.IID, opts); err != nil {
+		log.Printf("failed to update stale label of MR %d: %v", mr.ID, err)
+		return
+	}
+
+	var label models.Label
+	if err := c.db.Where(models.Label{Name: utils.StaleLabel}).FirstOrCreate(&label).Error; err != nil {
+		log.Printf("failed to upsert label %s: %v", utils.StaleLabel, err)
+		return
+	}
+	if add {
+		if err := c.db.Model(mr).Association("Labels").Append(&label); err != nil {
+			log.Printf("failed to add local stale label to MR %d: %v", mr.ID, err)
+		}
+	} else if err := c.db.Model(mr).Association("Labels").Delete(&label); err != nil {
+		log.Printf("failed to remove local stale label of MR %d: %v", mr.ID, err)
+	}
+}
+
+// closeAbandonedDraft closes a stale draft in GitLab and tells its author.
+func (c *MRReviewerConsumer) closeAbandonedDraft(mr *models.MergeRequest) {
+	if _, _, err := c.mrService.UpdateMergeRequest(mr.Repository.GitlabID, mr.IID,
+		&gitlab.UpdateMergeRequestOptions{StateEvent: gitlab.Ptr("close")}); err != nil {
+		log.Printf("failed to close abandoned draft MR %d: %v", mr.ID, err)
+		return
+	}
+	if err := c.db.Model(mr).UpdateColumn("state", "closed").Error; err != nil {
+		log.Printf("failed to mark MR %d closed: %v", mr.ID, err)
+	}
+	c.notifyUserDM(&mr.Author, utils.NotifyStale, fmt.Sprintf(
+		"🗑 Your abandoned draft was closed [%s]:\n%s\n%s\nReopen it in GitLab if you still need it.",
+		mr.Repository.Name, mr.Title, mr.WebURL,
+	))
+	log.Printf("Closed abandoned draft MR %d", mr.ID)
+}
+
+// hasLocalLabel reports whether the MR carries the label according to the synced labels.
+func hasLocalLabel(mr *models.MergeRequest, name string) bool {
+	for _, l := range mr.Labels {
+		if l.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// StaleReportConsumer posts the weekly list of stale MRs to every subscribed chat.
+// It runs at 10:00 every Monday.
+type StaleReportConsumer struct {
+	db    *gorm.DB
+	vkBot *botgolang.Bot
+}
+
+// NewStaleReportConsumer initializes a StaleReportConsumer.
+func NewStaleReportConsumer(db *gorm.DB, vkBot *botgolang.Bot) *StaleReportConsumer {
+	return &StaleReportConsumer{db: db, vkBot: vkBot}
+}
+
+// StartConsumer schedules the stale report at 10:00 on Mondays.
+func (c *StaleReportConsumer) StartConsumer() {
+	go func() {
+		for {
+			now := time.Now()
+			next := time.Date(now.Year(), now.Month(), now.Day(), 10, 0, 0, 0, now.Location())
+			for !next.After(now) || next.Weekday() != time.Monday {
+				next = next.AddDate(0, 0, 1)
+			}
+			time.Sleep(next.Sub(now))
+
+			c.sendReports()
+		}
+	}()
+}
+
+// sendReports posts the stale MRs of each chat's subscribed repositories. Chats without stale MRs get nothing.
+func (c *StaleReportConsumer) sendReports() {
+	var chats []models.Chat
+	if err := c.db.
+		Model(&models.Chat{}).
+		Joins("JOIN repository_subscriptions ON repository_subscriptions.chat_id = chats.id").
+		Joins("JOIN stale_configs ON stale_configs.repository_id = repository_subscriptions.repository_id AND stale_configs.deleted_at IS NULL").
+		Group("chats.id").
+		Find(&chats).Error; err != nil {
+		log.Printf("failed to fetch chats for stale reports: %v", err)
+		return
+	}
+
+	for _, chat := range chats {
+		text, err := buildChatStaleReport(c.db, &chat)
+		if err != nil {
+			log.Printf("failed to build stale report for chat %s: %v", chat.ChatID, err)
+			continue
+		}
+		if text == "" {
+			continue
+		}
+		if err := c.vkBot.NewTextMessage(chat.ChatID, text).Send(); err != nil {
+			log.Printf("failed to send stale report to chat %s: %v", chat.ChatID, err)
+		}
+	}
+}
+
+// buildChatStaleReport renders the stale MRs of the chat's subscribed repositories, or "" if there are none.
+func buildChatStaleReport(db *gorm.DB, chat *models.Chat) (string, error) {
+	var repoIDs []uint
+	if err := db.Model(&models.RepositorySubscription{}).Where("chat_id = ?", chat.ID).Pluck("repository_id", &repoIDs).Error; err != nil {
+		return "", err
+	}
+	staleMRs, err := utils.FindStaleMRs(db, repoIDs)
+	if err != nil {
+		return "", err
+	}
+	if len(staleMRs) == 0 {
+		return "", nil
+	}
+	return utils.BuildStaleReport(db, staleMRs), nil
+}
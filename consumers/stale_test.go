@@ -0,0 +1,96 @@
+package consumers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"devstreamlinebot/mocks"
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+// TestProcessStaleMRs tests that idle MRs are marked stale once with a DM and the stale label,
+// that the label is removed again on new activity, and that abandoned drafts are closed.
+func TestProcessStaleMRs(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	mockBot := mocks.NewMockVKBot()
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := testutils.NewUserFactory(db).Create(testutils.WithEmail("author@example.com"))
+	db.Create(&models.StaleConfig{RepositoryID: repo.ID, AfterDays: 5, ApplyLabel: true})
+
+	longAgo := time.Now().AddDate(0, 0, -30)
+	mrFactory := testutils.NewMergeRequestFactory(db)
+	idle := mrFactory.Create(repo, author, testutils.WithTitle("Idle change"), testutils.WithCreatedAt(longAgo))
+	active := mrFactory.Create(repo, author, testutils.WithTitle("Active change"), testutils.WithCreatedAt(longAgo))
+	testutils.CreateMRAction(db, active, models.ActionCommitsPushed, testutils.WithTimestamp(time.Now()))
+
+	mrService := &mocks.MockMergeRequestsService{}
+	consumer := NewMRReviewerConsumerWithServices(db, mockBot, mrService, nil, 0, nil)
+	consumer.ProcessStaleMRs()
+	consumer.ProcessStaleMRs()
+
+	sent := mockBot.GetSentMessages()
+	if len(sent) != 1 || sent[0].ChatID != "author@example.com" || !strings.Contains(sent[0].Text, "Idle change") {
+		t.Fatalf("expected one stale DM to the author about the idle MR, got %+v", sent)
+	}
+	if len(mrService.UpdateMergeRequestCalls) != 1 || mrService.UpdateMergeRequestCalls[0].Opt.AddLabels == nil {
+		t.Fatalf("expected the stale label added once, got %d updates", len(mrService.UpdateMergeRequestCalls))
+	}
+
+	testutils.CreateMRAction(db, idle, models.ActionCommentAdded, testutils.WithTimestamp(time.Now().Add(time.Minute)))
+	consumer.ProcessStaleMRs()
+	if len(mrService.UpdateMergeRequestCalls) != 2 || mrService.UpdateMergeRequestCalls[1].Opt.RemoveLabels == nil {
+		t.Fatalf("expected the stale label removed after activity, got %d updates", len(mrService.UpdateMergeRequestCalls))
+	}
+
+	draft := mrFactory.Create(repo, author, testutils.WithDraft(), testutils.WithCreatedAt(longAgo))
+	db.Model(&models.StaleConfig{}).Where("repository_id = ?", repo.ID).Updates(map[string]interface{}{"apply_label": false, "close_drafts_after_days": 3})
+	testutils.CreateMRAction(db, draft, models.ActionStaleDetected, testutils.WithTimestamp(longAgo))
+	consumer.ProcessStaleMRs()
+
+	var closed models.MergeRequest
+	db.First(&closed, draft.ID)
+	if closed.State != "closed" {
+		t.Errorf("expected the abandoned draft closed, got state %q", closed.State)
+	}
+	last := mrService.UpdateMergeRequestCalls[len(mrService.UpdateMergeRequestCalls)-1]
+	if last.Opt.StateEvent == nil || *last.Opt.StateEvent != "close" {
+		t.Errorf("expected a close request in GitLab, got %+v", last.Opt)
+	}
+}
+
+// TestBuildChatStaleReport tests that the weekly report lists only stale MRs of the chat's repositories.
+func TestBuildChatStaleReport(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repoFactory := testutils.NewRepositoryFactory(db)
+	repo := repoFactory.Create()
+	other := repoFactory.Create()
+	chat := testutils.NewChatFactory(db).Create()
+	testutils.CreateSubscription(db, repo, chat, testutils.NewVKUserFactory(db).Create())
+	author := testutils.NewUserFactory(db).Create()
+	db.Create(&models.StaleConfig{RepositoryID: repo.ID, AfterDays: 5})
+	db.Create(&models.StaleConfig{RepositoryID: other.ID, AfterDays: 5})
+
+	longAgo := time.Now().AddDate(0, 0, -30)
+	mrFactory := testutils.NewMergeRequestFactory(db)
+	mrFactory.Create(repo, author, testutils.WithTitle("Forgotten change"), testutils.WithCreatedAt(longAgo))
+	mrFactory.Create(repo, author, testutils.WithTitle("Fresh change"))
+	mrFactory.Create(other, author, testutils.WithTitle("Other repo change"), testutils.WithCreatedAt(longAgo))
+
+	report, err := buildChatStaleReport(db, &chat)
+	if err != nil {
+		t.Fatalf("buildChatStaleReport() error: %v", err)
+	}
+	if !containsAll(report, "STALE MRS", "Forgotten change") {
+		t.Errorf("expected the forgotten MR in the report, got:\n%s", report)
+	}
+	if strings.Contains(report, "Fresh change") || strings.Contains(report, "Other repo change") {
+		t.Errorf("expected only stale MRs of subscribed repositories, got:\n%s", report)
+	}
+
+	db.Where("repository_id = ?", repo.ID).Delete(&models.StaleConfig{})
+	if report, _ := buildChatStaleReport(db, &chat); report != "" {
+		t.Errorf("expected no report with stale detection off, got:\n%s", report)
+	}
+}
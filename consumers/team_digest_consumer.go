@@ -0,0 +1,100 @@
+package consumers
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	botgolang "github.com/mail-ru-im/bot-golang"
+	"gorm.io/gorm"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/utils"
+)
+
+// TeamDigestConsumer sends each team's chat a digest of open MRs authored or reviewed by its
+// members at 10:00 on weekdays in the team's timezone.
+type TeamDigestConsumer struct {
+	db    *gorm.DB
+	vkBot *botgolang.Bot
+}
+
+// NewTeamDigestConsumer initializes a TeamDigestConsumer.
+func NewTeamDigestConsumer(db *gorm.DB, vkBot *botgolang.Bot) *TeamDigestConsumer {
+	return &TeamDigestConsumer{db: db, vkBot: vkBot}
+}
+
+// StartConsumer checks every minute whether a team digest is due.
+func (c *TeamDigestConsumer) StartConsumer() {
+	go func() {
+		ticker := time.NewTicker(1 * time.Minute)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			c.sendDueDigests()
+		}
+	}()
+}
+
+func (c *TeamDigestConsumer) sendDueDigests() {
+	var teams []models.Team
+	if err := c.db.Preload("Chat").Preload("Members").Where("chat_id IS NOT NULL").Find(&teams).Error; err != nil {
+		log.Printf("failed to fetch teams: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, team := range teams {
+		if team.Chat == nil || !teamDigestDue(team, now) {
+			continue
+		}
+		if err := c.db.Model(&team).Update("digest_last_sent_at", now).Error; err != nil {
+			log.Printf("failed to update digest time of team %s: %v", team.Name, err)
+			continue
+		}
+
+		text, err := buildTeamDigest(c.db, &team)
+		if err != nil {
+			log.Printf("failed to build digest for team %s: %v", team.Name, err)
+			continue
+		}
+		if text == "" {
+			continue
+		}
+		msg := c.vkBot.NewTextMessage(team.Chat.ChatID, text)
+		if err := msg.Send(); err != nil {
+			log.Printf("failed to send digest of team %s: %v", team.Name, err)
+		}
+	}
+}
+
+// teamDigestDue reports whether it is 10:00 on a weekday in the team's timezone and the digest
+// has not been sent that day yet.
+func teamDigestDue(team models.Team, now time.Time) bool {
+	offset := time.Duration(team.TimezoneOffset) * time.Hour
+	teamTime := now.UTC().Add(offset)
+	if teamTime.Hour() != 10 || teamTime.Weekday() == time.Saturday || teamTime.Weekday() == time.Sunday {
+		return false
+	}
+	if team.DigestLastSentAt == nil {
+		return true
+	}
+	return team.DigestLastSentAt.UTC().Add(offset).Format("2006-01-02") != teamTime.Format("2006-01-02")
+}
+
+// buildTeamDigest renders the digest of open MRs authored or reviewed by team members.
+// Returns "" when there is nothing to report. team.Members must be loaded.
+func buildTeamDigest(db *gorm.DB, team *models.Team) (string, error) {
+	userIDs := make([]uint, len(team.Members))
+	for i, m := range team.Members {
+		userIDs[i] = m.ID
+	}
+	digestMRs, err := utils.FindTeamDigestMRs(db, userIDs)
+	if err != nil {
+		return "", err
+	}
+	if len(digestMRs) == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("TEAM %s\n", team.Name) + utils.BuildEnhancedReviewDigest(db, digestMRs), nil
+}
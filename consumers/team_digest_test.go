@@ -0,0 +1,33 @@
+package consumers
+
+import (
+	"testing"
+	"time"
+
+	"devstreamlinebot/models"
+)
+
+// TestTeamDigestDue tests that the team digest is due once at 10:00 on weekdays in the team's timezone.
+func TestTeamDigestDue(t *testing.T) {
+	team := models.Team{TimezoneOffset: 3}
+	monday := time.Date(2026, 10, 19, 7, 30, 0, 0, time.UTC) // 10:30 UTC+3
+	if !teamDigestDue(team, monday) {
+		t.Error("expected digest due at 10:30 team time")
+	}
+	if teamDigestDue(team, monday.Add(-time.Hour)) {
+		t.Error("expected digest not due at 09:30 team time")
+	}
+	if teamDigestDue(team, monday.AddDate(0, 0, -1)) {
+		t.Error("expected digest not due on Sunday")
+	}
+
+	sent := monday.Add(-20 * time.Minute)
+	team.DigestLastSentAt = &sent
+	if teamDigestDue(team, monday) {
+		t.Error("expected digest not sent twice a day")
+	}
+
+	if !isValidTeamName("platform-core") || isValidTeamName("two words") {
+		t.Error("unexpected team name validation")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -63,14 +64,38 @@ func (c *VKCommandConsumer) processMessage(msg *botgolang.Message, from botgolan
 		c.handleUnsubscribeCommand(msg, from)
 	} else if strings.HasPrefix(msg.Text, "/label_reviewers") {
 		c.handleLabelReviewersCommand(msg, from)
+	} else if strings.HasPrefix(msg.Text, "/path_reviewers") {
+		c.handlePathReviewersCommand(msg, from)
+	} else if strings.HasPrefix(msg.Text, "/reviewer_strategy") {
+		c.handleReviewerStrategyCommand(msg, from)
+	} else if strings.HasPrefix(msg.Text, "/require_tier") {
+		c.handleRequireTierCommand(msg, from)
 	} else if strings.HasPrefix(msg.Text, "/reviewers") {
 		c.handleReviewersCommand(msg, from)
+	} else if strings.HasPrefix(msg.Text, "/team") {
+		c.handleTeamCommand(msg, from)
 	} else if strings.HasPrefix(msg.Text, "/actions") {
 		c.handleActionsCommand(msg, from)
 	} else if strings.HasPrefix(msg.Text, "/send_digest") {
 		c.handleSendDigestCommand(msg, from)
 	} else if strings.HasPrefix(msg.Text, "/get_mr_info") {
 		c.handleGetMRInfoCommand(msg, from)
+	} else if strings.HasPrefix(msg.Text, "/why_reviewer") {
+		c.handleWhyReviewerCommand(msg, from)
+	} else if strings.HasPrefix(msg.Text, "/reassign") {
+		c.handleReassignCommand(msg, from)
+	} else if strings.HasPrefix(msg.Text, "/simulate") {
+		c.handleSimulateCommand(msg, from)
+	} else if strings.HasPrefix(msg.Text, "/workload") {
+		c.handleWorkloadCommand(msg, from)
+	} else if strings.HasPrefix(msg.Text, "/pool_health") {
+		c.handlePoolHealthCommand(msg, from)
+	} else if strings.HasPrefix(msg.Text, "/capacity") {
+		c.handleCapacityCommand(msg, from)
+	} else if strings.HasPrefix(msg.Text, "/size") {
+		c.handleSizeCommand(msg, from)
+	} else if strings.HasPrefix(msg.Text, "/codeowners") {
+		c.handleCodeOwnersCommand(msg, from)
 	} else if strings.HasPrefix(msg.Text, "/vacation") {
 		c.handleVacationCommand(msg, from)
 	} else if strings.HasPrefix(msg.Text, "/assign_count") {
@@ -79,8 +104,18 @@ func (c *VKCommandConsumer) processMessage(msg *botgolang.Message, from botgolan
 		c.handleHolidaysCommand(msg, from)
 	} else if strings.HasPrefix(msg.Text, "/sla") {
 		c.handleSLACommand(msg, from)
+	} else if strings.HasPrefix(msg.Text, "/stale") {
+		c.handleStaleCommand(msg, from)
+	} else if strings.HasPrefix(msg.Text, "/auto_merge") {
+		c.handleAutoMergeCommand(msg, from)
+	} else if strings.HasPrefix(msg.Text, "/auto_rebase") {
+		c.handleAutoRebaseCommand(msg, from)
+	} else if msg.Text == "/mr" || strings.HasPrefix(msg.Text, "/mr ") {
+		c.handleMRCommand(msg, from)
 	} else if strings.HasPrefix(msg.Text, "/daily_digest") {
 		c.handleDailyDigestCommand(msg, from)
+	} else if strings.HasPrefix(msg.Text, "/notify") {
+		c.handleNotifyCommand(msg, from)
 	} else if strings.HasPrefix(msg.Text, "/add_block_label") {
 		c.handleAddBlockLabelCommand(msg, from)
 	} else if strings.HasPrefix(msg.Text, "/add_release_ready_label") {
@@ -216,6 +251,36 @@ func (c *VKCommandConsumer) handleSubscribeCommand(msg *botgolang.Message, from
 			if err := tx.Where("repository_id = ?", repo.ID).Delete(&models.ReleaseManager{}).Error; err != nil {
 				return fmt.Errorf("deleting release managers: %w", err)
 			}
+			if err := tx.Unscoped().Where("repository_id = ?", repo.ID).Delete(&models.CodeOwnersConfig{}).Error; err != nil {
+				return fmt.Errorf("deleting codeowners config: %w", err)
+			}
+			if err := tx.Unscoped().Where("repository_id = ?", repo.ID).Delete(&models.PathReviewer{}).Error; err != nil {
+				return fmt.Errorf("deleting path reviewers: %w", err)
+			}
+			if err := tx.Unscoped().Where("repository_id = ?", repo.ID).Delete(&models.SizeAssignRule{}).Error; err != nil {
+				return fmt.Errorf("deleting size rules: %w", err)
+			}
+			if err := tx.Unscoped().Where("repository_id = ?", repo.ID).Delete(&models.SizeLabelConfig{}).Error; err != nil {
+				return fmt.Errorf("deleting size label config: %w", err)
+			}
+			if err := tx.Unscoped().Where("repository_id = ?", repo.ID).Delete(&models.ReviewerPoolGroup{}).Error; err != nil {
+				return fmt.Errorf("deleting reviewer pool groups: %w", err)
+			}
+			if err := tx.Unscoped().Where("repository_id = ?", repo.ID).Delete(&models.ReviewerPoolExclusion{}).Error; err != nil {
+				return fmt.Errorf("deleting reviewer pool exclusions: %w", err)
+			}
+			if err := tx.Unscoped().Where("repository_id = ?", repo.ID).Delete(&models.TeamBinding{}).Error; err != nil {
+				return fmt.Errorf("deleting team bindings: %w", err)
+			}
+			if err := tx.Unscoped().Where("repository_id = ?", repo.ID).Delete(&models.StaleConfig{}).Error; err != nil {
+				return fmt.Errorf("deleting stale config: %w", err)
+			}
+			if err := tx.Unscoped().Where("repository_id = ?", repo.ID).Delete(&models.AutoMergeConfig{}).Error; err != nil {
+				return fmt.Errorf("deleting auto-merge config: %w", err)
+			}
+			if err := tx.Unscoped().Where("repository_id = ?", repo.ID).Delete(&models.AutoRebaseConfig{}).Error; err != nil {
+				return fmt.Errorf("deleting auto-rebase config: %w", err)
+			}
 		}
 
 		subscription := models.RepositorySubscription{
@@ -236,29 +301,46 @@ func (c *VKCommandConsumer) handleSubscribeCommand(msg *botgolang.Message, from
 			sourceRepoID := existingSubs[0].RepositoryID
 			settingsCopied = true
 
+			// Group-synced pool rows are rebuilt from the copied group bindings on the next poll.
 			var existingReviewers []models.PossibleReviewer
-			tx.Where("repository_id = ?", sourceRepoID).Find(&existingReviewers)
+			tx.Where("repository_id = ? AND pool_group_id IS NULL", sourceRepoID).Find(&existingReviewers)
 			for _, r := range existingReviewers {
-				if err := tx.Create(&models.PossibleReviewer{RepositoryID: repo.ID, UserID: r.UserID}).Error; err != nil {
+				if err := tx.Create(&models.PossibleReviewer{RepositoryID: repo.ID, UserID: r.UserID, Tier: r.Tier, TeamID: r.TeamID}).Error; err != nil {
 					return fmt.Errorf("copying possible reviewer: %w", err)
 				}
 			}
 
 			var existingLabelReviewers []models.LabelReviewer
-			tx.Where("repository_id = ?", sourceRepoID).Find(&existingLabelReviewers)
+			tx.Where("repository_id = ? AND pool_group_id IS NULL", sourceRepoID).Find(&existingLabelReviewers)
 			for _, lr := range existingLabelReviewers {
-				if err := tx.Create(&models.LabelReviewer{RepositoryID: repo.ID, LabelName: lr.LabelName, UserID: lr.UserID}).Error; err != nil {
+				if err := tx.Create(&models.LabelReviewer{RepositoryID: repo.ID, LabelName: lr.LabelName, UserID: lr.UserID, Tier: lr.Tier, TeamID: lr.TeamID}).Error; err != nil {
 					return fmt.Errorf("copying label reviewer: %w", err)
 				}
 			}
 
+			var existingPathReviewers []models.PathReviewer
+			tx.Where("repository_id = ?", sourceRepoID).Find(&existingPathReviewers)
+			for _, pr := range existingPathReviewers {
+				if err := tx.Create(&models.PathReviewer{RepositoryID: repo.ID, Pattern: pr.Pattern, UserID: pr.UserID}).Error; err != nil {
+					return fmt.Errorf("copying path reviewer: %w", err)
+				}
+			}
+
 			var existingSLA models.RepositorySLA
 			if err := tx.Where("repository_id = ?", sourceRepoID).First(&existingSLA).Error; err == nil {
 				if err := tx.Create(&models.RepositorySLA{
-					RepositoryID:   repo.ID,
-					ReviewDuration: existingSLA.ReviewDuration,
-					FixesDuration:  existingSLA.FixesDuration,
-					AssignCount:    existingSLA.AssignCount,
+					RepositoryID:      repo.ID,
+					ReviewDuration:    existingSLA.ReviewDuration,
+					FixesDuration:     existingSLA.FixesDuration,
+					AssignCount:       existingSLA.AssignCount,
+					ReassignThreshold: existingSLA.ReassignThreshold,
+					MaxAutoReassigns:  existingSLA.MaxAutoReassigns,
+					MaxOpenReviews:    existingSLA.MaxOpenReviews,
+					ReviewerStrategy:  existingSLA.ReviewerStrategy,
+					RequiredTier:      existingSLA.RequiredTier,
+					RequiredTierCount: existingSLA.RequiredTierCount,
+					WaitForPipeline:   existingSLA.WaitForPipeline,
+					AssignDrafts:      existingSLA.AssignDrafts,
 				}).Error; err != nil {
 					return fmt.Errorf("copying SLA: %w", err)
 				}
@@ -302,10 +384,82 @@ func (c *VKCommandConsumer) handleSubscribeCommand(msg *botgolang.Message, from
 			var existingReleaseManagers []models.ReleaseManager
 			tx.Where("repository_id = ?", sourceRepoID).Find(&existingReleaseManagers)
 			for _, rm := range existingReleaseManagers {
-				if err := tx.Create(&models.ReleaseManager{RepositoryID: repo.ID, UserID: rm.UserID}).Error; err != nil {
+				if err := tx.Create(&models.ReleaseManager{RepositoryID: repo.ID, UserID: rm.UserID, TeamID: rm.TeamID}).Error; err != nil {
 					return fmt.Errorf("copying release manager: %w", err)
 				}
 			}
+
+			var existingCodeOwners models.CodeOwnersConfig
+			if err := tx.Where("repository_id = ?", sourceRepoID).First(&existingCodeOwners).Error; err == nil {
+				if err := tx.Create(&models.CodeOwnersConfig{RepositoryID: repo.ID, FilePath: existingCodeOwners.FilePath}).Error; err != nil {
+					return fmt.Errorf("copying codeowners config: %w", err)
+				}
+			}
+
+			var existingSizeRules []models.SizeAssignRule
+			tx.Where("repository_id = ?", sourceRepoID).Find(&existingSizeRules)
+			for _, sr := range existingSizeRules {
+				if err := tx.Create(&models.SizeAssignRule{RepositoryID: repo.ID, MinLines: sr.MinLines, AssignCount: sr.AssignCount}).Error; err != nil {
+					return fmt.Errorf("copying size rule: %w", err)
+				}
+			}
+
+			var existingSizeLabels models.SizeLabelConfig
+			if err := tx.Where("repository_id = ?", sourceRepoID).First(&existingSizeLabels).Error; err == nil {
+				if err := tx.Create(&models.SizeLabelConfig{RepositoryID: repo.ID}).Error; err != nil {
+					return fmt.Errorf("copying size label config: %w", err)
+				}
+			}
+
+			var existingPoolGroups []models.ReviewerPoolGroup
+			tx.Where("repository_id = ?", sourceRepoID).Find(&existingPoolGroups)
+			for _, pg := range existingPoolGroups {
+				if err := tx.Create(&models.ReviewerPoolGroup{RepositoryID: repo.ID, LabelName: pg.LabelName, GroupPath: pg.GroupPath, MinAccessLevel: pg.MinAccessLevel}).Error; err != nil {
+					return fmt.Errorf("copying reviewer pool group: %w", err)
+				}
+			}
+
+			var existingExclusions []models.ReviewerPoolExclusion
+			tx.Where("repository_id = ?", sourceRepoID).Find(&existingExclusions)
+			for _, e := range existingExclusions {
+				if err := tx.Create(&models.ReviewerPoolExclusion{RepositoryID: repo.ID, UserID: e.UserID}).Error; err != nil {
+					return fmt.Errorf("copying reviewer pool exclusion: %w", err)
+				}
+			}
+
+			var existingTeamBindings []models.TeamBinding
+			tx.Where("repository_id = ?", sourceRepoID).Find(&existingTeamBindings)
+			for _, tb := range existingTeamBindings {
+				if err := tx.Create(&models.TeamBinding{TeamID: tb.TeamID, RepositoryID: repo.ID, Role: tb.Role, LabelName: tb.LabelName}).Error; err != nil {
+					return fmt.Errorf("copying team binding: %w", err)
+				}
+			}
+
+			var existingStale models.StaleConfig
+			if err := tx.Where("repository_id = ?", sourceRepoID).First(&existingStale).Error; err == nil {
+				if err := tx.Create(&models.StaleConfig{
+					RepositoryID:         repo.ID,
+					AfterDays:            existingStale.AfterDays,
+					ApplyLabel:           existingStale.ApplyLabel,
+					CloseDraftsAfterDays: existingStale.CloseDraftsAfterDays,
+				}).Error; err != nil {
+					return fmt.Errorf("copying stale config: %w", err)
+				}
+			}
+
+			var existingAutoMerge models.AutoMergeConfig
+			if err := tx.Where("repository_id = ?", sourceRepoID).First(&existingAutoMerge).Error; err == nil {
+				if err := tx.Create(&models.AutoMergeConfig{RepositoryID: repo.ID, LabelName: existingAutoMerge.LabelName}).Error; err != nil {
+					return fmt.Errorf("copying auto-merge config: %w", err)
+				}
+			}
+
+			var existingAutoRebase models.AutoRebaseConfig
+			if err := tx.Where("repository_id = ?", sourceRepoID).First(&existingAutoRebase).Error; err == nil {
+				if err := tx.Create(&models.AutoRebaseConfig{RepositoryID: repo.ID, MaxPerHour: existingAutoRebase.MaxPerHour}).Error; err != nil {
+					return fmt.Errorf("copying auto-rebase config: %w", err)
+				}
+			}
 		}
 
 		return nil
@@ -409,15 +563,41 @@ func (c *VKCommandConsumer) handleReviewersCommand(msg *botgolang.Message, _ bot
 			c.sendReply(msg, "Failed to clear reviewers")
 			return
 		}
+		c.db.Unscoped().Where("repository_id IN ? AND label_name = ?", repoIDs, "").Delete(&models.ReviewerPoolGroup{})
+		c.unbindTeams(repoIDs, models.TeamRoleReviewers, "")
 		c.sendReply(msg, fmt.Sprintf("Cleared all reviewers for repositories: %s", strings.Join(repoNames, ",")))
 		return
 	}
 
+	if argStr == "groups" {
+		c.replyReviewerPoolGroups(msg, repoIDs)
+		return
+	}
+	if strings.HasPrefix(argStr, "@") {
+		c.bindReviewerPoolGroup(msg, repoIDs, "", strings.Fields(argStr))
+		return
+	}
+	if fields := strings.Fields(argStr); fields[0] == "team" {
+		c.handleTeamBinding(msg, repoIDs, models.TeamRoleReviewers, "", fields)
+		return
+	}
+	if fields := strings.Fields(argStr); len(fields) > 0 && (fields[0] == "exclude" || fields[0] == "include") {
+		c.setReviewerPoolExclusions(msg, repoIDs, fields[0] == "exclude", strings.TrimSpace(strings.TrimPrefix(argStr, fields[0])))
+		return
+	}
+
 	names := strings.Split(argStr, ",")
+	for _, name := range names {
+		if _, tier := parseReviewerEntry(name); tier != "" && !isValidTierName(tier) {
+			c.sendReply(msg, fmt.Sprintf("Invalid tier '%s'. Use letters, digits, '_' or '-' (max 20).", tier))
+			return
+		}
+	}
+
 	var added []string
 	var notFoundUsers []string
 	for _, name := range names {
-		uname := strings.TrimSpace(name)
+		uname, tier := parseReviewerEntry(name)
 		if uname == "" {
 			continue
 		}
@@ -456,12 +636,14 @@ func (c *VKCommandConsumer) handleReviewersCommand(msg *botgolang.Message, _ bot
 			}
 		}
 		for _, rid := range repoIDs {
-			pr := models.PossibleReviewer{RepositoryID: rid, UserID: user.ID}
-			if err := c.db.FirstOrCreate(&pr, models.PossibleReviewer{RepositoryID: rid, UserID: user.ID}).Error; err != nil {
+			var pr models.PossibleReviewer
+			if err := c.db.Where(models.PossibleReviewer{RepositoryID: rid, UserID: user.ID}).
+				Assign(map[string]interface{}{"tier": tier, "pool_group_id": nil, "team_id": nil}).
+				FirstOrCreate(&pr).Error; err != nil {
 				log.Printf("Failed to create possible reviewer link for repo %d and user %d: %v", rid, user.ID, err)
 			}
 		}
-		added = append(added, user.Username)
+		added = append(added, formatReviewerTier(user.Username, tier))
 	}
 
 	replyText := fmt.Sprintf("Reviewers for repositories %s updated: %s.", strings.Join(repoNames, ", "), strings.Join(added, ", "))
@@ -471,6 +653,276 @@ func (c *VKCommandConsumer) handleReviewersCommand(msg *botgolang.Message, _ bot
 	c.sendReply(msg, replyText)
 }
 
+// parseReviewerEntry splits a "username:tier" entry of a reviewer list. The tier is optional.
+func parseReviewerEntry(entry string) (string, string) {
+	username, tier, _ := strings.Cut(strings.TrimSpace(entry), ":")
+	return strings.TrimSpace(username), strings.ToLower(strings.TrimSpace(tier))
+}
+
+func isValidTierName(tier string) bool {
+	if tier == "" || len(tier) > 20 {
+		return false
+	}
+	for _, r := range tier {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_' && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+func formatReviewerTier(username, tier string) string {
+	if tier == "" {
+		return username
+	}
+	return fmt.Sprintf("%s (%s)", username, tier)
+}
+
+// bindReviewerPoolGroup binds the default pool (empty labelName) or a label pool of the
+// repositories to a GitLab group. args are "@group/path" and an optional minimum access level.
+// Members are synced by the next poll.
+func (c *VKCommandConsumer) bindReviewerPoolGroup(msg *botgolang.Message, repoIDs []uint, labelName string, args []string) {
+	if len(args) > 2 {
+		c.sendReply(msg, "Usage: @group/path [guest|reporter|developer|maintainer|owner]")
+		return
+	}
+	minLevel := gitlab.DeveloperPermissions
+	if len(args) == 2 {
+		level, ok := parseAccessLevel(args[1])
+		if !ok {
+			c.sendReply(msg, fmt.Sprintf("Unknown access level '%s'. Use guest, reporter, developer, maintainer or owner.", args[1]))
+			return
+		}
+		minLevel = level
+	}
+
+	groupPath := strings.TrimPrefix(args[0], "@")
+	group, _, err := c.glClient.Groups.GetGroup(groupPath, nil)
+	if err != nil {
+		log.Printf("Failed to get GitLab group %s: %v", groupPath, err)
+		c.sendReply(msg, fmt.Sprintf("GitLab group '%s' not found", groupPath))
+		return
+	}
+
+	for _, rid := range repoIDs {
+		var binding models.ReviewerPoolGroup
+		if err := c.db.Where(models.ReviewerPoolGroup{RepositoryID: rid, LabelName: labelName, GroupPath: group.FullPath}).
+			Assign(map[string]interface{}{"min_access_level": int(minLevel), "synced_at": nil}).
+			FirstOrCreate(&binding).Error; err != nil {
+			log.Printf("Failed to bind reviewer pool of repo %d to group %s: %v", rid, group.FullPath, err)
+			c.sendReply(msg, "Failed to bind reviewer group")
+			return
+		}
+	}
+
+	pool := "Default reviewer pool"
+	if labelName != "" {
+		pool = fmt.Sprintf("Reviewers for label '%s'", labelName)
+	}
+	c.sendReply(msg, fmt.Sprintf("%s bound to group %s (%s and above). Members are synced on the next poll.",
+		pool, group.FullPath, accessLevelName(int(minLevel))))
+}
+
+// replyReviewerPoolGroups lists group bindings and pool exclusions of the repositories.
+func (c *VKCommandConsumer) replyReviewerPoolGroups(msg *botgolang.Message, repoIDs []uint) {
+	var bindings []models.ReviewerPoolGroup
+	c.db.Preload("Repository").Where("repository_id IN ?", repoIDs).Order("label_name, group_path").Find(&bindings)
+	var exclusions []models.ReviewerPoolExclusion
+	c.db.Preload("Repository").Preload("User").Where("repository_id IN ?", repoIDs).Find(&exclusions)
+
+	if len(bindings) == 0 && len(exclusions) == 0 {
+		c.sendReply(msg, "No reviewer groups configured.")
+		return
+	}
+
+	var lines []string
+	for _, b := range bindings {
+		pool := "default"
+		if b.LabelName != "" {
+			pool = "label " + b.LabelName
+		}
+		synced := "not synced yet"
+		if b.SyncedAt != nil {
+			synced = "synced " + b.SyncedAt.Format("2006-01-02 15:04")
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s pool <- @%s (%s+, %s)",
+			b.Repository.Name, pool, b.GroupPath, accessLevelName(b.MinAccessLevel), synced))
+	}
+	for _, e := range exclusions {
+		lines = append(lines, fmt.Sprintf("%s: excluded %s", e.Repository.Name, e.User.Username))
+	}
+	c.sendReply(msg, "Reviewer groups:\n"+strings.Join(lines, "\n"))
+}
+
+// setReviewerPoolExclusions keeps users out of (or lets them back into) group-synced pools.
+// Excluded users lose their synced rows right away; manually added rows are kept.
+func (c *VKCommandConsumer) setReviewerPoolExclusions(msg *botgolang.Message, repoIDs []uint, exclude bool, argStr string) {
+	var usernames []string
+	for _, name := range strings.Split(argStr, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			usernames = append(usernames, name)
+		}
+	}
+	if len(usernames) == 0 {
+		c.sendReply(msg, "Usage: /reviewers exclude|include user1,user2")
+		return
+	}
+
+	var users []models.User
+	c.db.Where("username IN ?", usernames).Find(&users)
+	var done []string
+	for _, u := range users {
+		for _, rid := range repoIDs {
+			if exclude {
+				var e models.ReviewerPoolExclusion
+				if err := c.db.Where(models.ReviewerPoolExclusion{RepositoryID: rid, UserID: u.ID}).FirstOrCreate(&e).Error; err != nil {
+					log.Printf("Failed to exclude user %d from pools of repo %d: %v", u.ID, rid, err)
+					continue
+				}
+				c.db.Unscoped().Where("repository_id = ? AND user_id = ? AND pool_group_id IS NOT NULL", rid, u.ID).Delete(&models.PossibleReviewer{})
+				c.db.Unscoped().Where("repository_id = ? AND user_id = ? AND pool_group_id IS NOT NULL", rid, u.ID).Delete(&models.LabelReviewer{})
+			} else {
+				c.db.Unscoped().Where("repository_id = ? AND user_id = ?", rid, u.ID).Delete(&models.ReviewerPoolExclusion{})
+			}
+		}
+		done = append(done, u.Username)
+	}
+	if !exclude && len(done) > 0 {
+		// Re-sync so included users come back without waiting for the interval.
+		c.db.Model(&models.ReviewerPoolGroup{}).Where("repository_id IN ?", repoIDs).Update("synced_at", nil)
+	}
+
+	var notFound []string
+	for _, name := range usernames {
+		if !containsString(done, name) {
+			notFound = append(notFound, name)
+		}
+	}
+
+	verb := "Excluded from group-synced pools"
+	if !exclude {
+		verb = "Included in group-synced pools again"
+	}
+	reply := fmt.Sprintf("%s: %s", verb, strings.Join(done, ", "))
+	if len(done) == 0 {
+		reply = "No users updated"
+	}
+	if len(notFound) > 0 {
+		reply += fmt.Sprintf(". Not found: %s", strings.Join(notFound, ", "))
+	}
+	c.sendReply(msg, reply)
+}
+
+var accessLevelNames = []struct {
+	name  string
+	level gitlab.AccessLevelValue
+}{
+	{"guest", gitlab.GuestPermissions},
+	{"reporter", gitlab.ReporterPermissions},
+	{"developer", gitlab.DeveloperPermissions},
+	{"maintainer", gitlab.MaintainerPermissions},
+	{"owner", gitlab.OwnerPermissions},
+}
+
+// parseAccessLevel parses a GitLab role name into its access level.
+func parseAccessLevel(s string) (gitlab.AccessLevelValue, bool) {
+	for _, l := range accessLevelNames {
+		if strings.EqualFold(s, l.name) {
+			return l.level, true
+		}
+	}
+	return 0, false
+}
+
+func accessLevelName(level int) string {
+	for _, l := range accessLevelNames {
+		if int(l.level) == level {
+			return l.name
+		}
+	}
+	return fmt.Sprintf("level %d", level)
+}
+
+// handleRequireTierCommand sets how many reviewers of a tier every MR of the subscribed repositories needs.
+// Format: /require_tier [<tier> <count>|off]
+// Tiers are assigned with /reviewers user:tier or /label_reviewers <label> user:tier.
+func (c *VKCommandConsumer) handleRequireTierCommand(msg *botgolang.Message, _ botgolang.Contact) {
+	const usage = "Usage: /require_tier <tier> <count> or /require_tier off"
+	chatID := fmt.Sprint(msg.Chat.ID)
+	var chat models.Chat
+	if err := c.db.Where("chat_id = ?", chatID).First(&chat).Error; err != nil {
+		c.sendReply(msg, "Chat not found")
+		return
+	}
+
+	var subs []models.RepositorySubscription
+	c.db.Preload("Repository").Where("chat_id = ?", chat.ID).Find(&subs)
+	if len(subs) == 0 {
+		c.sendReply(msg, "No repository subscription found. Use /subscribe first.")
+		return
+	}
+
+	parts := strings.Fields(msg.Text)
+	if len(parts) < 2 {
+		var lines []string
+		for _, sub := range subs {
+			sla, err := utils.GetRepositorySLA(c.db, sub.RepositoryID)
+			if err != nil {
+				log.Printf("failed to get SLA for repo %d: %v", sub.RepositoryID, err)
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("%s: %s", sub.Repository.Name, formatTierRequirement(sla.RequiredTier, sla.RequiredTierCount)))
+		}
+		c.sendReply(msg, "Required reviewer tiers:\n"+strings.Join(lines, "\n"))
+		return
+	}
+
+	tier := ""
+	count := 0
+	if strings.ToLower(parts[1]) != "off" {
+		if len(parts) < 3 {
+			c.sendReply(msg, usage)
+			return
+		}
+		tier = strings.ToLower(parts[1])
+		if !isValidTierName(tier) {
+			c.sendReply(msg, fmt.Sprintf("Invalid tier '%s'. Use letters, digits, '_' or '-' (max 20).", tier))
+			return
+		}
+		value, err := strconv.Atoi(parts[2])
+		if err != nil || value <= 0 {
+			c.sendReply(msg, "Count must be a positive number")
+			return
+		}
+		count = value
+	}
+
+	var repoNames []string
+	for _, sub := range subs {
+		var sla models.RepositorySLA
+		if err := c.db.Where(models.RepositorySLA{RepositoryID: sub.RepositoryID}).FirstOrCreate(&sla).Error; err != nil {
+			log.Printf("failed to get/create SLA for repo %d: %v", sub.RepositoryID, err)
+			continue
+		}
+		sla.RequiredTier = tier
+		sla.RequiredTierCount = count
+		if err := c.db.Save(&sla).Error; err != nil {
+			log.Printf("failed to save SLA for repo %d: %v", sub.RepositoryID, err)
+			continue
+		}
+		repoNames = append(repoNames, sub.Repository.Name)
+	}
+
+	c.sendReply(msg, fmt.Sprintf("Required reviewer tier set to %s for: %s", formatTierRequirement(tier, count), strings.Join(repoNames, ", ")))
+}
+
+func formatTierRequirement(tier string, count int) string {
+	if tier == "" || count <= 0 {
+		return "none"
+	}
+	return fmt.Sprintf("%d %s", count, tier)
+}
+
 func (c *VKCommandConsumer) handleReleaseManagersCommand(msg *botgolang.Message, _ botgolang.Contact) {
 	chatID := fmt.Sprint(msg.Chat.ID)
 	var chat models.Chat
@@ -511,7 +963,12 @@ func (c *VKCommandConsumer) handleReleaseManagersCommand(msg *botgolang.Message,
 		return
 	}
 
-	if err := c.db.Where("repository_id IN ?", repoIDs).Delete(&models.ReleaseManager{}).Error; err != nil {
+	if fields := strings.Fields(argStr); fields[0] == "team" {
+		c.handleTeamBinding(msg, repoIDs, models.TeamRoleReleaseManagers, "", fields)
+		return
+	}
+
+	if err := c.db.Where("repository_id IN ? AND team_id IS NULL", repoIDs).Delete(&models.ReleaseManager{}).Error; err != nil {
 		c.sendReply(msg, "Failed to clear existing release managers")
 		return
 	}
@@ -559,8 +1016,10 @@ func (c *VKCommandConsumer) handleReleaseManagersCommand(msg *botgolang.Message,
 			}
 		}
 		for _, rid := range repoIDs {
-			rm := models.ReleaseManager{RepositoryID: rid, UserID: user.ID}
-			if err := c.db.FirstOrCreate(&rm, models.ReleaseManager{RepositoryID: rid, UserID: user.ID}).Error; err != nil {
+			var rm models.ReleaseManager
+			if err := c.db.Where(models.ReleaseManager{RepositoryID: rid, UserID: user.ID}).
+				Assign(map[string]interface{}{"team_id": nil}).
+				FirstOrCreate(&rm).Error; err != nil {
 				log.Printf("Failed to create release manager link for repo %d and user %d: %v", rid, user.ID, err)
 			}
 		}
@@ -574,62 +1033,441 @@ func (c *VKCommandConsumer) handleReleaseManagersCommand(msg *botgolang.Message,
 	c.sendReply(msg, replyText)
 }
 
-func (c *VKCommandConsumer) handleActionsCommand(msg *botgolang.Message, from botgolang.Contact) {
+// handleTeamCommand manages teams that can be referenced by reviewer pools and release managers.
+// Format: /team [create|delete|show|digest|chat <name>] [add|remove <name> user1,user2]
+// [lead <name> <user>] [timezone <name> <offset>]
+// Without arguments teams are listed. Member changes are synced into every pool bound to the team.
+func (c *VKCommandConsumer) handleTeamCommand(msg *botgolang.Message, _ botgolang.Contact) {
+	const usage = "Usage: /team create|delete|show|digest|chat <name>, /team add|remove <name> user1,user2, /team lead <name> <user>, /team timezone <name> <+3>"
 	parts := strings.Fields(msg.Text)
-	var username string
-	if len(parts) < 2 {
-		vkID := fmt.Sprint(from.ID)
-		var vkUser models.VKUser
-		if err := c.db.Where("user_id = ?", vkID).First(&vkUser).Error; err != nil {
-			c.sendReply(msg, "Cannot determine your account. Please specify a GitLab username: /actions <username>")
+	if len(parts) == 1 {
+		c.replyTeams(msg)
+		return
+	}
+	if len(parts) < 3 {
+		c.sendReply(msg, usage)
+		return
+	}
+
+	sub, name := parts[1], strings.ToLower(parts[2])
+	if sub == "create" {
+		if !isValidTeamName(name) {
+			c.sendReply(msg, fmt.Sprintf("Invalid team name '%s'. Use letters, digits, '_' or '-' (max 50).", name))
 			return
 		}
-		var user models.User
-		if err := c.db.Where("email = ?", vkUser.UserID).First(&user).Error; err != nil {
-			c.sendReply(msg, "No linked GitLab user found for your VK account. Please specify a username: /actions <username>")
+		var chat models.Chat
+		if err := c.db.Where("chat_id = ?", fmt.Sprint(msg.Chat.ID)).First(&chat).Error; err != nil {
+			c.sendReply(msg, "Chat not found")
 			return
 		}
-		username = user.Username
-	} else {
-		username = strings.TrimSpace(parts[1])
-	}
-
-	var user models.User
-	if err := c.db.Where("username = ?", username).First(&user).Error; err != nil {
-		c.sendReply(msg, fmt.Sprintf("User %s not found", username))
+		var existing int64
+		c.db.Model(&models.Team{}).Where("name = ?", name).Count(&existing)
+		if existing > 0 {
+			c.sendReply(msg, fmt.Sprintf("Team '%s' already exists", name))
+			return
+		}
+		if err := c.db.Create(&models.Team{Name: name, ChatID: &chat.ID}).Error; err != nil {
+			log.Printf("failed to create team %s: %v", name, err)
+			c.sendReply(msg, "Failed to create team")
+			return
+		}
+		c.sendReply(msg, fmt.Sprintf("Team '%s' created. Its digest goes to this chat. Add members with /team add %s user1,user2", name, name))
 		return
 	}
 
-	reviewMRs, fixesMRs, authorOnReviewMRs, err := utils.FindUserActionMRs(c.db, user.ID)
-	if err != nil {
-		log.Printf("failed to fetch actions for user %s: %v", username, err)
-		c.sendReply(msg, "Failed to fetch actions. Please try again later.")
+	var team models.Team
+	if err := c.db.Preload("Lead").Preload("Chat").Preload("Members").Where("name = ?", name).First(&team).Error; err != nil {
+		c.sendReply(msg, fmt.Sprintf("Team '%s' not found", name))
 		return
 	}
 
-	releaseMRs, err := utils.FindReleaseManagerActionMRs(c.db, user.ID)
-	if err != nil {
-		log.Printf("failed to fetch release manager MRs for user %s: %v", username, err)
-	}
-
-	text := utils.BuildUserActionsDigest(c.db, reviewMRs, fixesMRs, authorOnReviewMRs, releaseMRs, username)
-	replyMsg := c.vkBot.NewTextMessage(fmt.Sprint(msg.Chat.ID), text)
-	if err := replyMsg.Send(); err != nil {
-		log.Printf("failed to send actions digest: %v", err)
+	// Teams feed pools and release managers of other chats, so only the team's own chat may change
+	// it. Teams whose chat was removed can be claimed by any chat.
+	if sub != "show" && sub != "digest" {
+		var chat models.Chat
+		if err := c.db.Where("chat_id = ?", fmt.Sprint(msg.Chat.ID)).First(&chat).Error; err != nil {
+			c.sendReply(msg, "Chat not found")
+			return
+		}
+		if team.ChatID != nil && *team.ChatID != chat.ID {
+			c.sendReply(msg, fmt.Sprintf("Team '%s' can only be changed from its own chat", name))
+			return
+		}
 	}
-}
-
-func (c *VKCommandConsumer) handleSendDigestCommand(msg *botgolang.Message, _ botgolang.Contact) {
-	chatID := fmt.Sprint(msg.Chat.ID)
 
-	var chat models.Chat
-	if err := c.db.Where("chat_id = ?", chatID).First(&chat).Error; err != nil {
-		c.sendReply(msg, "Chat not found in database")
-		return
-	}
+	switch sub {
+	case "delete":
+		err := c.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Unscoped().Where("team_id = ?", team.ID).Delete(&models.TeamBinding{}).Error; err != nil {
+				return err
+			}
+			if err := utils.SyncTeam(tx, team.ID); err != nil {
+				return err
+			}
+			if err := tx.Model(&team).Association("Members").Clear(); err != nil {
+				return err
+			}
+			return tx.Unscoped().Delete(&team).Error
+		})
+		if err != nil {
+			log.Printf("failed to delete team %s: %v", name, err)
+			c.sendReply(msg, "Failed to delete team")
+			return
+		}
+		c.sendReply(msg, fmt.Sprintf("Team '%s' deleted and its members removed from bound pools", name))
 
-	var subs []models.RepositorySubscription
-	if err := c.db.
+	case "add", "remove":
+		if len(parts) < 4 {
+			c.sendReply(msg, usage)
+			return
+		}
+		var changed, notFound []string
+		for _, uname := range strings.Split(strings.Join(parts[3:], ""), ",") {
+			if uname = strings.TrimSpace(uname); uname == "" {
+				continue
+			}
+			user, ok := c.findOrFetchUser(uname)
+			if !ok {
+				notFound = append(notFound, uname)
+				continue
+			}
+			var err error
+			if sub == "add" {
+				err = c.db.Model(&team).Association("Members").Append(&user)
+			} else {
+				err = c.db.Model(&team).Association("Members").Delete(&user)
+			}
+			if err != nil {
+				log.Printf("failed to update member %s of team %s: %v", uname, name, err)
+				continue
+			}
+			changed = append(changed, user.Username)
+		}
+		if err := utils.SyncTeam(c.db, team.ID); err != nil {
+			log.Printf("failed to sync team %s: %v", name, err)
+		}
+
+		verb := "added to"
+		if sub == "remove" {
+			verb = "removed from"
+		}
+		reply := fmt.Sprintf("Members %s team '%s': %s", verb, name, strings.Join(changed, ", "))
+		if len(notFound) > 0 {
+			reply += fmt.Sprintf(". Not found: %s", strings.Join(notFound, ", "))
+		}
+		c.sendReply(msg, reply)
+
+	case "lead":
+		if len(parts) < 4 {
+			c.sendReply(msg, usage)
+			return
+		}
+		user, ok := c.findOrFetchUser(parts[3])
+		if !ok {
+			c.sendReply(msg, fmt.Sprintf("User %s not found", parts[3]))
+			return
+		}
+		if err := c.db.Model(&team).Update("lead_id", user.ID).Error; err != nil {
+			log.Printf("failed to set lead of team %s: %v", name, err)
+			c.sendReply(msg, "Failed to set team lead")
+			return
+		}
+		c.sendReply(msg, fmt.Sprintf("Team '%s' lead: %s", name, user.Username))
+
+	case "timezone":
+		if len(parts) < 4 {
+			c.sendReply(msg, usage)
+			return
+		}
+		offset, err := parseTimezoneOffset(parts[3])
+		if err != nil {
+			c.sendReply(msg, "Invalid timezone offset. Use e.g. +3 or -5")
+			return
+		}
+		if err := c.db.Model(&team).Update("timezone_offset", offset).Error; err != nil {
+			log.Printf("failed to set timezone of team %s: %v", name, err)
+			c.sendReply(msg, "Failed to set team timezone")
+			return
+		}
+		c.sendReply(msg, fmt.Sprintf("Team '%s' timezone: %s", name, formatTimezone(offset)))
+
+	case "chat":
+		var chat models.Chat
+		if err := c.db.Where("chat_id = ?", fmt.Sprint(msg.Chat.ID)).First(&chat).Error; err != nil {
+			c.sendReply(msg, "Chat not found")
+			return
+		}
+		if err := c.db.Model(&team).Update("chat_id", chat.ID).Error; err != nil {
+			log.Printf("failed to set chat of team %s: %v", name, err)
+			c.sendReply(msg, "Failed to set team chat")
+			return
+		}
+		c.sendReply(msg, fmt.Sprintf("Team '%s' digest now goes to this chat", name))
+
+	case "digest":
+		text, err := buildTeamDigest(c.db, &team)
+		if err != nil {
+			log.Printf("failed to build digest for team %s: %v", name, err)
+			c.sendReply(msg, "Failed to fetch merge requests. Please try again later.")
+			return
+		}
+		if text == "" {
+			text = fmt.Sprintf("No open merge requests for team '%s'", name)
+		}
+		c.sendReply(msg, text)
+
+	case "show":
+		c.sendReply(msg, c.formatTeam(&team))
+
+	default:
+		c.sendReply(msg, usage)
+	}
+}
+
+// handleTeamBinding adds a team to (args "team <name>") or removes it from (args "team remove <name>")
+// the default pool, a label pool or release managers of the repositories and syncs its members.
+func (c *VKCommandConsumer) handleTeamBinding(msg *botgolang.Message, repoIDs []uint, role, labelName string, args []string) {
+	remove := len(args) == 3 && args[1] == "remove"
+	if len(args) != 2 && !remove {
+		c.sendReply(msg, "Usage: team <name> or team remove <name>")
+		return
+	}
+	teamName := args[len(args)-1]
+	var team models.Team
+	if err := c.db.Where("name = ?", strings.ToLower(teamName)).First(&team).Error; err != nil {
+		c.sendReply(msg, fmt.Sprintf("Team '%s' not found", teamName))
+		return
+	}
+
+	if remove {
+		if err := c.db.Unscoped().Where("team_id = ? AND repository_id IN ? AND role = ? AND label_name = ?", team.ID, repoIDs, role, labelName).
+			Delete(&models.TeamBinding{}).Error; err != nil {
+			log.Printf("Failed to unbind team %s: %v", team.Name, err)
+			c.sendReply(msg, "Failed to remove team")
+			return
+		}
+		if err := utils.SyncTeam(c.db, team.ID); err != nil {
+			log.Printf("failed to sync team %s: %v", team.Name, err)
+		}
+		c.sendReply(msg, fmt.Sprintf("Team '%s' removed from %s", team.Name, formatTeamBinding(role, labelName)))
+		return
+	}
+
+	for _, rid := range repoIDs {
+		var binding models.TeamBinding
+		if err := c.db.Where(models.TeamBinding{TeamID: team.ID, RepositoryID: rid, Role: role, LabelName: labelName}).
+			FirstOrCreate(&binding).Error; err != nil {
+			log.Printf("Failed to bind team %s to repo %d: %v", team.Name, rid, err)
+			c.sendReply(msg, "Failed to bind team")
+			return
+		}
+	}
+	if err := utils.SyncTeam(c.db, team.ID); err != nil {
+		log.Printf("failed to sync team %s: %v", team.Name, err)
+		c.sendReply(msg, "Team bound, but syncing its members failed. Please try again later.")
+		return
+	}
+
+	c.sendReply(msg, fmt.Sprintf("Team '%s' added to %s", team.Name, formatTeamBinding(role, labelName)))
+}
+
+// unbindTeams removes every team binding of a pool of the repositories along with the synced rows.
+func (c *VKCommandConsumer) unbindTeams(repoIDs []uint, role, labelName string) {
+	var teamIDs []uint
+	c.db.Model(&models.TeamBinding{}).Where("repository_id IN ? AND role = ? AND label_name = ?", repoIDs, role, labelName).
+		Distinct().Pluck("team_id", &teamIDs)
+	if len(teamIDs) == 0 {
+		return
+	}
+	if err := c.db.Unscoped().Where("repository_id IN ? AND role = ? AND label_name = ?", repoIDs, role, labelName).
+		Delete(&models.TeamBinding{}).Error; err != nil {
+		log.Printf("failed to remove team bindings: %v", err)
+		return
+	}
+	for _, id := range teamIDs {
+		if err := utils.SyncTeam(c.db, id); err != nil {
+			log.Printf("failed to sync team %d: %v", id, err)
+		}
+	}
+}
+
+func (c *VKCommandConsumer) replyTeams(msg *botgolang.Message) {
+	var teams []models.Team
+	c.db.Preload("Lead").Preload("Members").Order("name").Find(&teams)
+	if len(teams) == 0 {
+		c.sendReply(msg, "No teams configured. Use /team create <name>.")
+		return
+	}
+
+	var lines []string
+	for _, t := range teams {
+		line := fmt.Sprintf("- %s: %d members", t.Name, len(t.Members))
+		if t.Lead != nil {
+			line += ", lead " + t.Lead.Username
+		}
+		lines = append(lines, line)
+	}
+	c.sendReply(msg, "Teams:\n"+strings.Join(lines, "\n"))
+}
+
+// formatTeam renders a team's settings, bindings and the review workload of its members.
+func (c *VKCommandConsumer) formatTeam(team *models.Team) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("TEAM: %s\n", team.Name))
+	lead := "-"
+	if team.Lead != nil {
+		lead = team.Lead.Username
+	}
+	chat := "-"
+	if team.Chat != nil {
+		chat = team.Chat.Title
+		if chat == "" {
+			chat = team.Chat.ChatID
+		}
+	}
+	sb.WriteString(fmt.Sprintf("Lead: %s\nTimezone: %s\nDigest chat: %s\n", lead, formatTimezone(team.TimezoneOffset), chat))
+
+	var bindings []models.TeamBinding
+	c.db.Preload("Repository").Where("team_id = ?", team.ID).Order("repository_id, role, label_name").Find(&bindings)
+	if len(bindings) > 0 {
+		sb.WriteString("\nUsed in:\n")
+		for _, b := range bindings {
+			sb.WriteString(fmt.Sprintf("- %s: %s\n", b.Repository.Name, formatTeamBinding(b.Role, b.LabelName)))
+		}
+	}
+
+	if len(team.Members) == 0 {
+		sb.WriteString("\nNo members.")
+		return sb.String()
+	}
+
+	userIDs := make([]uint, len(team.Members))
+	for i, m := range team.Members {
+		userIDs[i] = m.ID
+	}
+	openCounts, oldest, err := utils.GetOpenReviewStats(c.db, userIDs)
+	if err != nil {
+		log.Printf("failed to fetch open review stats: %v", err)
+	}
+	recentCounts, err := utils.GetRecentReviewCounts(c.db, userIDs)
+	if err != nil {
+		log.Printf("failed to fetch recent review counts: %v", err)
+	}
+	loads := utils.BuildPoolLoad(team.Members, openCounts, oldest, recentCounts)
+	for i := range loads {
+		loads[i].Capacity = utils.EffectiveCapacity(loads[i].User, 0)
+	}
+	sb.WriteString("\nMembers:\n")
+	sb.WriteString(formatWorkloadSection(loads, time.Now()))
+	return sb.String()
+}
+
+func formatTeamBinding(role, labelName string) string {
+	switch role {
+	case models.TeamRoleLabelReviewers:
+		return fmt.Sprintf("reviewers for label '%s'", labelName)
+	case models.TeamRoleReleaseManagers:
+		return "release managers"
+	default:
+		return "default reviewer pool"
+	}
+}
+
+func isValidTeamName(name string) bool {
+	if name == "" || len(name) > 50 {
+		return false
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_' && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// findOrFetchUser looks a user up by username, fetching and storing it from GitLab when unknown.
+func (c *VKCommandConsumer) findOrFetchUser(username string) (models.User, bool) {
+	var user models.User
+	if err := c.db.Where("username = ?", username).First(&user).Error; err == nil {
+		return user, true
+	}
+	users, _, err := c.glClient.Users.ListUsers(&gitlab.ListUsersOptions{Username: gitlab.Ptr(username)})
+	if err != nil || len(users) == 0 {
+		log.Printf("User %s not found in GitLab or API error: %v", username, err)
+		return user, false
+	}
+	userData := models.User{
+		GitlabID:  users[0].ID,
+		Username:  users[0].Username,
+		Name:      users[0].Name,
+		State:     users[0].State,
+		AvatarURL: users[0].AvatarURL,
+		WebURL:    users[0].WebURL,
+		Email:     users[0].Email,
+	}
+	if err := c.db.Where(models.User{GitlabID: users[0].ID}).Assign(userData).FirstOrCreate(&user).Error; err != nil {
+		log.Printf("Failed to upsert GitLab user %s: %v", username, err)
+		return user, false
+	}
+	return user, true
+}
+
+func (c *VKCommandConsumer) handleActionsCommand(msg *botgolang.Message, from botgolang.Contact) {
+	parts := strings.Fields(msg.Text)
+	var username string
+	if len(parts) < 2 {
+		vkID := fmt.Sprint(from.ID)
+		var vkUser models.VKUser
+		if err := c.db.Where("user_id = ?", vkID).First(&vkUser).Error; err != nil {
+			c.sendReply(msg, "Cannot determine your account. Please specify a GitLab username: /actions <username>")
+			return
+		}
+		var user models.User
+		if err := c.db.Where("email = ?", vkUser.UserID).First(&user).Error; err != nil {
+			c.sendReply(msg, "No linked GitLab user found for your VK account. Please specify a username: /actions <username>")
+			return
+		}
+		username = user.Username
+	} else {
+		username = strings.TrimSpace(parts[1])
+	}
+
+	var user models.User
+	if err := c.db.Where("username = ?", username).First(&user).Error; err != nil {
+		c.sendReply(msg, fmt.Sprintf("User %s not found", username))
+		return
+	}
+
+	reviewMRs, fixesMRs, authorOnReviewMRs, err := utils.FindUserActionMRs(c.db, user.ID)
+	if err != nil {
+		log.Printf("failed to fetch actions for user %s: %v", username, err)
+		c.sendReply(msg, "Failed to fetch actions. Please try again later.")
+		return
+	}
+
+	releaseMRs, err := utils.FindReleaseManagerActionMRs(c.db, user.ID)
+	if err != nil {
+		log.Printf("failed to fetch release manager MRs for user %s: %v", username, err)
+	}
+
+	text := utils.BuildUserActionsDigest(c.db, reviewMRs, fixesMRs, authorOnReviewMRs, releaseMRs, username)
+	replyMsg := c.vkBot.NewTextMessage(fmt.Sprint(msg.Chat.ID), text)
+	if err := replyMsg.Send(); err != nil {
+		log.Printf("failed to send actions digest: %v", err)
+	}
+}
+
+func (c *VKCommandConsumer) handleSendDigestCommand(msg *botgolang.Message, _ botgolang.Contact) {
+	chatID := fmt.Sprint(msg.Chat.ID)
+
+	var chat models.Chat
+	if err := c.db.Where("chat_id = ?", chatID).First(&chat).Error; err != nil {
+		c.sendReply(msg, "Chat not found in database")
+		return
+	}
+
+	var subs []models.RepositorySubscription
+	if err := c.db.
 		Preload("Repository").
 		Where("chat_id = ?", chat.ID).
 		Find(&subs).Error; err != nil {
@@ -668,14 +1506,11 @@ func (c *VKCommandConsumer) handleGetMRInfoCommand(msg *botgolang.Message, _ bot
 		c.sendReply(msg, "Usage: /get_mr_info <project_path!iid> (e.g., intdev/jobofferapp!2103)")
 		return
 	}
-	ref := strings.TrimSpace(parts[1])
-	bangIdx := strings.LastIndex(ref, "!")
-	if bangIdx == -1 || bangIdx == 0 || bangIdx == len(ref)-1 {
+	projectPath, mrIID, err := parseMRReference(parts[1])
+	if err != nil {
 		c.sendReply(msg, "Invalid reference format. Use <project_path!iid> (e.g., intdev/jobofferapp!2103)")
 		return
 	}
-	projectPath := ref[:bangIdx]
-	mrIID := ref[bangIdx+1:]
 
 	repo, err := utils.FindRepositoryByIdentifier(c.db, projectPath)
 	if err != nil {
@@ -718,62 +1553,1131 @@ func (c *VKCommandConsumer) handleGetMRInfoCommand(msg *botgolang.Message, _ bot
 		createdAt = mr.GitlabCreatedAt.Format("2006-01-02 15:04:05")
 	}
 
+	pipeline := "none"
+	if mr.PipelineStatus != "" {
+		pipeline = fmt.Sprintf("%s %s", utils.PipelineBadge(&mr), mr.PipelineStatus)
+		if mr.PipelineWebURL != "" {
+			pipeline += " " + mr.PipelineWebURL
+		}
+	}
+
+	approvalRules := "satisfied"
+	if missing := utils.MissingApprovals(&mr); missing != "" {
+		approvalRules = missing
+	}
+
 	info := fmt.Sprintf(
-		"MR #%d: %s\nState: %s\nAuthor: @%s\nCreated: %s\nURL: %s\nReviewers: %s\nApprovers: %s\nActive subscriptions: %s",
+		"MR #%d: %s\nState: %s\nAuthor: @%s\nCreated: %s\nURL: %s\nPipeline: %s\nReviewers: %s\nApprovers: %s\nApproval rules: %s\nActive subscriptions: %s",
 		mr.IID,
 		mr.Title,
 		mr.State,
 		mr.Author.Username,
 		createdAt,
 		mr.WebURL,
+		pipeline,
 		strings.Join(reviewerNames, ", "),
 		strings.Join(approverNames, ", "),
+		approvalRules,
 		strings.Join(chatTitles, ", "))
 	c.sendReply(msg, info)
 }
 
-func (c *VKCommandConsumer) handleVacationCommand(msg *botgolang.Message, _ botgolang.Contact) {
+// handleWhyReviewerCommand explains how reviewers were selected for an MR.
+// Format: /why_reviewer <project_path!iid>
+func (c *VKCommandConsumer) handleWhyReviewerCommand(msg *botgolang.Message, _ botgolang.Contact) {
 	parts := strings.Fields(msg.Text)
 	if len(parts) < 2 {
-		c.sendReply(msg, "Usage: /vacation <username>")
+		c.sendReply(msg, "Usage: /why_reviewer <project_path!iid> (e.g., intdev/jobofferapp!2103)")
+		return
+	}
+	projectPath, mrIID, err := parseMRReference(parts[1])
+	if err != nil {
+		c.sendReply(msg, "Invalid reference format. Use <project_path!iid> (e.g., intdev/jobofferapp!2103)")
 		return
 	}
 
-	username := strings.TrimSpace(parts[1])
+	repo, err := utils.FindRepositoryByIdentifier(c.db, projectPath)
+	if err != nil {
+		c.sendReply(msg, "Repository not found for this reference.")
+		return
+	}
 
-	var user models.User
-	if err := c.db.Where("username = ?", username).First(&user).Error; err != nil {
-		c.sendReply(msg, fmt.Sprintf("User %s not found", username))
+	var mr models.MergeRequest
+	if err := c.db.Where("repository_id = ? AND i_id = ?", repo.ID, mrIID).First(&mr).Error; err != nil {
+		c.sendReply(msg, "Merge request not found in local database.")
 		return
 	}
 
-	user.OnVacation = !user.OnVacation
-	if err := c.db.Save(&user).Error; err != nil {
-		log.Printf("failed to update vacation status for user %s: %v", username, err)
-		c.sendReply(msg, "Failed to update vacation status")
+	var traces []models.ReviewerSelectionTrace
+	c.db.Where("merge_request_id = ?", mr.ID).Order("created_at").Find(&traces)
+	if len(traces) == 0 {
+		c.sendReply(msg, fmt.Sprintf("No selection trace recorded for %s!%d. Reviewers may have been assigned manually.", repo.PathWithNamespace, mr.IID))
 		return
 	}
 
-	status := "off vacation"
-	if user.OnVacation {
-		status = "on vacation"
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Reviewer selection for %s!%d:\n", repo.PathWithNamespace, mr.IID))
+	for _, t := range traces {
+		trace, err := decodeSelectionTrace(t.Trace)
+		if err != nil {
+			log.Printf("failed to decode selection trace %d: %v", t.ID, err)
+			continue
+		}
+		sb.WriteString("\n")
+		sb.WriteString(formatSelectionTrace(trace, t.CreatedAt))
 	}
-	c.sendReply(msg, fmt.Sprintf("User %s is now %s", username, status))
+	c.sendReply(msg, sb.String())
 }
 
-func (c *VKCommandConsumer) handleAssignCountCommand(msg *botgolang.Message, _ botgolang.Contact) {
+// handleCodeOwnersCommand toggles CODEOWNERS-aware reviewer selection for subscribed repositories.
+// Format: /codeowners [on [path]|off]
+// Without arguments the current setting is shown.
+func (c *VKCommandConsumer) handleCodeOwnersCommand(msg *botgolang.Message, _ botgolang.Contact) {
+	chatID := fmt.Sprint(msg.Chat.ID)
+	var chat models.Chat
+	if err := c.db.Where("chat_id = ?", chatID).First(&chat).Error; err != nil {
+		c.sendReply(msg, "Chat not found")
+		return
+	}
+
+	var subs []models.RepositorySubscription
+	c.db.Preload("Repository").Where("chat_id = ?", chat.ID).Find(&subs)
+	if len(subs) == 0 {
+		c.sendReply(msg, "No repository subscription found. Use /subscribe first.")
+		return
+	}
+
 	parts := strings.Fields(msg.Text)
 	if len(parts) < 2 {
-		c.sendReply(msg, "Usage: /assign_count <N>")
+		var lines []string
+		for _, sub := range subs {
+			var cfg models.CodeOwnersConfig
+			if err := c.db.Where("repository_id = ?", sub.RepositoryID).First(&cfg).Error; err != nil {
+				lines = append(lines, fmt.Sprintf("%s: off", sub.Repository.Name))
+				continue
+			}
+			location := cfg.FilePath
+			if location == "" {
+				location = strings.Join(utils.CodeOwnersPaths, ", ")
+			}
+			lines = append(lines, fmt.Sprintf("%s: on (%s)", sub.Repository.Name, location))
+		}
+		c.sendReply(msg, "CODEOWNERS selection:\n"+strings.Join(lines, "\n"))
+		return
+	}
+
+	mode := strings.ToLower(parts[1])
+	if mode != "on" && mode != "off" {
+		c.sendReply(msg, "Usage: /codeowners [on [path]|off]")
+		return
+	}
+	filePath := ""
+	if len(parts) > 2 {
+		filePath = strings.TrimPrefix(parts[2], "/")
+	}
+
+	var repoNames []string
+	for _, sub := range subs {
+		if mode == "off" {
+			if err := c.db.Unscoped().Where("repository_id = ?", sub.RepositoryID).Delete(&models.CodeOwnersConfig{}).Error; err != nil {
+				log.Printf("failed to disable codeowners for repo %d: %v", sub.RepositoryID, err)
+				continue
+			}
+		} else {
+			if err := c.db.Where(models.CodeOwnersConfig{RepositoryID: sub.RepositoryID}).
+				Assign(map[string]interface{}{"file_path": filePath}).
+				FirstOrCreate(&models.CodeOwnersConfig{}).Error; err != nil {
+				log.Printf("failed to enable codeowners for repo %d: %v", sub.RepositoryID, err)
+				continue
+			}
+		}
+		repoNames = append(repoNames, sub.Repository.Name)
+	}
+
+	c.sendReply(msg, fmt.Sprintf("CODEOWNERS selection %s for: %s", mode, strings.Join(repoNames, ", ")))
+}
+
+// handlePoolHealthCommand lists pool entries of subscribed repositories that can no longer be
+// assigned: blocked, deactivated or locked GitLab accounts and users who left the project.
+// Format: /pool_health
+func (c *VKCommandConsumer) handlePoolHealthCommand(msg *botgolang.Message, _ botgolang.Contact) {
+	chatID := fmt.Sprint(msg.Chat.ID)
+	var chat models.Chat
+	if err := c.db.Where("chat_id = ?", chatID).First(&chat).Error; err != nil {
+		c.sendReply(msg, "Chat not found")
+		return
+	}
+
+	var subs []models.RepositorySubscription
+	c.db.Preload("Repository").Where("chat_id = ?", chat.ID).Find(&subs)
+	if len(subs) == 0 {
+		c.sendReply(msg, "No repository subscription found. Use /subscribe first.")
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("POOL HEALTH\n")
+	for _, sub := range subs {
+		repoID := sub.RepositoryID
+		invalid := make(map[string][]string)
+		var poolNames []string
+		check := func(pool string, user models.User) {
+			if reason := utils.UserIneligibleReason(c.db, repoID, &user); reason != "" {
+				if _, ok := invalid[pool]; !ok {
+					poolNames = append(poolNames, pool)
+				}
+				invalid[pool] = append(invalid[pool], fmt.Sprintf("%s (%s)", user.Username, reason))
+			}
+		}
+
+		var reviewers []models.PossibleReviewer
+		c.db.Preload("User").Where("repository_id = ?", repoID).Find(&reviewers)
+		for _, r := range reviewers {
+			check("reviewers", r.User)
+		}
+		var labelReviewers []models.LabelReviewer
+		c.db.Preload("User").Where("repository_id = ?", repoID).Order("label_name").Find(&labelReviewers)
+		for _, lr := range labelReviewers {
+			check("label "+lr.LabelName, lr.User)
+		}
+		var pathReviewers []models.PathReviewer
+		c.db.Preload("User").Where("repository_id = ?", repoID).Order("pattern").Find(&pathReviewers)
+		for _, pr := range pathReviewers {
+			check("path "+pr.Pattern, pr.User)
+		}
+		var managers []models.ReleaseManager
+		c.db.Preload("User").Where("repository_id = ?", repoID).Find(&managers)
+		for _, m := range managers {
+			check("release managers", m.User)
+		}
+
+		sb.WriteString(fmt.Sprintf("\n%s:", sub.Repository.Name))
+		if sub.Repository.MembersSyncedAt == nil {
+			sb.WriteString(" (project membership not synced yet)")
+		}
+		if len(poolNames) == 0 {
+			sb.WriteString(" all pool entries valid\n")
+			continue
+		}
+		sb.WriteString("\n")
+		for _, pool := range poolNames {
+			sb.WriteString(fmt.Sprintf("- %s: %s\n", pool, strings.Join(invalid[pool], ", ")))
+		}
+	}
+
+	c.sendReply(msg, strings.TrimSuffix(sb.String(), "\n"))
+}
+
+// handleSizeCommand configures MR size handling for subscribed repositories.
+// Format: /size [labels on|off] or /size rule <min_lines> <count|off>
+// Without arguments the current labels setting and size rules are shown.
+func (c *VKCommandConsumer) handleSizeCommand(msg *botgolang.Message, _ botgolang.Contact) {
+	const usage = "Usage: /size labels on|off or /size rule <min_lines> <count|off>"
+	chatID := fmt.Sprint(msg.Chat.ID)
+	var chat models.Chat
+	if err := c.db.Where("chat_id = ?", chatID).First(&chat).Error; err != nil {
+		c.sendReply(msg, "Chat not found")
+		return
+	}
+
+	var subs []models.RepositorySubscription
+	c.db.Preload("Repository").Where("chat_id = ?", chat.ID).Find(&subs)
+	if len(subs) == 0 {
+		c.sendReply(msg, "No repository subscription found. Use /subscribe first.")
+		return
+	}
+
+	parts := strings.Fields(msg.Text)
+	if len(parts) < 2 {
+		var sb strings.Builder
+		sb.WriteString("MR size settings:\n")
+		for _, sub := range subs {
+			labels := "off"
+			var cfg models.SizeLabelConfig
+			if err := c.db.Where("repository_id = ?", sub.RepositoryID).First(&cfg).Error; err == nil {
+				labels = "on"
+			}
+			sb.WriteString(fmt.Sprintf("%s: size labels %s\n", sub.Repository.Name, labels))
+
+			var rules []models.SizeAssignRule
+			c.db.Where("repository_id = ?", sub.RepositoryID).Order("min_lines").Find(&rules)
+			for _, rule := range rules {
+				sb.WriteString(fmt.Sprintf("  ≥%d lines → %d reviewers\n", rule.MinLines, rule.AssignCount))
+			}
+		}
+		c.sendReply(msg, strings.TrimSuffix(sb.String(), "\n"))
+		return
+	}
+
+	var repoNames []string
+	switch strings.ToLower(parts[1]) {
+	case "labels":
+		if len(parts) < 3 || (strings.ToLower(parts[2]) != "on" && strings.ToLower(parts[2]) != "off") {
+			c.sendReply(msg, usage)
+			return
+		}
+		mode := strings.ToLower(parts[2])
+		for _, sub := range subs {
+			if mode == "off" {
+				if err := c.db.Unscoped().Where("repository_id = ?", sub.RepositoryID).Delete(&models.SizeLabelConfig{}).Error; err != nil {
+					log.Printf("failed to disable size labels for repo %d: %v", sub.RepositoryID, err)
+					continue
+				}
+			} else {
+				if err := c.db.Where(models.SizeLabelConfig{RepositoryID: sub.RepositoryID}).FirstOrCreate(&models.SizeLabelConfig{}).Error; err != nil {
+					log.Printf("failed to enable size labels for repo %d: %v", sub.RepositoryID, err)
+					continue
+				}
+			}
+			repoNames = append(repoNames, sub.Repository.Name)
+		}
+		c.sendReply(msg, fmt.Sprintf("Size labels %s for: %s", mode, strings.Join(repoNames, ", ")))
+
+	case "rule":
+		if len(parts) < 4 {
+			c.sendReply(msg, usage)
+			return
+		}
+		minLines, err := strconv.Atoi(parts[2])
+		if err != nil || minLines <= 0 {
+			c.sendReply(msg, "Line threshold must be a positive number")
+			return
+		}
+		count := 0
+		if strings.ToLower(parts[3]) != "off" {
+			count, err = strconv.Atoi(parts[3])
+			if err != nil || count <= 0 {
+				c.sendReply(msg, "Reviewer count must be a positive number or 'off'")
+				return
+			}
+		}
+
+		for _, sub := range subs {
+			if count == 0 {
+				if err := c.db.Unscoped().Where("repository_id = ? AND min_lines = ?", sub.RepositoryID, minLines).Delete(&models.SizeAssignRule{}).Error; err != nil {
+					log.Printf("failed to delete size rule for repo %d: %v", sub.RepositoryID, err)
+					continue
+				}
+			} else {
+				if err := c.db.Where(models.SizeAssignRule{RepositoryID: sub.RepositoryID, MinLines: minLines}).
+					Assign(map[string]interface{}{"assign_count": count}).
+					FirstOrCreate(&models.SizeAssignRule{}).Error; err != nil {
+					log.Printf("failed to save size rule for repo %d: %v", sub.RepositoryID, err)
+					continue
+				}
+			}
+			repoNames = append(repoNames, sub.Repository.Name)
+		}
+		if count == 0 {
+			c.sendReply(msg, fmt.Sprintf("Size rule ≥%d lines removed for: %s", minLines, strings.Join(repoNames, ", ")))
+		} else {
+			c.sendReply(msg, fmt.Sprintf("Size rule ≥%d lines → %d reviewers set for: %s", minLines, count, strings.Join(repoNames, ", ")))
+		}
+
+	default:
+		c.sendReply(msg, usage)
+	}
+}
+
+// handleStaleCommand configures stale MR detection for subscribed repositories.
+// Format: /stale <days|off>, /stale label on|off or /stale close_drafts <days|off>
+// Without arguments the current settings are shown.
+func (c *VKCommandConsumer) handleStaleCommand(msg *botgolang.Message, _ botgolang.Contact) {
+	const usage = "Usage: /stale <days|off>, /stale label on|off or /stale close_drafts <days|off>"
+	chatID := fmt.Sprint(msg.Chat.ID)
+	var chat models.Chat
+	if err := c.db.Where("chat_id = ?", chatID).First(&chat).Error; err != nil {
+		c.sendReply(msg, "Chat not found")
+		return
+	}
+
+	var subs []models.RepositorySubscription
+	c.db.Preload("Repository").Where("chat_id = ?", chat.ID).Find(&subs)
+	if len(subs) == 0 {
+		c.sendReply(msg, "No repository subscription found. Use /subscribe first.")
+		return
+	}
+
+	parts := strings.Fields(msg.Text)
+	if len(parts) < 2 {
+		var sb strings.Builder
+		sb.WriteString("Stale MR settings:\n")
+		for _, sub := range subs {
+			var cfg models.StaleConfig
+			if err := c.db.Where("repository_id = ?", sub.RepositoryID).First(&cfg).Error; err != nil {
+				sb.WriteString(fmt.Sprintf("%s: off\n", sub.Repository.Name))
+				continue
+			}
+			label := "off"
+			if cfg.ApplyLabel {
+				label = "on"
+			}
+			closeDrafts := "off"
+			if cfg.CloseDraftsAfterDays > 0 {
+				closeDrafts = fmt.Sprintf("%d working days after warning", cfg.CloseDraftsAfterDays)
+			}
+			sb.WriteString(fmt.Sprintf("%s: stale after %d working days, label %s, close drafts %s\n",
+				sub.Repository.Name, cfg.AfterDays, label, closeDrafts))
+		}
+		c.sendReply(msg, strings.TrimSuffix(sb.String(), "\n"))
+		return
+	}
+
+	var repoNames []string
+	arg := strings.ToLower(parts[1])
+	switch arg {
+	case "off":
+		for _, sub := range subs {
+			if err := c.db.Unscoped().Where("repository_id = ?", sub.RepositoryID).Delete(&models.StaleConfig{}).Error; err != nil {
+				log.Printf("failed to disable stale detection for repo %d: %v", sub.RepositoryID, err)
+				continue
+			}
+			repoNames = append(repoNames, sub.Repository.Name)
+		}
+		c.sendReply(msg, "Stale MR detection disabled for: "+strings.Join(repoNames, ", "))
+
+	case "label":
+		if len(parts) < 3 || (strings.ToLower(parts[2]) != "on" && strings.ToLower(parts[2]) != "off") {
+			c.sendReply(msg, usage)
+			return
+		}
+		mode := strings.ToLower(parts[2])
+		repoNames = c.updateStaleConfigs(subs, map[string]interface{}{"apply_label": mode == "on"})
+		c.sendReply(msg, fmt.Sprintf("Stale label %s for: %s", mode, strings.Join(repoNames, ", ")))
+
+	case "close_drafts":
+		if len(parts) < 3 {
+			c.sendReply(msg, usage)
+			return
+		}
+		days := 0
+		if strings.ToLower(parts[2]) != "off" {
+			value, err := strconv.Atoi(parts[2])
+			if err != nil || value <= 0 {
+				c.sendReply(msg, "Days must be a positive number or 'off'")
+				return
+			}
+			days = value
+		}
+		repoNames = c.updateStaleConfigs(subs, map[string]interface{}{"close_drafts_after_days": days})
+		if days == 0 {
+			c.sendReply(msg, "Stale drafts will not be closed for: "+strings.Join(repoNames, ", "))
+		} else {
+			c.sendReply(msg, fmt.Sprintf("Stale drafts will be closed %d working days after the warning for: %s", days, strings.Join(repoNames, ", ")))
+		}
+
+	default:
+		days, err := strconv.Atoi(arg)
+		if err != nil || days <= 0 {
+			c.sendReply(msg, usage)
+			return
+		}
+		repoNames = c.updateStaleConfigs(subs, map[string]interface{}{"after_days": days})
+		c.sendReply(msg, fmt.Sprintf("MRs without activity for %d working days are stale for: %s", days, strings.Join(repoNames, ", ")))
+	}
+}
+
+// handleAutoMergeCommand configures auto-merge for subscribed repositories.
+// Format: /auto_merge <label> or /auto_merge off. Without arguments the current settings are shown.
+func (c *VKCommandConsumer) handleAutoMergeCommand(msg *botgolang.Message, _ botgolang.Contact) {
+	chatID := fmt.Sprint(msg.Chat.ID)
+	var chat models.Chat
+	if err := c.db.Where("chat_id = ?", chatID).First(&chat).Error; err != nil {
+		c.sendReply(msg, "Chat not found")
+		return
+	}
+
+	var subs []models.RepositorySubscription
+	c.db.Preload("Repository").Where("chat_id = ?", chat.ID).Find(&subs)
+	if len(subs) == 0 {
+		c.sendReply(msg, "No repository subscription found. Use /subscribe first.")
+		return
+	}
+
+	parts := strings.Fields(msg.Text)
+	if len(parts) < 2 {
+		var sb strings.Builder
+		sb.WriteString("Auto-merge settings:\n")
+		for _, sub := range subs {
+			var cfg models.AutoMergeConfig
+			if err := c.db.Where("repository_id = ?", sub.RepositoryID).First(&cfg).Error; err != nil {
+				sb.WriteString(fmt.Sprintf("%s: off\n", sub.Repository.Name))
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("%s: MRs labeled %q\n", sub.Repository.Name, cfg.LabelName))
+		}
+		sb.WriteString("Usage: /auto_merge <label> or /auto_merge off")
+		c.sendReply(msg, sb.String())
+		return
+	}
+
+	var repoNames []string
+	if strings.ToLower(parts[1]) == "off" {
+		for _, sub := range subs {
+			if err := c.db.Unscoped().Where("repository_id = ?", sub.RepositoryID).Delete(&models.AutoMergeConfig{}).Error; err != nil {
+				log.Printf("failed to disable auto-merge for repo %d: %v", sub.RepositoryID, err)
+				continue
+			}
+			repoNames = append(repoNames, sub.Repository.Name)
+		}
+		c.sendReply(msg, "Auto-merge disabled for: "+strings.Join(repoNames, ", "))
+		return
+	}
+
+	label := parts[1]
+	for _, sub := range subs {
+		var cfg models.AutoMergeConfig
+		if err := c.db.Where(models.AutoMergeConfig{RepositoryID: sub.RepositoryID}).
+			Assign(models.AutoMergeConfig{LabelName: label}).
+			FirstOrCreate(&cfg).Error; err != nil {
+			log.Printf("failed to save auto-merge config for repo %d: %v", sub.RepositoryID, err)
+			continue
+		}
+		repoNames = append(repoNames, sub.Repository.Name)
+	}
+	c.sendReply(msg, fmt.Sprintf("Fully approved MRs labeled %q with a green pipeline and no open threads will be merged automatically for: %s",
+		label, strings.Join(repoNames, ", ")))
+}
+
+// handleAutoRebaseCommand configures automatic rebases for subscribed repositories.
+// Format: /auto_rebase on [max_per_hour] or /auto_rebase off. Without arguments the current settings are shown.
+func (c *VKCommandConsumer) handleAutoRebaseCommand(msg *botgolang.Message, _ botgolang.Contact) {
+	const usage = "Usage: /auto_rebase on [max_per_hour] or /auto_rebase off"
+	chatID := fmt.Sprint(msg.Chat.ID)
+	var chat models.Chat
+	if err := c.db.Where("chat_id = ?", chatID).First(&chat).Error; err != nil {
+		c.sendReply(msg, "Chat not found")
+		return
+	}
+
+	var subs []models.RepositorySubscription
+	c.db.Preload("Repository").Where("chat_id = ?", chat.ID).Find(&subs)
+	if len(subs) == 0 {
+		c.sendReply(msg, "No repository subscription found. Use /subscribe first.")
+		return
+	}
+
+	parts := strings.Fields(msg.Text)
+	if len(parts) < 2 {
+		var sb strings.Builder
+		sb.WriteString("Auto-rebase settings:\n")
+		for _, sub := range subs {
+			var cfg models.AutoRebaseConfig
+			if err := c.db.Where("repository_id = ?", sub.RepositoryID).First(&cfg).Error; err != nil {
+				sb.WriteString(fmt.Sprintf("%s: off\n", sub.Repository.Name))
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("%s: on, at most %d rebases per hour\n", sub.Repository.Name, cfg.MaxPerHour))
+		}
+		sb.WriteString(usage)
+		c.sendReply(msg, sb.String())
+		return
+	}
+
+	var repoNames []string
+	switch strings.ToLower(parts[1]) {
+	case "off":
+		for _, sub := range subs {
+			if err := c.db.Unscoped().Where("repository_id = ?", sub.RepositoryID).Delete(&models.AutoRebaseConfig{}).Error; err != nil {
+				log.Printf("failed to disable auto-rebase for repo %d: %v", sub.RepositoryID, err)
+				continue
+			}
+			repoNames = append(repoNames, sub.Repository.Name)
+		}
+		c.sendReply(msg, "Auto-rebase disabled for: "+strings.Join(repoNames, ", "))
+
+	case "on":
+		maxPerHour := 5
+		if len(parts) > 2 {
+			value, err := strconv.Atoi(parts[2])
+			if err != nil || value <= 0 {
+				c.sendReply(msg, "Max rebases per hour must be a positive number")
+				return
+			}
+			maxPerHour = value
+		}
+		for _, sub := range subs {
+			var cfg models.AutoRebaseConfig
+			if err := c.db.Where(models.AutoRebaseConfig{RepositoryID: sub.RepositoryID}).
+				Assign(models.AutoRebaseConfig{MaxPerHour: maxPerHour}).
+				FirstOrCreate(&cfg).Error; err != nil {
+				log.Printf("failed to save auto-rebase config for repo %d: %v", sub.RepositoryID, err)
+				continue
+			}
+			repoNames = append(repoNames, sub.Repository.Name)
+		}
+		c.sendReply(msg, fmt.Sprintf("MRs behind their target branch without conflicts will be rebased (at most %d per hour) for: %s",
+			maxPerHour, strings.Join(repoNames, ", ")))
+
+	default:
+		c.sendReply(msg, usage)
+	}
+}
+
+// updateStaleConfigs enables stale detection for the subscribed repositories and applies updates.
+// Returns the names of the repositories that were updated.
+func (c *VKCommandConsumer) updateStaleConfigs(subs []models.RepositorySubscription, updates map[string]interface{}) []string {
+	var repoNames []string
+	for _, sub := range subs {
+		var cfg models.StaleConfig
+		if err := c.db.Where(models.StaleConfig{RepositoryID: sub.RepositoryID}).FirstOrCreate(&cfg).Error; err != nil {
+			log.Printf("failed to get/create stale config for repo %d: %v", sub.RepositoryID, err)
+			continue
+		}
+		if err := c.db.Model(&cfg).Updates(updates).Error; err != nil {
+			log.Printf("failed to save stale config for repo %d: %v", sub.RepositoryID, err)
+			continue
+		}
+		repoNames = append(repoNames, sub.Repository.Name)
+	}
+	return repoNames
+}
+
+// handleReassignCommand replaces a reviewer on an MR.
+// Format: /reassign <project_path!iid> [reviewer] [@replacement]
+// Without a reviewer the sender's own review is reassigned (or the only reviewer's).
+// Without @replacement a new reviewer is picked by weighted selection from the pools.
+func (c *VKCommandConsumer) handleReassignCommand(msg *botgolang.Message, from botgolang.Contact) {
+	const usage = "Usage: /reassign <project_path!iid> [reviewer] [@replacement]"
+	parts := strings.Fields(msg.Text)
+	if len(parts) < 2 {
+		c.sendReply(msg, usage)
+		return
+	}
+	projectPath, mrIID, err := parseMRReference(parts[1])
+	if err != nil {
+		c.sendReply(msg, "Invalid reference format. Use <project_path!iid> (e.g., intdev/jobofferapp!2103)")
+		return
+	}
+
+	var removedName, replacementName string
+	for _, arg := range parts[2:] {
+		if strings.HasPrefix(arg, "@") {
+			replacementName = strings.TrimPrefix(arg, "@")
+		} else if removedName == "" {
+			removedName = arg
+		} else {
+			c.sendReply(msg, usage)
+			return
+		}
+	}
+
+	repo, err := utils.FindRepositoryByIdentifier(c.db, projectPath)
+	if err != nil {
+		c.sendReply(msg, "Repository not found for this reference.")
+		return
+	}
+
+	var mr models.MergeRequest
+	if err := c.db.Preload("Repository").Preload("Author").Preload("Labels").Preload("Reviewers").
+		Where("repository_id = ? AND i_id = ?", repo.ID, mrIID).First(&mr).Error; err != nil {
+		c.sendReply(msg, "Merge request not found in local database.")
+		return
+	}
+	if mr.State != "opened" {
+		c.sendReply(msg, "Merge request is not open.")
+		return
+	}
+	if len(mr.Reviewers) == 0 {
+		c.sendReply(msg, "Merge request has no reviewers to reassign.")
+		return
+	}
+
+	sender := c.findLinkedUser(from)
+
+	if removedName == "" && sender != nil && containsUser(mr.Reviewers, sender.ID) {
+		removedName = sender.Username
+	}
+	if removedName == "" && len(mr.Reviewers) == 1 {
+		removedName = mr.Reviewers[0].Username
+	}
+	if removedName == "" {
+		c.sendReply(msg, "Multiple reviewers assigned. Please specify which one to replace. "+usage)
+		return
+	}
+
+	var removed *models.User
+	for i := range mr.Reviewers {
+		if mr.Reviewers[i].Username == removedName {
+			removed = &mr.Reviewers[i]
+			break
+		}
+	}
+	if removed == nil {
+		c.sendReply(msg, fmt.Sprintf("%s is not a reviewer of %s!%d", removedName, repo.PathWithNamespace, mr.IID))
+		return
+	}
+
+	var replacement *models.User
+	if replacementName != "" {
+		var user models.User
+		if err := c.db.Where("username = ?", replacementName).First(&user).Error; err != nil {
+			c.sendReply(msg, fmt.Sprintf("User %s not found", replacementName))
+			return
+		}
+		replacement = &user
+	}
+
+	var actorID *uint
+	if sender != nil {
+		actorID = &sender.ID
+	}
+
+	reviewerConsumer := NewMRReviewerConsumer(c.db, c.vkBot, c.glClient, 0, nil)
+	newReviewer, err := reviewerConsumer.ReassignReviewer(&mr, *removed, replacement, actorID)
+	if err != nil {
+		log.Printf("failed to reassign reviewer on MR %d: %v", mr.ID, err)
+		c.sendReply(msg, fmt.Sprintf("Failed to reassign: %v", err))
+		return
+	}
+
+	c.sendReply(msg, fmt.Sprintf("Reassigned %s!%d: %s → %s", repo.PathWithNamespace, mr.IID, removed.Username, newReviewer.Username))
+}
+
+// findLinkedUser resolves the GitLab user linked to the sender's VK account, or nil.
+func (c *VKCommandConsumer) findLinkedUser(from botgolang.Contact) *models.User {
+	var vkUser models.VKUser
+	if err := c.db.Where("user_id = ?", fmt.Sprint(from.ID)).First(&vkUser).Error; err != nil {
+		return nil
+	}
+	var user models.User
+	if err := c.db.Where("email = ?", vkUser.UserID).First(&user).Error; err != nil {
+		return nil
+	}
+	return &user
+}
+
+func containsUser(users []models.User, userID uint) bool {
+	for _, u := range users {
+		if u.ID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// parseMRReference splits a <project_path!iid> reference into project path and IID.
+func parseMRReference(ref string) (string, int, error) {
+	ref = strings.TrimSpace(ref)
+	bangIdx := strings.LastIndex(ref, "!")
+	if bangIdx <= 0 || bangIdx == len(ref)-1 {
+		return "", 0, fmt.Errorf("invalid reference: %s", ref)
+	}
+	iid, err := strconv.Atoi(ref[bangIdx+1:])
+	if err != nil || iid <= 0 {
+		return "", 0, fmt.Errorf("invalid MR IID in reference: %s", ref)
+	}
+	return ref[:bangIdx], iid, nil
+}
+
+// handleWorkloadCommand shows review load of every reviewer pool member.
+// Format: /workload [repo|chat]
+// Without arguments (or with "chat") pools of all repositories subscribed in this chat are merged.
+func (c *VKCommandConsumer) handleWorkloadCommand(msg *botgolang.Message, _ botgolang.Contact) {
+	argStr := strings.TrimSpace(strings.TrimPrefix(msg.Text, "/workload"))
+
+	var repoIDs []uint
+	var scopeName string
+	if argStr == "" || argStr == "chat" {
+		chatID := fmt.Sprint(msg.Chat.ID)
+		var chat models.Chat
+		if err := c.db.Where("chat_id = ?", chatID).First(&chat).Error; err != nil {
+			c.sendReply(msg, "Chat not found")
+			return
+		}
+
+		var subs []models.RepositorySubscription
+		c.db.Preload("Repository").Where("chat_id = ?", chat.ID).Find(&subs)
+		if len(subs) == 0 {
+			c.sendReply(msg, "No repository subscription found. Use /subscribe first.")
+			return
+		}
+
+		repoNames := make([]string, len(subs))
+		for i, s := range subs {
+			repoIDs = append(repoIDs, s.RepositoryID)
+			repoNames[i] = s.Repository.Name
+		}
+		scopeName = strings.Join(repoNames, ", ")
+	} else {
+		repo, err := utils.FindRepositoryByIdentifier(c.db, argStr)
+		if err != nil {
+			c.sendReply(msg, fmt.Sprintf("Repository not found: %s", argStr))
+			return
+		}
+		repoIDs = []uint{repo.ID}
+		scopeName = repo.Name
+	}
+
+	var possibleReviewers []models.PossibleReviewer
+	c.db.Preload("User").Where("repository_id IN ?", repoIDs).Find(&possibleReviewers)
+	var labelReviewers []models.LabelReviewer
+	c.db.Preload("User").Where("repository_id IN ?", repoIDs).Find(&labelReviewers)
+
+	if len(possibleReviewers) == 0 && len(labelReviewers) == 0 {
+		c.sendReply(msg, fmt.Sprintf("No reviewer pools configured for %s.", scopeName))
+		return
+	}
+
+	allUserIDs := make(map[uint]bool)
+	var defaultPool []models.User
+	for _, pr := range possibleReviewers {
+		if !allUserIDs[pr.UserID] {
+			defaultPool = append(defaultPool, pr.User)
+		}
+		allUserIDs[pr.UserID] = true
+	}
+
+	labelPools := make(map[string][]models.User)
+	labelSeen := make(map[string]map[uint]bool)
+	for _, lr := range labelReviewers {
+		if labelSeen[lr.LabelName] == nil {
+			labelSeen[lr.LabelName] = make(map[uint]bool)
+		}
+		if labelSeen[lr.LabelName][lr.UserID] {
+			continue
+		}
+		labelSeen[lr.LabelName][lr.UserID] = true
+		labelPools[lr.LabelName] = append(labelPools[lr.LabelName], lr.User)
+		allUserIDs[lr.UserID] = true
+	}
+
+	userIDs := make([]uint, 0, len(allUserIDs))
+	for id := range allUserIDs {
+		userIDs = append(userIDs, id)
+	}
+
+	openCounts, oldest, err := utils.GetOpenReviewStats(c.db, userIDs)
+	if err != nil {
+		log.Printf("failed to fetch open review stats: %v", err)
+		c.sendReply(msg, "Failed to fetch workload. Please try again later.")
+		return
+	}
+	recentCounts, err := utils.GetRecentReviewCounts(c.db, userIDs)
+	if err != nil {
+		log.Printf("failed to fetch recent review counts: %v", err)
+		c.sendReply(msg, "Failed to fetch workload. Please try again later.")
+		return
+	}
+
+	repoLimit := strictestCapacity(c.db, repoIDs)
+	buildLoad := func(users []models.User) []utils.ReviewerLoad {
+		loads := utils.BuildPoolLoad(users, openCounts, oldest, recentCounts)
+		for i := range loads {
+			loads[i].Capacity = utils.EffectiveCapacity(loads[i].User, repoLimit)
+		}
+		return loads
+	}
+
+	now := time.Now()
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("WORKLOAD: %s\n", scopeName))
+	if len(defaultPool) > 0 {
+		sb.WriteString("\nDefault pool:\n")
+		sb.WriteString(formatWorkloadSection(buildLoad(defaultPool), now))
+	}
+
+	labelNames := make([]string, 0, len(labelPools))
+	for label := range labelPools {
+		labelNames = append(labelNames, label)
+	}
+	sort.Strings(labelNames)
+	for _, label := range labelNames {
+		sb.WriteString(fmt.Sprintf("\nLabel '%s':\n", label))
+		sb.WriteString(formatWorkloadSection(buildLoad(labelPools[label]), now))
+	}
+
+	c.sendReply(msg, sb.String())
+}
+
+// defaultSimulationDays is the replay window of /simulate without a days argument.
+const defaultSimulationDays = 30
+
+// handleSimulateCommand replays recent MRs of subscribed repositories through reviewer
+// selection without assigning anyone and reports load, fairness and unfilled label groups.
+// Format: /simulate [days] [strategy] [count=N]
+func (c *VKCommandConsumer) handleSimulateCommand(msg *botgolang.Message, _ botgolang.Contact) {
+	days, opts, err := parseSimulateArgs(strings.Fields(strings.TrimPrefix(msg.Text, "/simulate")))
+	if err != nil {
+		c.sendReply(msg, fmt.Sprintf("%v\nUsage: /simulate [days] [%s] [count=N]", err, strings.Join(ReviewerStrategies, "|")))
+		return
+	}
+
+	chatID := fmt.Sprint(msg.Chat.ID)
+	var chat models.Chat
+	if err := c.db.Where("chat_id = ?", chatID).First(&chat).Error; err != nil {
+		c.sendReply(msg, "Chat not found")
+		return
+	}
+
+	var subs []models.RepositorySubscription
+	c.db.Preload("Repository").Where("chat_id = ?", chat.ID).Find(&subs)
+	if len(subs) == 0 {
+		c.sendReply(msg, "No repository subscription found. Use /subscribe first.")
+		return
+	}
+
+	opts.Since = time.Now().AddDate(0, 0, -days)
+	simulator := NewMRReviewerConsumerWithServices(c.db, nil, nil, nil, 0, nil)
+
+	var reports []string
+	for _, sub := range subs {
+		report, err := simulator.SimulateAssignments(sub.RepositoryID, opts)
+		if err != nil {
+			log.Printf("failed to simulate assignments for repo %d: %v", sub.RepositoryID, err)
+			reports = append(reports, fmt.Sprintf("%s: simulation failed", sub.Repository.Name))
+			continue
+		}
+		reports = append(reports, FormatSimulationReport(report, opts.Since))
+	}
+
+	c.sendReply(msg, strings.Join(reports, "\n"))
+}
+
+// parseSimulateArgs parses /simulate arguments in any order: a number of days,
+// a strategy name and count=N.
+func parseSimulateArgs(args []string) (int, SimulationOptions, error) {
+	days := defaultSimulationDays
+	var opts SimulationOptions
+	for _, arg := range args {
+		lower := strings.ToLower(arg)
+		switch {
+		case strings.HasPrefix(lower, "count="):
+			count, err := strconv.Atoi(strings.TrimPrefix(lower, "count="))
+			if err != nil || count < 1 {
+				return 0, opts, fmt.Errorf("invalid count: %s", arg)
+			}
+			opts.AssignCount = count
+		case IsValidReviewerStrategy(lower):
+			opts.Strategy = lower
+		default:
+			value, err := strconv.Atoi(strings.TrimSuffix(lower, "d"))
+			if err != nil || value < 1 {
+				return 0, opts, fmt.Errorf("unknown argument: %s", arg)
+			}
+			days = value
+		}
+	}
+	return days, opts, nil
+}
+
+// formatWorkloadSection renders one pool's load entries, one line per reviewer.
+func formatWorkloadSection(loads []utils.ReviewerLoad, now time.Time) string {
+	var sb strings.Builder
+	for _, l := range loads {
+		oldestStr := "-"
+		if l.OldestPending != nil {
+			oldestStr = utils.FormatDuration(now.Sub(*l.OldestPending))
+		}
+		vacation := ""
+		if l.User.OnVacation {
+			vacation = " [vacation]"
+		}
+		openStr := fmt.Sprint(l.OpenReviews)
+		full := ""
+		if l.Capacity > 0 {
+			openStr = fmt.Sprintf("%d/%d", l.OpenReviews, l.Capacity)
+			if l.OpenReviews >= l.Capacity {
+				full = " [full]"
+			}
+		}
+		sb.WriteString(fmt.Sprintf("- %s%s%s: open %s, oldest %s, recent %d, weight %.0f%%\n",
+			l.User.Username, vacation, full, openStr, oldestStr, l.RecentCount, l.Weight*100))
+	}
+	return sb.String()
+}
+
+// strictestCapacity returns the lowest non-zero repository review capacity among repoIDs (0 = unlimited).
+func strictestCapacity(db *gorm.DB, repoIDs []uint) int {
+	var limits []int
+	db.Model(&models.RepositorySLA{}).
+		Where("repository_id IN ? AND max_open_reviews > 0", repoIDs).
+		Order("max_open_reviews").
+		Pluck("max_open_reviews", &limits)
+	if len(limits) == 0 {
+		return 0
+	}
+	return limits[0]
+}
+
+// handleCapacityCommand configures the max number of concurrent open reviews per reviewer.
+// Format: /capacity [repo <n|off>] | [username <n|off>]
+// The repo limit applies to subscribed repositories; a user limit overrides it everywhere.
+// Reviewers at capacity are skipped by selection.
+func (c *VKCommandConsumer) handleCapacityCommand(msg *botgolang.Message, _ botgolang.Contact) {
+	chatID := fmt.Sprint(msg.Chat.ID)
+	var chat models.Chat
+	if err := c.db.Where("chat_id = ?", chatID).First(&chat).Error; err != nil {
+		c.sendReply(msg, "Chat not found")
+		return
+	}
+
+	var subs []models.RepositorySubscription
+	c.db.Preload("Repository").Where("chat_id = ?", chat.ID).Find(&subs)
+	if len(subs) == 0 {
+		c.sendReply(msg, "No repository subscription found. Use /subscribe first.")
+		return
+	}
+
+	parts := strings.Fields(msg.Text)
+
+	if len(parts) < 2 {
+		var lines []string
+		for _, sub := range subs {
+			sla, err := utils.GetRepositorySLA(c.db, sub.RepositoryID)
+			if err != nil {
+				log.Printf("failed to get SLA for repo %d: %v", sub.RepositoryID, err)
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("%s: %s", sub.Repository.Name, formatCapacity(sla.MaxOpenReviews)))
+		}
+
+		var users []models.User
+		c.db.Where("max_open_reviews > 0").Order("username").Find(&users)
+		if len(users) > 0 {
+			lines = append(lines, "", "User limits:")
+			for _, u := range users {
+				lines = append(lines, fmt.Sprintf("%s: %s", u.Username, formatCapacity(u.MaxOpenReviews)))
+			}
+		}
+		c.sendReply(msg, "Review capacity:\n"+strings.Join(lines, "\n"))
+		return
+	}
+
+	if len(parts) < 3 {
+		c.sendReply(msg, "Usage: /capacity repo <n|off> or /capacity <username> <n|off>")
+		return
+	}
+
+	limit := 0
+	if strings.ToLower(parts[2]) != "off" {
+		value, err := strconv.Atoi(parts[2])
+		if err != nil || value <= 0 {
+			c.sendReply(msg, "Capacity must be a positive number or 'off'")
+			return
+		}
+		limit = value
+	}
+
+	if strings.ToLower(parts[1]) == "repo" {
+		var repoNames []string
+		for _, sub := range subs {
+			var sla models.RepositorySLA
+			if err := c.db.Where(models.RepositorySLA{RepositoryID: sub.RepositoryID}).FirstOrCreate(&sla).Error; err != nil {
+				log.Printf("failed to get/create SLA for repo %d: %v", sub.RepositoryID, err)
+				continue
+			}
+			sla.MaxOpenReviews = limit
+			if err := c.db.Save(&sla).Error; err != nil {
+				log.Printf("failed to save SLA for repo %d: %v", sub.RepositoryID, err)
+				continue
+			}
+			repoNames = append(repoNames, sub.Repository.Name)
+		}
+		c.sendReply(msg, fmt.Sprintf("Review capacity set to %s for: %s", formatCapacity(limit), strings.Join(repoNames, ", ")))
+		return
+	}
+
+	username := strings.TrimPrefix(parts[1], "@")
+	var user models.User
+	if err := c.db.Where("username = ?", username).First(&user).Error; err != nil {
+		c.sendReply(msg, fmt.Sprintf("User %s not found", username))
+		return
+	}
+
+	if err := c.db.Model(&user).Update("max_open_reviews", limit).Error; err != nil {
+		log.Printf("failed to update capacity for %s: %v", username, err)
+		c.sendReply(msg, "Failed to update capacity. Please try again later.")
+		return
+	}
+
+	if limit == 0 {
+		c.sendReply(msg, fmt.Sprintf("%s now uses the repository capacity", username))
+		return
+	}
+	c.sendReply(msg, fmt.Sprintf("%s capacity set to %s", username, formatCapacity(limit)))
+}
+
+func formatCapacity(limit int) string {
+	if limit <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%d open reviews", limit)
+}
+
+func (c *VKCommandConsumer) handleVacationCommand(msg *botgolang.Message, _ botgolang.Contact) {
+	parts := strings.Fields(msg.Text)
+	if len(parts) < 2 {
+		c.sendReply(msg, "Usage: /vacation <username>")
+		return
+	}
+
+	username := strings.TrimSpace(parts[1])
+
+	var user models.User
+	if err := c.db.Where("username = ?", username).First(&user).Error; err != nil {
+		c.sendReply(msg, fmt.Sprintf("User %s not found", username))
+		return
+	}
+
+	user.OnVacation = !user.OnVacation
+	if err := c.db.Save(&user).Error; err != nil {
+		log.Printf("failed to update vacation status for user %s: %v", username, err)
+		c.sendReply(msg, "Failed to update vacation status")
+		return
+	}
+
+	status := "off vacation"
+	if user.OnVacation {
+		status = "on vacation"
+	}
+	c.sendReply(msg, fmt.Sprintf("User %s is now %s", username, status))
+}
+
+func (c *VKCommandConsumer) handleAssignCountCommand(msg *botgolang.Message, _ botgolang.Contact) {
+	parts := strings.Fields(msg.Text)
+	if len(parts) < 2 {
+		c.sendReply(msg, "Usage: /assign_count <N>")
+		return
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil || count < 1 {
+		c.sendReply(msg, "Invalid count. Must be a positive integer.")
+		return
+	}
+
+	chatID := fmt.Sprint(msg.Chat.ID)
+	var chat models.Chat
+	if err := c.db.Where("chat_id = ?", chatID).First(&chat).Error; err != nil {
+		c.sendReply(msg, "Chat not found")
+		return
+	}
+
+	var subs []models.RepositorySubscription
+	c.db.Preload("Repository").Where("chat_id = ?", chat.ID).Find(&subs)
+	if len(subs) == 0 {
+		c.sendReply(msg, "No repository subscription found. Use /subscribe first.")
 		return
 	}
 
-	count, err := strconv.Atoi(strings.TrimSpace(parts[1]))
-	if err != nil || count < 1 {
-		c.sendReply(msg, "Invalid count. Must be a positive integer.")
-		return
+	repoNames := make([]string, 0, len(subs))
+	for _, sub := range subs {
+		var sla models.RepositorySLA
+		if err := c.db.Where(models.RepositorySLA{RepositoryID: sub.RepositoryID}).
+			Assign(models.RepositorySLA{AssignCount: count}).
+			FirstOrCreate(&sla).Error; err != nil {
+			log.Printf("failed to set assign count for repo %d: %v", sub.RepositoryID, err)
+		}
+		repoNames = append(repoNames, sub.Repository.Name)
 	}
 
+	c.sendReply(msg, fmt.Sprintf("Assign count set to %d for: %s", count, strings.Join(repoNames, ", ")))
+}
+
+// handleReviewerStrategyCommand shows or sets the reviewer selection strategy of subscribed repositories.
+// Format: /reviewer_strategy [weighted|round_robin|least_open|expertise]
+func (c *VKCommandConsumer) handleReviewerStrategyCommand(msg *botgolang.Message, _ botgolang.Contact) {
 	chatID := fmt.Sprint(msg.Chat.ID)
 	var chat models.Chat
 	if err := c.db.Where("chat_id = ?", chatID).First(&chat).Error; err != nil {
@@ -788,18 +2692,40 @@ func (c *VKCommandConsumer) handleAssignCountCommand(msg *botgolang.Message, _ b
 		return
 	}
 
+	parts := strings.Fields(msg.Text)
+	if len(parts) < 2 {
+		var lines []string
+		for _, sub := range subs {
+			strategy := StrategyWeighted
+			if sla, err := utils.GetRepositorySLA(c.db, sub.RepositoryID); err == nil && sla.ReviewerStrategy != "" {
+				strategy = sla.ReviewerStrategy
+			}
+			lines = append(lines, fmt.Sprintf("%s: %s", sub.Repository.Name, strategy))
+		}
+		c.sendReply(msg, fmt.Sprintf("Reviewer strategy:\n%s\nAvailable: %s",
+			strings.Join(lines, "\n"), strings.Join(ReviewerStrategies, ", ")))
+		return
+	}
+
+	strategy := strings.ToLower(parts[1])
+	if !IsValidReviewerStrategy(strategy) {
+		c.sendReply(msg, fmt.Sprintf("Unknown strategy: %s. Available: %s", parts[1], strings.Join(ReviewerStrategies, ", ")))
+		return
+	}
+
 	repoNames := make([]string, 0, len(subs))
 	for _, sub := range subs {
 		var sla models.RepositorySLA
 		if err := c.db.Where(models.RepositorySLA{RepositoryID: sub.RepositoryID}).
-			Assign(models.RepositorySLA{AssignCount: count}).
+			Assign(models.RepositorySLA{ReviewerStrategy: strategy}).
 			FirstOrCreate(&sla).Error; err != nil {
-			log.Printf("failed to set assign count for repo %d: %v", sub.RepositoryID, err)
+			log.Printf("failed to set reviewer strategy for repo %d: %v", sub.RepositoryID, err)
+			continue
 		}
 		repoNames = append(repoNames, sub.Repository.Name)
 	}
 
-	c.sendReply(msg, fmt.Sprintf("Assign count set to %d for: %s", count, strings.Join(repoNames, ", ")))
+	c.sendReply(msg, fmt.Sprintf("Reviewer strategy set to %s for: %s", strategy, strings.Join(repoNames, ", ")))
 }
 
 func (c *VKCommandConsumer) handleHolidaysCommand(msg *botgolang.Message, _ botgolang.Contact) {
@@ -939,11 +2865,22 @@ func (c *VKCommandConsumer) handleSLACommand(msg *botgolang.Message, _ botgolang
 			if err := c.db.Where("repository_id = ?", sub.RepositoryID).First(&sla).Error; err != nil {
 				lines = append(lines, fmt.Sprintf("%s: not configured", sub.Repository.Name))
 			} else {
-				lines = append(lines, fmt.Sprintf("%s: review=%s, fixes=%s, assign_count=%d",
+				waitPipeline := "off"
+				if sla.WaitForPipeline {
+					waitPipeline = "on"
+				}
+				drafts := "defer"
+				if sla.AssignDrafts {
+					drafts = "assign"
+				}
+				lines = append(lines, fmt.Sprintf("%s: review=%s, fixes=%s, assign_count=%d, reassign=%s, wait_pipeline=%s, drafts=%s",
 					sub.Repository.Name,
 					formatSLADuration(sla.ReviewDuration.ToDuration()),
 					formatSLADuration(sla.FixesDuration.ToDuration()),
-					sla.AssignCount))
+					sla.AssignCount,
+					formatReassignSetting(sla.ReassignThreshold, sla.MaxAutoReassigns),
+					waitPipeline,
+					drafts))
 			}
 		}
 		c.sendReply(msg, "SLA Settings:\n"+strings.Join(lines, "\n"))
@@ -951,13 +2888,25 @@ func (c *VKCommandConsumer) handleSLACommand(msg *botgolang.Message, _ botgolang
 	}
 
 	if len(parts) < 3 {
-		c.sendReply(msg, "Usage: /sla review <duration> or /sla fixes <duration>\nDuration format: 1h, 2d, 1w")
+		c.sendReply(msg, "Usage: /sla review <duration>, /sla fixes <duration>, /sla reassign <percent|off> [max], /sla pipeline <on|off> or /sla drafts <assign|defer>\nDuration format: 1h, 2d, 1w")
 		return
 	}
 
 	slaType := strings.ToLower(parts[1])
+	if slaType == "reassign" {
+		c.handleSLAReassign(msg, subs, parts[2:])
+		return
+	}
+	if slaType == "pipeline" {
+		c.handleSLAPipeline(msg, subs, parts[2])
+		return
+	}
+	if slaType == "drafts" {
+		c.handleSLADrafts(msg, subs, parts[2])
+		return
+	}
 	if slaType != "review" && slaType != "fixes" {
-		c.sendReply(msg, "SLA type must be 'review' or 'fixes'")
+		c.sendReply(msg, "SLA type must be 'review', 'fixes', 'reassign', 'pipeline' or 'drafts'")
 		return
 	}
 
@@ -992,6 +2941,129 @@ func (c *VKCommandConsumer) handleSLACommand(msg *botgolang.Message, _ botgolang
 	c.sendReply(msg, fmt.Sprintf("SLA %s set to %s for: %s", slaType, parts[2], strings.Join(repoNames, ", ")))
 }
 
+// handleSLAReassign configures automatic reassignment of stale reviews.
+// Format: /sla reassign <percent|off> [max]
+// A reviewer inactive for percent of the review SLA is swapped out, at most max times per MR.
+func (c *VKCommandConsumer) handleSLAReassign(msg *botgolang.Message, subs []models.RepositorySubscription, args []string) {
+	threshold := 0
+	if strings.ToLower(args[0]) != "off" {
+		value, err := strconv.Atoi(strings.TrimSuffix(args[0], "%"))
+		if err != nil || value <= 0 || value > 100 {
+			c.sendReply(msg, "Threshold must be a percentage between 1 and 100 (e.g., 50%) or 'off'")
+			return
+		}
+		threshold = value
+	}
+
+	maxReassigns := 0
+	if len(args) > 1 {
+		value, err := strconv.Atoi(args[1])
+		if err != nil || value <= 0 {
+			c.sendReply(msg, "Max reassignments must be a positive number")
+			return
+		}
+		maxReassigns = value
+	}
+
+	var repoNames []string
+	for _, sub := range subs {
+		var sla models.RepositorySLA
+		if err := c.db.Where(models.RepositorySLA{RepositoryID: sub.RepositoryID}).FirstOrCreate(&sla).Error; err != nil {
+			log.Printf("failed to get/create SLA for repo %d: %v", sub.RepositoryID, err)
+			continue
+		}
+
+		sla.ReassignThreshold = threshold
+		if maxReassigns > 0 {
+			sla.MaxAutoReassigns = maxReassigns
+		}
+		if err := c.db.Save(&sla).Error; err != nil {
+			log.Printf("failed to save SLA for repo %d: %v", sub.RepositoryID, err)
+			continue
+		}
+		repoNames = append(repoNames, fmt.Sprintf("%s (%s)", sub.Repository.Name, formatReassignSetting(sla.ReassignThreshold, sla.MaxAutoReassigns)))
+	}
+
+	c.sendReply(msg, "Auto-reassign updated for: "+strings.Join(repoNames, ", "))
+}
+
+// handleSLAPipeline toggles holding reviewer assignment until an MR's first pipeline succeeds.
+// Format: /sla pipeline <on|off>
+func (c *VKCommandConsumer) handleSLAPipeline(msg *botgolang.Message, subs []models.RepositorySubscription, value string) {
+	var wait bool
+	switch strings.ToLower(value) {
+	case "on":
+		wait = true
+	case "off":
+		wait = false
+	default:
+		c.sendReply(msg, "Usage: /sla pipeline <on|off>")
+		return
+	}
+
+	var repoNames []string
+	for _, sub := range subs {
+		var sla models.RepositorySLA
+		if err := c.db.Where(models.RepositorySLA{RepositoryID: sub.RepositoryID}).FirstOrCreate(&sla).Error; err != nil {
+			log.Printf("failed to get/create SLA for repo %d: %v", sub.RepositoryID, err)
+			continue
+		}
+		if err := c.db.Model(&sla).Update("wait_for_pipeline", wait).Error; err != nil {
+			log.Printf("failed to save SLA for repo %d: %v", sub.RepositoryID, err)
+			continue
+		}
+		repoNames = append(repoNames, sub.Repository.Name)
+	}
+
+	if wait {
+		c.sendReply(msg, "Reviewers will be assigned once the first pipeline succeeds for: "+strings.Join(repoNames, ", "))
+		return
+	}
+	c.sendReply(msg, "Reviewers will be assigned regardless of pipeline status for: "+strings.Join(repoNames, ", "))
+}
+
+// handleSLADrafts chooses whether draft MRs get reviewers right away or only once they leave draft.
+// Format: /sla drafts <assign|defer>
+func (c *VKCommandConsumer) handleSLADrafts(msg *botgolang.Message, subs []models.RepositorySubscription, value string) {
+	var assign bool
+	switch strings.ToLower(value) {
+	case "assign":
+		assign = true
+	case "defer":
+		assign = false
+	default:
+		c.sendReply(msg, "Usage: /sla drafts <assign|defer>")
+		return
+	}
+
+	var repoNames []string
+	for _, sub := range subs {
+		var sla models.RepositorySLA
+		if err := c.db.Where(models.RepositorySLA{RepositoryID: sub.RepositoryID}).FirstOrCreate(&sla).Error; err != nil {
+			log.Printf("failed to get/create SLA for repo %d: %v", sub.RepositoryID, err)
+			continue
+		}
+		if err := c.db.Model(&sla).Update("assign_drafts", assign).Error; err != nil {
+			log.Printf("failed to save SLA for repo %d: %v", sub.RepositoryID, err)
+			continue
+		}
+		repoNames = append(repoNames, sub.Repository.Name)
+	}
+
+	if assign {
+		c.sendReply(msg, "Draft MRs will get reviewers right away for: "+strings.Join(repoNames, ", "))
+		return
+	}
+	c.sendReply(msg, "Draft MRs will get reviewers once they leave draft for: "+strings.Join(repoNames, ", "))
+}
+
+func formatReassignSetting(threshold, maxReassigns int) string {
+	if threshold <= 0 {
+		return "off"
+	}
+	return fmt.Sprintf("%d%% of review SLA, max %d per MR", threshold, maxReassigns)
+}
+
 func (c *VKCommandConsumer) handleLabelReviewersCommand(msg *botgolang.Message, _ botgolang.Contact) {
 	chatID := fmt.Sprint(msg.Chat.ID)
 	var chat models.Chat
@@ -1025,7 +3097,7 @@ func (c *VKCommandConsumer) handleLabelReviewersCommand(msg *botgolang.Message,
 
 		labelMap := make(map[string][]string)
 		for _, lr := range labelReviewers {
-			labelMap[lr.LabelName] = append(labelMap[lr.LabelName], lr.User.Username)
+			labelMap[lr.LabelName] = append(labelMap[lr.LabelName], formatReviewerTier(lr.User.Username, lr.Tier))
 		}
 
 		var lines []string
@@ -1041,16 +3113,34 @@ func (c *VKCommandConsumer) handleLabelReviewersCommand(msg *botgolang.Message,
 
 	if len(parts) == 1 {
 		c.db.Where("repository_id IN ? AND label_name = ?", repoIDs, labelName).Delete(&models.LabelReviewer{})
+		c.db.Unscoped().Where("repository_id IN ? AND label_name = ?", repoIDs, labelName).Delete(&models.ReviewerPoolGroup{})
+		c.unbindTeams(repoIDs, models.TeamRoleLabelReviewers, labelName)
 		c.sendReply(msg, fmt.Sprintf("Cleared reviewers for label '%s'", labelName))
 		return
 	}
 
+	if strings.HasPrefix(strings.TrimSpace(parts[1]), "@") {
+		c.bindReviewerPoolGroup(msg, repoIDs, labelName, strings.Fields(parts[1]))
+		return
+	}
+	if fields := strings.Fields(parts[1]); len(fields) > 0 && fields[0] == "team" {
+		c.handleTeamBinding(msg, repoIDs, models.TeamRoleLabelReviewers, labelName, fields)
+		return
+	}
+
 	usernames := strings.Split(parts[1], ",")
+	for _, entry := range usernames {
+		if _, tier := parseReviewerEntry(entry); tier != "" && !isValidTierName(tier) {
+			c.sendReply(msg, fmt.Sprintf("Invalid tier '%s'. Use letters, digits, '_' or '-' (max 20).", tier))
+			return
+		}
+	}
+
 	var added []string
 	var notFound []string
 
-	for _, uname := range usernames {
-		uname = strings.TrimSpace(uname)
+	for _, entry := range usernames {
+		uname, tier := parseReviewerEntry(entry)
 		if uname == "" {
 			continue
 		}
@@ -1075,10 +3165,12 @@ func (c *VKCommandConsumer) handleLabelReviewersCommand(msg *botgolang.Message,
 		}
 
 		for _, repoID := range repoIDs {
-			lr := models.LabelReviewer{RepositoryID: repoID, LabelName: labelName, UserID: user.ID}
-			c.db.FirstOrCreate(&lr, models.LabelReviewer{RepositoryID: repoID, LabelName: labelName, UserID: user.ID})
+			var lr models.LabelReviewer
+			c.db.Where(models.LabelReviewer{RepositoryID: repoID, LabelName: labelName, UserID: user.ID}).
+				Assign(map[string]interface{}{"tier": tier, "pool_group_id": nil, "team_id": nil}).
+				FirstOrCreate(&lr)
 		}
-		added = append(added, uname)
+		added = append(added, formatReviewerTier(uname, tier))
 	}
 
 	reply := fmt.Sprintf("Label '%s' reviewers set: %s", labelName, strings.Join(added, ", "))
@@ -1088,6 +3180,174 @@ func (c *VKCommandConsumer) handleLabelReviewersCommand(msg *botgolang.Message,
 	c.sendReply(msg, reply)
 }
 
+// handlePathReviewersCommand manages path-glob reviewer rules for subscribed repositories.
+// Format: /path_reviewers ["glob" user1,user2]
+// Without arguments rules are listed; with only a glob the rule is removed.
+// Setting users replaces the rule's reviewer list.
+func (c *VKCommandConsumer) handlePathReviewersCommand(msg *botgolang.Message, _ botgolang.Contact) {
+	chatID := fmt.Sprint(msg.Chat.ID)
+	var chat models.Chat
+	if err := c.db.Where("chat_id = ?", chatID).First(&chat).Error; err != nil {
+		c.sendReply(msg, "Chat not found")
+		return
+	}
+
+	var subs []models.RepositorySubscription
+	c.db.Preload("Repository").Where("chat_id = ?", chat.ID).Find(&subs)
+	if len(subs) == 0 {
+		c.sendReply(msg, "No repository subscription found. Use /subscribe first.")
+		return
+	}
+
+	repoIDs := make([]uint, len(subs))
+	for i, s := range subs {
+		repoIDs[i] = s.RepositoryID
+	}
+
+	argStr := strings.TrimSpace(strings.TrimPrefix(msg.Text, "/path_reviewers"))
+
+	if argStr == "" {
+		var pathReviewers []models.PathReviewer
+		c.db.Where("repository_id IN ?", repoIDs).Preload("User").Order("pattern").Find(&pathReviewers)
+
+		if len(pathReviewers) == 0 {
+			c.sendReply(msg, "No path reviewers configured.")
+			return
+		}
+
+		var patterns []string
+		patternUsers := make(map[string][]string)
+		for _, pr := range pathReviewers {
+			if _, ok := patternUsers[pr.Pattern]; !ok {
+				patterns = append(patterns, pr.Pattern)
+			}
+			if !containsString(patternUsers[pr.Pattern], pr.User.Username) {
+				patternUsers[pr.Pattern] = append(patternUsers[pr.Pattern], pr.User.Username)
+			}
+		}
+
+		var lines []string
+		for _, pattern := range patterns {
+			lines = append(lines, fmt.Sprintf("%s: %s", pattern, strings.Join(patternUsers[pattern], ", ")))
+		}
+		c.sendReply(msg, "Path reviewers:\n"+strings.Join(lines, "\n"))
+		return
+	}
+
+	pattern, usernames := parsePathReviewersArgs(argStr)
+	if pattern == "" {
+		c.sendReply(msg, "Usage: /path_reviewers \"glob\" user1,user2")
+		return
+	}
+
+	if len(usernames) == 0 {
+		result := c.db.Where("repository_id IN ? AND pattern = ?", repoIDs, pattern).Delete(&models.PathReviewer{})
+		if result.RowsAffected == 0 {
+			c.sendReply(msg, fmt.Sprintf("No rule for '%s'", pattern))
+			return
+		}
+		c.sendReply(msg, fmt.Sprintf("Removed path rule '%s'", pattern))
+		return
+	}
+
+	var users []models.User
+	var added []string
+	var notFound []string
+
+	for _, uname := range usernames {
+		var user models.User
+		if err := c.db.Where("username = ?", uname).First(&user).Error; err != nil {
+			glUsers, _, glErr := c.glClient.Users.ListUsers(&gitlab.ListUsersOptions{Username: gitlab.Ptr(uname)})
+			if glErr != nil || len(glUsers) == 0 {
+				notFound = append(notFound, uname)
+				continue
+			}
+			userData := models.User{
+				GitlabID:  glUsers[0].ID,
+				Username:  glUsers[0].Username,
+				Name:      glUsers[0].Name,
+				State:     glUsers[0].State,
+				AvatarURL: glUsers[0].AvatarURL,
+				WebURL:    glUsers[0].WebURL,
+				Email:     glUsers[0].Email,
+			}
+			c.db.Where(models.User{GitlabID: glUsers[0].ID}).Assign(userData).FirstOrCreate(&user)
+		}
+		users = append(users, user)
+		added = append(added, uname)
+	}
+
+	if len(users) == 0 {
+		c.sendReply(msg, fmt.Sprintf("No users found: %s", strings.Join(notFound, ", ")))
+		return
+	}
+
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("repository_id IN ? AND pattern = ?", repoIDs, pattern).Delete(&models.PathReviewer{}).Error; err != nil {
+			return err
+		}
+		for _, repoID := range repoIDs {
+			for _, user := range users {
+				if err := tx.Create(&models.PathReviewer{RepositoryID: repoID, Pattern: pattern, UserID: user.ID}).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("failed to save path reviewers for %s: %v", pattern, err)
+		c.sendReply(msg, "Failed to save path reviewers. Please try again later.")
+		return
+	}
+
+	reply := fmt.Sprintf("Path '%s' reviewers set: %s", pattern, strings.Join(added, ", "))
+	if len(notFound) > 0 {
+		reply += fmt.Sprintf(". Not found: %s", strings.Join(notFound, ", "))
+	}
+	c.sendReply(msg, reply)
+}
+
+// parsePathReviewersArgs splits `"glob" user1,user2` into the pattern and usernames.
+// The glob may be quoted; usernames are optional and may be prefixed with @.
+func parsePathReviewersArgs(argStr string) (string, []string) {
+	argStr = strings.TrimSpace(argStr)
+
+	var pattern, rest string
+	if strings.HasPrefix(argStr, "\"") {
+		endQuote := strings.Index(argStr[1:], "\"")
+		if endQuote == -1 {
+			return strings.Trim(argStr, "\""), nil
+		}
+		pattern = argStr[1 : endQuote+1]
+		rest = argStr[endQuote+2:]
+	} else {
+		parts := strings.SplitN(argStr, " ", 2)
+		pattern = parts[0]
+		if len(parts) > 1 {
+			rest = parts[1]
+		}
+	}
+
+	var usernames []string
+	for _, uname := range strings.Split(rest, ",") {
+		uname = strings.TrimPrefix(strings.TrimSpace(uname), "@")
+		if uname != "" && !containsString(usernames, uname) {
+			usernames = append(usernames, uname)
+		}
+	}
+	return strings.TrimSpace(pattern), usernames
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *VKCommandConsumer) handleDailyDigestCommand(msg *botgolang.Message, from botgolang.Contact) {
 	if msg.Chat.Type != "private" {
 		c.sendReply(msg, "The /daily_digest command must be used in a private chat with the bot.")
@@ -1155,6 +3415,104 @@ func (c *VKCommandConsumer) handleDailyDigestCommand(msg *botgolang.Message, fro
 	c.sendReply(msg, fmt.Sprintf("Daily digest is now %s.", status))
 }
 
+// handleNotifyCommand lets users choose which DMs they get and set quiet hours.
+// Format: /notify [<event> on|off] [quiet <start>-<end> [+/-N]|off]
+// Must be used in a private chat; the VK account must match a GitLab user by email.
+func (c *VKCommandConsumer) handleNotifyCommand(msg *botgolang.Message, from botgolang.Contact) {
+	if msg.Chat.Type != "private" {
+		c.sendReply(msg, "The /notify command must be used in a private chat with the bot.")
+		return
+	}
+	user := c.findLinkedUser(from)
+	if user == nil {
+		c.sendReply(msg, "No GitLab user matches your VK account email.")
+		return
+	}
+
+	parts := strings.Fields(msg.Text)
+	if len(parts) == 1 {
+		c.sendReply(msg, formatNotificationPreference(utils.GetNotificationPreference(c.db, user.ID)))
+		return
+	}
+
+	var updates map[string]interface{}
+	switch {
+	case parts[1] == "quiet" && len(parts) == 3 && parts[2] == "off":
+		updates = map[string]interface{}{"quiet_start": -1, "quiet_end": -1}
+	case parts[1] == "quiet" && (len(parts) == 3 || len(parts) == 4):
+		start, end, err := parseQuietHours(parts[2])
+		if err != nil {
+			c.sendReply(msg, "Invalid quiet hours. Use e.g. /notify quiet 22-8 +3")
+			return
+		}
+		updates = map[string]interface{}{"quiet_start": start, "quiet_end": end}
+		if len(parts) == 4 {
+			offset, err := parseTimezoneOffset(parts[3])
+			if err != nil {
+				c.sendReply(msg, "Invalid timezone format. Use +N or -N (e.g., +3, -5).")
+				return
+			}
+			updates["timezone_offset"] = offset
+		}
+	case len(parts) == 3 && utils.IsValidNotificationEvent(parts[1]) && (parts[2] == "on" || parts[2] == "off"):
+		updates = map[string]interface{}{utils.NotificationColumn(utils.NotificationEvent(parts[1])): parts[2] == "on"}
+	default:
+		var events []string
+		for _, e := range utils.NotificationEvents {
+			events = append(events, string(e))
+		}
+		c.sendReply(msg, fmt.Sprintf("Usage: /notify <event> on|off, /notify quiet <start>-<end> [+N], /notify quiet off\nEvents: %s", strings.Join(events, ", ")))
+		return
+	}
+
+	pref, err := utils.UpdateNotificationPreference(c.db, user.ID, updates)
+	if err != nil {
+		log.Printf("failed to save notification preference for user %s: %v", user.Username, err)
+		c.sendReply(msg, "Failed to save preferences. Please try again later.")
+		return
+	}
+	c.sendReply(msg, formatNotificationPreference(pref))
+}
+
+// parseQuietHours parses a "start-end" hour range such as "22-8".
+func parseQuietHours(s string) (int, int, error) {
+	startStr, endStr, ok := strings.Cut(s, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid range")
+	}
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := strconv.Atoi(endStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	if start < 0 || start > 23 || end < 0 || end > 23 || start == end {
+		return 0, 0, fmt.Errorf("hours out of range")
+	}
+	return start, end, nil
+}
+
+func formatNotificationPreference(pref *models.NotificationPreference) string {
+	var sb strings.Builder
+	sb.WriteString("DM notifications:\n")
+	for _, e := range utils.NotificationEvents {
+		state := "off"
+		if utils.NotificationEnabled(pref, e) {
+			state = "on"
+		}
+		sb.WriteString(fmt.Sprintf("- %s: %s\n", e, state))
+	}
+	if pref == nil || pref.QuietStart < 0 || pref.QuietEnd < 0 {
+		sb.WriteString("Quiet hours: off")
+	} else {
+		sb.WriteString(fmt.Sprintf("Quiet hours: %02d:00-%02d:00 %s (DMs are delivered in one batch afterwards)",
+			pref.QuietStart, pref.QuietEnd, formatTimezone(pref.TimezoneOffset)))
+	}
+	return sb.String()
+}
+
 func (c *VKCommandConsumer) handleSubscribersCommand(msg *botgolang.Message) {
 	var prefs []models.DailyDigestPreference
 	c.db.Preload("VKUser").Where("enabled = ?", true).Find(&prefs)
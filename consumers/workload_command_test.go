@@ -0,0 +1,50 @@
+package consumers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/utils"
+)
+
+// TestFormatWorkloadSection_RendersEntries tests rendering of load lines including vacation and missing pending age.
+func TestFormatWorkloadSection_RendersEntries(t *testing.T) {
+	now := time.Now()
+	assigned := now.Add(-26 * time.Hour)
+	loads := []utils.ReviewerLoad{
+		{User: models.User{Username: "alice"}, OpenReviews: 3, OldestPending: &assigned, RecentCount: 5, Weight: 0.25},
+		{User: models.User{Username: "bob", OnVacation: true}, OpenReviews: 0, RecentCount: 1},
+	}
+
+	text := formatWorkloadSection(loads, now)
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %q", len(lines), text)
+	}
+	if lines[0] != "- alice: open 3, oldest 1d 2h, recent 5, weight 25%" {
+		t.Errorf("unexpected first line: %q", lines[0])
+	}
+	if lines[1] != "- bob [vacation]: open 0, oldest -, recent 1, weight 0%" {
+		t.Errorf("unexpected second line: %q", lines[1])
+	}
+}
+
+// TestFormatWorkloadSection_ShowsCapacity tests open/capacity rendering and the full marker.
+func TestFormatWorkloadSection_ShowsCapacity(t *testing.T) {
+	loads := []utils.ReviewerLoad{
+		{User: models.User{Username: "alice"}, OpenReviews: 3, Capacity: 3},
+		{User: models.User{Username: "bob"}, OpenReviews: 1, Capacity: 3},
+	}
+
+	lines := strings.Split(strings.TrimSpace(formatWorkloadSection(loads, time.Now())), "\n")
+
+	if lines[0] != "- alice [full]: open 3/3, oldest -, recent 0, weight 0%" {
+		t.Errorf("unexpected first line: %q", lines[0])
+	}
+	if lines[1] != "- bob: open 1/3, oldest -, recent 0, weight 0%" {
+		t.Errorf("unexpected second line: %q", lines[1])
+	}
+}
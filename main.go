@@ -1,6 +1,8 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -34,6 +36,12 @@ func (t *RateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, err
 }
 
 func main() {
+	simulateRepo := flag.String("simulate", "", "Replay recent MRs of a repository (path or ID) through reviewer selection and exit")
+	simulateDays := flag.Int("simulate-days", 30, "Number of days of MRs to replay in simulation mode")
+	simulateStrategy := flag.String("simulate-strategy", "", "Reviewer strategy to simulate instead of the configured one")
+	simulateCount := flag.Int("simulate-count", 0, "Assign count to simulate instead of the configured one")
+	flag.Parse()
+
 	logsDir := "logs"
 	if err := os.MkdirAll(logsDir, 0o755); err != nil {
 		log.Fatalf("failed to create logs directory: %v", err)
@@ -65,6 +73,11 @@ func main() {
 		&models.ReleaseSubscription{}, &models.MRNotificationState{},
 		&models.FeatureReleaseLabel{}, &models.FeatureReleaseBranch{},
 		&models.DeployTrackingRule{}, &models.TrackedDeployJob{},
+		&models.ReviewerSelectionTrace{}, &models.CodeOwnersConfig{}, &models.PathReviewer{}, &models.MergeRequestFile{},
+		&models.SizeAssignRule{}, &models.SizeLabelConfig{}, &models.RepositoryMember{},
+		&models.ReviewerPoolGroup{}, &models.ReviewerPoolExclusion{}, &models.Team{}, &models.TeamBinding{},
+		&models.NotificationPreference{}, &models.QueuedNotification{}, &models.StaleConfig{},
+		&models.AutoMergeConfig{}, &models.AutoRebaseConfig{},
 	); err != nil {
 		log.Fatalf("failed to migrate database schemas: %v", err)
 	}
@@ -73,6 +86,11 @@ func main() {
 		log.Printf("Warning: thread metadata backfill failed: %v", err)
 	}
 
+	if *simulateRepo != "" {
+		runSimulation(db, *simulateRepo, *simulateDays, *simulateStrategy, *simulateCount)
+		return
+	}
+
 	limiter := rate.NewLimiter(rate.Limit(5), 10)
 
 	httpClient := &http.Client{
@@ -145,6 +163,12 @@ func main() {
 	personalDigestConsumer := consumers.NewPersonalDigestConsumer(db, vkBot)
 	personalDigestConsumer.StartConsumer()
 
+	teamDigestConsumer := consumers.NewTeamDigestConsumer(db, vkBot)
+	teamDigestConsumer.StartConsumer()
+
+	staleReportConsumer := consumers.NewStaleReportConsumer(db, vkBot)
+	staleReportConsumer.StartConsumer()
+
 	autoReleaseConsumer := consumers.NewAutoReleaseConsumer(db, glClient, cfg.Jira.BaseURL)
 
 	releaseNotificationConsumer := consumers.NewReleaseNotificationConsumer(db, vkBot)
@@ -158,10 +182,24 @@ func main() {
 		for range ticker.C {
 			polling.PollRepositories(db, glClient)
 			polling.PollMergeRequests(db, glClient)
+			polling.PollRepositoryMembers(db, glClient)
+			polling.PollReviewerPoolGroups(db, glClient)
+			mrReviewerConsumer.ApplySizeLabels()
+			mrReviewerConsumer.ProcessDraftReadyNotifications()
 			mrReviewerConsumer.AssignReviewers()
+			mrReviewerConsumer.ReassignStaleReviews()
+			mrReviewerConsumer.ReplaceInactiveReviewers()
 			mrReviewerConsumer.ProcessStateChangeNotifications()
 			mrReviewerConsumer.ProcessReviewerRemovalNotifications()
 			mrReviewerConsumer.ProcessFullyApprovedNotifications()
+			mrReviewerConsumer.ProcessAutoMerge()
+			mrReviewerConsumer.ProcessPipelineNotifications()
+			mrReviewerConsumer.ProcessConflictNotifications()
+			mrReviewerConsumer.ProcessAutoRebase()
+			mrReviewerConsumer.ProcessReReviewRequests()
+			mrReviewerConsumer.ProcessSLAWarnings()
+			mrReviewerConsumer.ProcessStaleMRs()
+			mrReviewerConsumer.FlushQueuedNotifications()
 			mrReviewerConsumer.CleanupOldUnnotifiedActions()
 			autoReleaseConsumer.ProcessAutoReleaseBranches()
 			autoReleaseConsumer.ProcessReleaseMRDescriptions()
@@ -175,3 +213,25 @@ func main() {
 
 	select {}
 }
+
+// runSimulation prints a dry-run reviewer assignment report for one repository.
+func runSimulation(db *gorm.DB, repoIdentifier string, days int, strategy string, assignCount int) {
+	repo, err := utils.FindRepositoryByIdentifier(db, repoIdentifier)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "repository not found: %s\n", repoIdentifier)
+		os.Exit(1)
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	simulator := consumers.NewMRReviewerConsumerWithServices(db, nil, nil, nil, 0, nil)
+	report, err := simulator.SimulateAssignments(repo.ID, consumers.SimulationOptions{
+		Since:       since,
+		Strategy:    strategy,
+		AssignCount: assignCount,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "simulation failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(consumers.FormatSimulationReport(report, since))
+}
@@ -14,6 +14,9 @@ type MockMergeRequestsService struct {
 	GetMergeRequestFunc            func(pid interface{}, mergeRequest int, opt *gitlab.GetMergeRequestsOptions, options ...gitlab.RequestOptionFunc) (*gitlab.MergeRequest, *gitlab.Response, error)
 	CreateMergeRequestFunc         func(pid interface{}, opt *gitlab.CreateMergeRequestOptions, options ...gitlab.RequestOptionFunc) (*gitlab.MergeRequest, *gitlab.Response, error)
 	GetMergeRequestCommitsFunc     func(pid interface{}, mergeRequest int, opt *gitlab.GetMergeRequestCommitsOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.Commit, *gitlab.Response, error)
+	ListMergeRequestDiffsFunc      func(pid interface{}, mergeRequest int, opt *gitlab.ListMergeRequestDiffsOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.MergeRequestDiff, *gitlab.Response, error)
+	AcceptMergeRequestFunc         func(pid interface{}, mergeRequest int, opt *gitlab.AcceptMergeRequestOptions, options ...gitlab.RequestOptionFunc) (*gitlab.MergeRequest, *gitlab.Response, error)
+	RebaseMergeRequestFunc         func(pid interface{}, mergeRequest int, opt *gitlab.RebaseMergeRequestOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Response, error)
 
 	// Call tracking
 	UpdateMergeRequestCalls       []UpdateMergeRequestCall
@@ -22,6 +25,9 @@ type MockMergeRequestsService struct {
 	GetMergeRequestCalls          []GetMergeRequestCall
 	CreateMergeRequestCalls       []CreateMergeRequestCall
 	GetMergeRequestCommitsCalls   []GetMergeRequestCommitsCall
+	ListMergeRequestDiffsCalls    []ListMergeRequestDiffsCall
+	AcceptMergeRequestCalls       []AcceptMergeRequestCall
+	RebaseMergeRequestCalls       []RebaseMergeRequestCall
 }
 
 // UpdateMergeRequestCall tracks a call to UpdateMergeRequest.
@@ -63,6 +69,27 @@ type GetMergeRequestCommitsCall struct {
 	Opt          *gitlab.GetMergeRequestCommitsOptions
 }
 
+// ListMergeRequestDiffsCall tracks a call to ListMergeRequestDiffs.
+type ListMergeRequestDiffsCall struct {
+	PID          interface{}
+	MergeRequest int
+	Opt          *gitlab.ListMergeRequestDiffsOptions
+}
+
+// AcceptMergeRequestCall tracks a call to AcceptMergeRequest.
+type AcceptMergeRequestCall struct {
+	PID          interface{}
+	MergeRequest int
+	Opt          *gitlab.AcceptMergeRequestOptions
+}
+
+// RebaseMergeRequestCall tracks a call to RebaseMergeRequest.
+type RebaseMergeRequestCall struct {
+	PID          interface{}
+	MergeRequest int
+	Opt          *gitlab.RebaseMergeRequestOptions
+}
+
 // UpdateMergeRequest implements the interface method.
 func (m *MockMergeRequestsService) UpdateMergeRequest(pid interface{}, mergeRequest int, opt *gitlab.UpdateMergeRequestOptions, options ...gitlab.RequestOptionFunc) (*gitlab.MergeRequest, *gitlab.Response, error) {
 	m.UpdateMergeRequestCalls = append(m.UpdateMergeRequestCalls, UpdateMergeRequestCall{
@@ -138,6 +165,45 @@ func (m *MockMergeRequestsService) GetMergeRequestCommits(pid interface{}, merge
 	return nil, NewMockResponse(0), nil
 }
 
+// ListMergeRequestDiffs implements the interface method.
+func (m *MockMergeRequestsService) ListMergeRequestDiffs(pid interface{}, mergeRequest int, opt *gitlab.ListMergeRequestDiffsOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.MergeRequestDiff, *gitlab.Response, error) {
+	m.ListMergeRequestDiffsCalls = append(m.ListMergeRequestDiffsCalls, ListMergeRequestDiffsCall{
+		PID:          pid,
+		MergeRequest: mergeRequest,
+		Opt:          opt,
+	})
+	if m.ListMergeRequestDiffsFunc != nil {
+		return m.ListMergeRequestDiffsFunc(pid, mergeRequest, opt, options...)
+	}
+	return nil, NewMockResponse(0), nil
+}
+
+// AcceptMergeRequest implements the interface method.
+func (m *MockMergeRequestsService) AcceptMergeRequest(pid interface{}, mergeRequest int, opt *gitlab.AcceptMergeRequestOptions, options ...gitlab.RequestOptionFunc) (*gitlab.MergeRequest, *gitlab.Response, error) {
+	m.AcceptMergeRequestCalls = append(m.AcceptMergeRequestCalls, AcceptMergeRequestCall{
+		PID:          pid,
+		MergeRequest: mergeRequest,
+		Opt:          opt,
+	})
+	if m.AcceptMergeRequestFunc != nil {
+		return m.AcceptMergeRequestFunc(pid, mergeRequest, opt, options...)
+	}
+	return nil, NewMockResponse(0), nil
+}
+
+// RebaseMergeRequest implements the interface method.
+func (m *MockMergeRequestsService) RebaseMergeRequest(pid interface{}, mergeRequest int, opt *gitlab.RebaseMergeRequestOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Response, error) {
+	m.RebaseMergeRequestCalls = append(m.RebaseMergeRequestCalls, RebaseMergeRequestCall{
+		PID:          pid,
+		MergeRequest: mergeRequest,
+		Opt:          opt,
+	})
+	if m.RebaseMergeRequestFunc != nil {
+		return m.RebaseMergeRequestFunc(pid, mergeRequest, opt, options...)
+	}
+	return NewMockResponse(0), nil
+}
+
 // MockDiscussionsService is a mock implementation of GitLabDiscussionsService.
 type MockDiscussionsService struct {
 	ListMergeRequestDiscussionsFunc func(pid interface{}, mergeRequest int, opt *gitlab.ListMergeRequestDiscussionsOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.Discussion, *gitlab.Response, error)
@@ -335,3 +401,26 @@ func (m *MockJobsService) ListProjectJobs(pid interface{}, opts *gitlab.ListJobs
 	}
 	return nil, NewMockResponse(0), nil
 }
+
+// MockRepositoryFilesService is a mock implementation of GitLabRepositoryFilesService.
+type MockRepositoryFilesService struct {
+	GetRawFileFunc func(pid interface{}, fileName string, opt *gitlab.GetRawFileOptions, options ...gitlab.RequestOptionFunc) ([]byte, *gitlab.Response, error)
+
+	GetRawFileCalls []GetRawFileCall
+}
+
+// GetRawFileCall tracks a call to GetRawFile.
+type GetRawFileCall struct {
+	PID      interface{}
+	FileName string
+	Opt      *gitlab.GetRawFileOptions
+}
+
+// GetRawFile implements the interface method.
+func (m *MockRepositoryFilesService) GetRawFile(pid interface{}, fileName string, opt *gitlab.GetRawFileOptions, options ...gitlab.RequestOptionFunc) ([]byte, *gitlab.Response, error) {
+	m.GetRawFileCalls = append(m.GetRawFileCalls, GetRawFileCall{PID: pid, FileName: fileName, Opt: opt})
+	if m.GetRawFileFunc != nil {
+		return m.GetRawFileFunc(pid, fileName, opt, options...)
+	}
+	return nil, NewMockResponse404(), nil
+}
@@ -36,12 +36,13 @@ func (d Duration) ToDuration() time.Duration {
 
 type Repository struct {
 	gorm.Model
-	GitlabID          int    `gorm:"uniqueIndex;not null"`
+	GitlabID          int `gorm:"uniqueIndex;not null"`
 	Name              string
 	Path              string `gorm:"index"`
 	PathWithNamespace string `gorm:"index"`
 	Description       string
 	WebURL            string
+	MembersSyncedAt   *time.Time // Last sync of RepositoryMember rows (nil = membership unknown)
 	MergeRequests     []MergeRequest
 	Subscriptions     []RepositorySubscription
 }
@@ -49,17 +50,18 @@ type Repository struct {
 type User struct {
 	gorm.Model
 
-	GitlabID     int `gorm:"uniqueIndex;not null"`
-	Username     string
-	Name         string
-	State        string
-	Locked       bool
-	CreatedAt    *time.Time
-	AvatarURL    string
-	WebURL       string
-	Email        string `gorm:"index"`
-	EmailFetched bool   `gorm:"default:false"`
-	OnVacation   bool   `gorm:"default:false"` // User is on vacation and should not be assigned as reviewer
+	GitlabID       int `gorm:"uniqueIndex;not null"`
+	Username       string
+	Name           string
+	State          string
+	Locked         bool
+	CreatedAt      *time.Time
+	AvatarURL      string
+	WebURL         string
+	Email          string `gorm:"index"`
+	EmailFetched   bool   `gorm:"default:false"`
+	OnVacation     bool   `gorm:"default:false"` // User is on vacation and should not be assigned as reviewer
+	MaxOpenReviews int    `gorm:"default:0"`     // Cap on concurrent open reviews, overrides the repository limit (0 = use repository limit)
 
 	UpdatedAt *time.Time
 
@@ -151,6 +153,23 @@ type MergeRequest struct {
 	HasConflicts                bool
 	BlockingDiscussionsResolved bool
 
+	// GitLab approval rules, refreshed with the approvals
+	ApprovalRulesSatisfied bool   `gorm:"default:true"` // GitLab reports no approvals left
+	ApprovalsLeft          int    // Approvals GitLab still requires
+	MissingApprovalRules   string // Comma-separated names of approval rules that still need approvals
+
+	// Diff stats, refreshed when the head SHA changes
+	LinesAdded   int
+	LinesRemoved int
+	FilesChanged int
+	DiffStatsSHA string // SHA the diff stats were computed for (empty = not fetched yet)
+
+	// Head pipeline, refreshed until it finishes for the current head SHA
+	PipelineID     int
+	PipelineStatus string `gorm:"type:varchar(30)"` // GitLab pipeline status (empty = no pipeline)
+	PipelineSHA    string
+	PipelineWebURL string
+
 	GitlabCreatedAt *time.Time
 	GitlabUpdatedAt *time.Time
 	MergedAt        *time.Time
@@ -232,6 +251,9 @@ type PossibleReviewer struct {
 	Repository   Repository `gorm:"constraint:OnDelete:CASCADE;"`
 	UserID       uint       `gorm:"not null"`
 	User         User       `gorm:"constraint:OnDelete:CASCADE;"`
+	Tier         string     `gorm:"type:varchar(20)"` // Reviewer tier, e.g. senior (empty = no tier)
+	PoolGroupID  *uint      `gorm:"index"`            // ReviewerPoolGroup that synced this row (nil = added manually)
+	TeamID       *uint      `gorm:"index"`            // Team that synced this row (nil = added manually)
 }
 
 // ReleaseManager links a GitLab repository with a user who manages releases.
@@ -242,6 +264,7 @@ type ReleaseManager struct {
 	Repository   Repository `gorm:"constraint:OnDelete:CASCADE;"`
 	UserID       uint       `gorm:"not null;uniqueIndex:idx_release_manager_unique,priority:2"`
 	User         User       `gorm:"constraint:OnDelete:CASCADE;"`
+	TeamID       *uint      `gorm:"index"` // Team that synced this row (nil = added manually)
 }
 
 type VKMessage struct {
@@ -272,16 +295,27 @@ type LabelReviewer struct {
 	LabelName    string     `gorm:"not null;uniqueIndex:idx_label_reviewer_unique,priority:2"`
 	UserID       uint       `gorm:"not null;uniqueIndex:idx_label_reviewer_unique,priority:3"`
 	User         User       `gorm:"constraint:OnDelete:CASCADE;"`
+	Tier         string     `gorm:"type:varchar(20)"` // Reviewer tier, e.g. senior (empty = no tier)
+	PoolGroupID  *uint      `gorm:"index"`            // ReviewerPoolGroup that synced this row (nil = added manually)
+	TeamID       *uint      `gorm:"index"`            // Team that synced this row (nil = added manually)
 }
 
 // RepositorySLA stores SLA settings per repository.
 type RepositorySLA struct {
 	gorm.Model
-	RepositoryID   uint       `gorm:"uniqueIndex;not null"`
-	Repository     Repository `gorm:"constraint:OnDelete:CASCADE;"`
-	ReviewDuration Duration   `gorm:"not null;default:172800000000000"` // SLA duration for review phase (default 48h)
-	FixesDuration  Duration   `gorm:"not null;default:172800000000000"` // SLA duration for fixes phase (default 48h)
-	AssignCount    int        `gorm:"not null;default:1"`               // Number of reviewers to assign
+	RepositoryID      uint       `gorm:"uniqueIndex;not null"`
+	Repository        Repository `gorm:"constraint:OnDelete:CASCADE;"`
+	ReviewDuration    Duration   `gorm:"not null;default:172800000000000"` // SLA duration for review phase (default 48h)
+	FixesDuration     Duration   `gorm:"not null;default:172800000000000"` // SLA duration for fixes phase (default 48h)
+	AssignCount       int        `gorm:"not null;default:1"`               // Number of reviewers to assign
+	ReassignThreshold int        `gorm:"not null;default:0"`               // Percent of review SLA a reviewer may stay inactive before auto-reassignment (0 = disabled)
+	MaxAutoReassigns  int        `gorm:"not null;default:1"`               // Cap on automatic reassignments per MR
+	MaxOpenReviews    int        `gorm:"not null;default:0"`               // Cap on a reviewer's concurrent open reviews (0 = unlimited)
+	ReviewerStrategy  string     `gorm:"type:varchar(20)"`                 // Reviewer selection strategy: weighted, round_robin, least_open, expertise (empty = weighted)
+	RequiredTier      string     `gorm:"type:varchar(20)"`                 // Reviewer tier every MR needs reviewers from (empty = none)
+	RequiredTierCount int        `gorm:"not null;default:0"`               // Number of reviewers required from RequiredTier
+	WaitForPipeline   bool       `gorm:"not null;default:false"`           // Hold reviewer assignment until the MR's first pipeline succeeds
+	AssignDrafts      bool       `gorm:"not null;default:false"`           // Assign reviewers to draft MRs instead of waiting until they leave draft
 }
 
 // Holiday stores holiday dates per repository for SLA calculation.
@@ -320,6 +354,26 @@ type AutoReleaseBranchConfig struct {
 	DevBranchName       string     `gorm:"not null"`
 }
 
+// PathReviewer links a changed-file glob to a user for path-based reviewer assignment.
+// When an MR changes files matching the pattern, these reviewers are picked like a label group.
+type PathReviewer struct {
+	gorm.Model
+	RepositoryID uint       `gorm:"not null;uniqueIndex:idx_path_reviewer_unique,priority:1"`
+	Repository   Repository `gorm:"constraint:OnDelete:CASCADE;"`
+	Pattern      string     `gorm:"not null;uniqueIndex:idx_path_reviewer_unique,priority:2"`
+	UserID       uint       `gorm:"not null;uniqueIndex:idx_path_reviewer_unique,priority:3"`
+	User         User       `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// CodeOwnersConfig enables CODEOWNERS-aware reviewer selection for a repository.
+// FilePath overrides the default CODEOWNERS lookup locations when set.
+type CodeOwnersConfig struct {
+	gorm.Model
+	RepositoryID uint       `gorm:"uniqueIndex;not null"`
+	Repository   Repository `gorm:"constraint:OnDelete:CASCADE;"`
+	FilePath     string
+}
+
 // ReleaseReadyLabel stores labels that mark MRs as ready for release.
 type ReleaseReadyLabel struct {
 	gorm.Model
@@ -359,19 +413,35 @@ type JiraProjectPrefix struct {
 type MRActionType string
 
 const (
-	ActionReviewerAssigned  MRActionType = "reviewer_assigned"
-	ActionReviewerRemoved   MRActionType = "reviewer_removed"
-	ActionCommentAdded      MRActionType = "comment_added"
-	ActionCommentResolved   MRActionType = "comment_resolved"
-	ActionApproved          MRActionType = "approved"
-	ActionUnapproved        MRActionType = "unapproved"
-	ActionDraftToggled      MRActionType = "draft_toggled"
-	ActionMerged            MRActionType = "merged"
-	ActionClosed            MRActionType = "closed"
+	ActionReviewerAssigned       MRActionType = "reviewer_assigned"
+	ActionReviewerRemoved        MRActionType = "reviewer_removed"
+	ActionCommentAdded           MRActionType = "comment_added"
+	ActionCommentResolved        MRActionType = "comment_resolved"
+	ActionApproved               MRActionType = "approved"
+	ActionUnapproved             MRActionType = "unapproved"
+	ActionDraftToggled           MRActionType = "draft_toggled"
+	ActionMerged                 MRActionType = "merged"
+	ActionClosed                 MRActionType = "closed"
 	ActionBlockLabelAdded        MRActionType = "block_label_added"
 	ActionBlockLabelRemoved      MRActionType = "block_label_removed"
-	ActionFullyApproved          MRActionType = "fully_approved"          // All reviewers have approved
+	ActionFullyApproved          MRActionType = "fully_approved" // All reviewers have approved
 	ActionReleaseReadyLabelAdded MRActionType = "release_ready_label_added"
+	ActionReviewerAutoReassigned MRActionType = "reviewer_auto_reassigned" // Stale reviewer was replaced automatically, counted against MaxAutoReassigns (metadata: reassigned_from)
+	ActionReviewersUnavailable   MRActionType = "reviewers_unavailable"    // Assignment fell short because pool members are at capacity
+	ActionReviewerInactive       MRActionType = "reviewer_inactive"        // Inactive reviewer could not be replaced and chats were warned (metadata: reason)
+	ActionSLAWarning             MRActionType = "sla_warning"              // SLA of the current state was exceeded and DMs were sent
+	ActionPipelineFailed         MRActionType = "pipeline_failed"          // Head pipeline failed
+	ActionPipelineFixed          MRActionType = "pipeline_fixed"           // Head pipeline succeeded after a failure
+	ActionConflictDetected       MRActionType = "conflict_detected"        // MR got merge conflicts or needs a rebase (metadata: reason)
+	ActionConflictResolved       MRActionType = "conflict_resolved"        // MR can be merged again
+	ActionCommitsPushed          MRActionType = "commits_pushed"           // Head SHA changed (metadata: from, to)
+	ActionStaleDetected          MRActionType = "stale_detected"           // MR had no activity for the repository's stale threshold and its author was DMed
+	ActionAutoMerged             MRActionType = "auto_merged"              // Bot merged the MR or set it to merge when the pipeline succeeds (metadata: sha, mode)
+	ActionAutoMergeFailed        MRActionType = "auto_merge_failed"        // GitLab refused the bot's merge (metadata: sha, reason)
+	ActionRebaseRequested        MRActionType = "rebase_requested"         // Bot started a rebase onto the target branch (metadata: sha)
+	ActionRebaseFailed           MRActionType = "rebase_failed"            // Bot's rebase failed and the author was DMed (metadata: sha, reason)
+	ActionRebased                MRActionType = "rebased"                  // Head SHA changed by a rebase the bot started (metadata: from, to)
+	ActionBlockRequested         MRActionType = "block_requested"          // Block label applied from chat with /mr block (metadata: reason)
 )
 
 // MRAction records timestamped actions for MR timeline tracking.
@@ -392,6 +462,164 @@ type MRAction struct {
 	Notified       bool         `gorm:"default:false;index"` // Whether DM notification was sent for this action
 }
 
+// SizeAssignRule raises the number of reviewers for MRs with at least MinLines changed lines.
+// The rule with the highest matching MinLines wins; it never lowers RepositorySLA.AssignCount.
+type SizeAssignRule struct {
+	gorm.Model
+	RepositoryID uint       `gorm:"not null;uniqueIndex:idx_size_rule_unique,priority:1"`
+	Repository   Repository `gorm:"constraint:OnDelete:CASCADE;"`
+	MinLines     int        `gorm:"not null;uniqueIndex:idx_size_rule_unique,priority:2"`
+	AssignCount  int        `gorm:"not null"`
+}
+
+// SizeLabelConfig enables automatic size/S, size/M, size/L and size/XL labels for a repository.
+type SizeLabelConfig struct {
+	gorm.Model
+	RepositoryID uint       `gorm:"uniqueIndex;not null"`
+	Repository   Repository `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// StaleConfig enables stale MR detection for a repository. Open MRs without commits, comments,
+// approvals or draft changes for AfterDays working days are reported weekly and their authors DMed.
+type StaleConfig struct {
+	gorm.Model
+	RepositoryID         uint       `gorm:"uniqueIndex;not null"`
+	Repository           Repository `gorm:"constraint:OnDelete:CASCADE;"`
+	AfterDays            int        `gorm:"not null;default:5"`     // Working days without activity before an MR is stale
+	ApplyLabel           bool       `gorm:"not null;default:false"` // Add the "stale" label to stale MRs
+	CloseDraftsAfterDays int        `gorm:"not null;default:0"`     // Working days after the stale DM before a stale draft is closed (0 = never)
+}
+
+// AutoMergeConfig enables auto-merge for a repository. Fully approved MRs carrying LabelName
+// with a green pipeline and no unresolved threads are merged by the bot.
+type AutoMergeConfig struct {
+	gorm.Model
+	RepositoryID uint       `gorm:"uniqueIndex;not null"`
+	Repository   Repository `gorm:"constraint:OnDelete:CASCADE;"`
+	LabelName    string     `gorm:"not null"`
+}
+
+// AutoRebaseConfig enables rebasing MRs that are behind their target branch but have no conflicts.
+type AutoRebaseConfig struct {
+	gorm.Model
+	RepositoryID uint       `gorm:"uniqueIndex;not null"`
+	Repository   Repository `gorm:"constraint:OnDelete:CASCADE;"`
+	MaxPerHour   int        `gorm:"not null;default:5"` // Rebases the bot starts per hour in the repository
+}
+
+// ReviewerPoolGroup binds a repository's default reviewer pool (empty LabelName) or a label pool
+// to a GitLab group. Group members with at least MinAccessLevel are periodically synced into
+// PossibleReviewer/LabelReviewer rows; synced rows of users who left the group are removed.
+type ReviewerPoolGroup struct {
+	gorm.Model
+	RepositoryID   uint       `gorm:"not null;uniqueIndex:idx_pool_group_unique,priority:1"`
+	Repository     Repository `gorm:"constraint:OnDelete:CASCADE;"`
+	LabelName      string     `gorm:"not null;default:'';uniqueIndex:idx_pool_group_unique,priority:2"` // Empty = default pool
+	GroupPath      string     `gorm:"not null;uniqueIndex:idx_pool_group_unique,priority:3"`
+	MinAccessLevel int        `gorm:"not null;default:30"` // GitLab access level (30 = developer)
+	SyncedAt       *time.Time
+}
+
+// ReviewerPoolExclusion keeps a user out of the group-synced reviewer pools of a repository.
+type ReviewerPoolExclusion struct {
+	gorm.Model
+	RepositoryID uint       `gorm:"not null;uniqueIndex:idx_pool_exclusion_unique,priority:1"`
+	Repository   Repository `gorm:"constraint:OnDelete:CASCADE;"`
+	UserID       uint       `gorm:"not null;uniqueIndex:idx_pool_exclusion_unique,priority:2"`
+	User         User       `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// NotificationPreference stores which DM notifications a user receives and their quiet hours.
+// Users without a row receive every notification.
+type NotificationPreference struct {
+	gorm.Model
+	UserID          uint `gorm:"uniqueIndex;not null"`
+	User            User `gorm:"constraint:OnDelete:CASCADE;"`
+	Assignment      bool `gorm:"not null;default:true"`
+	StateChange     bool `gorm:"not null;default:true"`
+	FullyApproved   bool `gorm:"not null;default:true"`
+	ReviewerRemoved bool `gorm:"not null;default:true"`
+	Release         bool `gorm:"not null;default:true"`
+	SLAWarning      bool `gorm:"not null;default:true"`
+	Pipeline        bool `gorm:"not null;default:true"`
+	Conflict        bool `gorm:"not null;default:true"`
+	Stale           bool `gorm:"not null;default:true"`
+	AutoMerge       bool `gorm:"not null;default:true"`
+	QuietStart      int  `gorm:"not null;default:-1"` // Hour quiet hours start in the user's timezone (-1 = no quiet hours)
+	QuietEnd        int  `gorm:"not null;default:-1"` // Hour quiet hours end (exclusive)
+	TimezoneOffset  int  `gorm:"not null;default:3"`  // Hours from UTC
+}
+
+// QueuedNotification is a DM held back during the recipient's quiet hours and sent in one batch afterwards.
+type QueuedNotification struct {
+	gorm.Model
+	UserID uint   `gorm:"not null;index"`
+	User   User   `gorm:"constraint:OnDelete:CASCADE;"`
+	Text   string `gorm:"type:text;not null"`
+}
+
+// Team is a named group of users managed in the bot. Teams can be referenced by reviewer pools,
+// label pools and release managers (see TeamBinding) and get their own digest.
+type Team struct {
+	gorm.Model
+	Name             string `gorm:"uniqueIndex;not null"`
+	LeadID           *uint
+	Lead             *User `gorm:"constraint:OnDelete:SET NULL;"`
+	TimezoneOffset   int   `gorm:"default:3"` // Hours from UTC, used to schedule the team digest
+	ChatID           *uint // Chat receiving the team digest
+	Chat             *Chat `gorm:"constraint:OnDelete:SET NULL;"`
+	DigestLastSentAt *time.Time
+	Members          []User `gorm:"many2many:team_members"`
+}
+
+// Team binding roles.
+const (
+	TeamRoleReviewers       = "reviewers"
+	TeamRoleLabelReviewers  = "label_reviewers"
+	TeamRoleReleaseManagers = "release_managers"
+)
+
+// TeamBinding references a team from a repository's default pool, a label pool or release managers.
+// Team members are synced into PossibleReviewer/LabelReviewer/ReleaseManager rows with TeamID set.
+type TeamBinding struct {
+	gorm.Model
+	TeamID       uint       `gorm:"not null;uniqueIndex:idx_team_binding_unique,priority:1"`
+	Team         Team       `gorm:"constraint:OnDelete:CASCADE;"`
+	RepositoryID uint       `gorm:"not null;uniqueIndex:idx_team_binding_unique,priority:2"`
+	Repository   Repository `gorm:"constraint:OnDelete:CASCADE;"`
+	Role         string     `gorm:"type:varchar(20);not null;uniqueIndex:idx_team_binding_unique,priority:3"`
+	LabelName    string     `gorm:"not null;default:'';uniqueIndex:idx_team_binding_unique,priority:4"` // Only for label_reviewers
+}
+
+// RepositoryMember records a direct or inherited GitLab project member of a subscribed repository.
+// Synced periodically; users missing from a synced repository are not assigned and are replaced as reviewers.
+type RepositoryMember struct {
+	gorm.Model
+	RepositoryID uint       `gorm:"not null;uniqueIndex:idx_repository_member_unique,priority:1"`
+	Repository   Repository `gorm:"constraint:OnDelete:CASCADE;"`
+	UserID       uint       `gorm:"not null;uniqueIndex:idx_repository_member_unique,priority:2"`
+	User         User       `gorm:"constraint:OnDelete:CASCADE;"`
+	AccessLevel  int        `gorm:"not null;default:0"` // GitLab access level (10 guest .. 50 owner)
+}
+
+// MergeRequestFile records a path changed by an MR.
+// Saved with the diff stats of open MRs; the expertise selection strategy scores reviewers by prior reviews of the same files.
+type MergeRequestFile struct {
+	gorm.Model
+	MergeRequestID uint         `gorm:"not null;uniqueIndex:idx_mr_file_unique,priority:1"`
+	MergeRequest   MergeRequest `gorm:"constraint:OnDelete:CASCADE;"`
+	Path           string       `gorm:"not null;uniqueIndex:idx_mr_file_unique,priority:2;index"`
+}
+
+// ReviewerSelectionTrace stores the explanation of one reviewer assignment round.
+// Trace is JSON with candidate pools, exclusions, matched label groups and pick probabilities.
+type ReviewerSelectionTrace struct {
+	gorm.Model
+	MergeRequestID uint         `gorm:"not null;index"`
+	MergeRequest   MergeRequest `gorm:"constraint:OnDelete:CASCADE;"`
+	Trace          string       `gorm:"type:text"`
+}
+
 type MRComment struct {
 	gorm.Model
 	MergeRequestID     uint         `gorm:"not null;index"`
@@ -466,7 +694,7 @@ type DeployTrackingRule struct {
 type TrackedDeployJob struct {
 	gorm.Model
 	DeployTrackingRuleID uint               `gorm:"not null;index"`
-	DeployTrackingRule   DeployTrackingRule  `gorm:"constraint:OnDelete:CASCADE;"`
+	DeployTrackingRule   DeployTrackingRule `gorm:"constraint:OnDelete:CASCADE;"`
 	GitlabJobID          int                `gorm:"not null;uniqueIndex"`
 	Status               string             `gorm:"not null"`
 	Ref                  string
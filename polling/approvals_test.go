@@ -0,0 +1,92 @@
+package polling
+
+import (
+	"reflect"
+	"testing"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// TestApprovalRulesState tests how GitLab's approvals response maps to the stored approval rules state.
+func TestApprovalRulesState(t *testing.T) {
+	tests := []struct {
+		name          string
+		approvals     *gitlab.MergeRequestApprovals
+		wantSatisfied bool
+		wantLeft      int
+		wantMissing   []string
+	}{
+		{
+			name:          "no rules",
+			approvals:     &gitlab.MergeRequestApprovals{},
+			wantSatisfied: true,
+		},
+		{
+			name: "rules left",
+			approvals: &gitlab.MergeRequestApprovals{
+				ApprovalsLeft: 2,
+				ApprovalRulesLeft: []*gitlab.MergeRequestApprovalRule{
+					{Name: "Backend", RuleType: "regular"},
+					{Name: "*.go", RuleType: "code_owner"},
+				},
+			},
+			wantLeft:    2,
+			wantMissing: []string{"Backend", "*.go"},
+		},
+		{
+			name:      "approvals left without rules",
+			approvals: &gitlab.MergeRequestApprovals{ApprovalsRequired: 2, ApprovalsLeft: 1},
+			wantLeft:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			satisfied, left, missing := approvalRulesState(tt.approvals)
+			if satisfied != tt.wantSatisfied || left != tt.wantLeft || !reflect.DeepEqual(missing, tt.wantMissing) {
+				t.Errorf("approvalRulesState() = %v, %d, %v, want %v, %d, %v",
+					satisfied, left, missing, tt.wantSatisfied, tt.wantLeft, tt.wantMissing)
+			}
+		})
+	}
+}
+
+// TestCheckAndRecordFullyApproved_ApprovalRules tests that an MR approved by every reviewer is only
+// recorded as fully approved once GitLab's approval rules are satisfied.
+func TestCheckAndRecordFullyApproved_ApprovalRules(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	reviewer := userFactory.Create()
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author)
+	db.Model(&mr).UpdateColumns(map[string]interface{}{
+		"approval_rules_satisfied": false,
+		"approvals_left":           1,
+		"missing_approval_rules":   "Backend",
+	})
+
+	countFullyApproved := func() int64 {
+		var count int64
+		db.Model(&models.MRAction{}).Where("merge_request_id = ? AND action_type = ?", mr.ID, models.ActionFullyApproved).Count(&count)
+		return count
+	}
+
+	checkAndRecordFullyApproved(db, mr.ID, []models.User{reviewer}, []models.User{reviewer})
+	if got := countFullyApproved(); got != 0 {
+		t.Fatalf("expected no fully_approved action while approval rules are pending, got %d", got)
+	}
+
+	db.Model(&mr).UpdateColumns(map[string]interface{}{
+		"approval_rules_satisfied": true,
+		"approvals_left":           0,
+		"missing_approval_rules":   "",
+	})
+	checkAndRecordFullyApproved(db, mr.ID, []models.User{reviewer}, []models.User{reviewer})
+	if got := countFullyApproved(); got != 1 {
+		t.Errorf("expected one fully_approved action once approval rules are satisfied, got %d", got)
+	}
+}
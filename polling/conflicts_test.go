@@ -0,0 +1,60 @@
+package polling
+
+import (
+	"testing"
+	"time"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// TestDetectMergeBlockerChange tests that conflicts and rebases are recorded once per transition
+// and that a "checking" merge status in between does not count as a resolution.
+func TestDetectMergeBlockerChange(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := testutils.NewUserFactory(db).Create()
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author)
+
+	actionTypes := func() []models.MRActionType {
+		var actions []models.MRAction
+		db.Where("merge_request_id = ?", mr.ID).Order("timestamp ASC, id ASC").Find(&actions)
+		types := make([]models.MRActionType, len(actions))
+		for i, a := range actions {
+			types[i] = a.ActionType
+		}
+		return types
+	}
+
+	start := time.Now().UTC()
+	steps := []*gitlab.BasicMergeRequest{
+		{State: "opened", DetailedMergeStatus: "mergeable"},
+		{State: "opened", HasConflicts: true, DetailedMergeStatus: "conflict"},
+		{State: "opened", DetailedMergeStatus: "checking"},
+		{State: "opened", HasConflicts: true, DetailedMergeStatus: "conflict"},
+		{State: "opened", DetailedMergeStatus: "need_rebase"},
+		{State: "opened", DetailedMergeStatus: "mergeable"},
+	}
+	for i, step := range steps {
+		detectMergeBlockerChange(db, mr.ID, step, start.Add(time.Duration(i)*time.Minute))
+	}
+
+	want := []models.MRActionType{models.ActionConflictDetected, models.ActionConflictDetected, models.ActionConflictResolved}
+	got := actionTypes()
+	if len(got) != len(want) {
+		t.Fatalf("expected actions %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected actions %v, got %v", want, got)
+		}
+	}
+
+	var rebase models.MRAction
+	db.Where("merge_request_id = ? AND action_type = ?", mr.ID, models.ActionConflictDetected).Order("timestamp DESC").First(&rebase)
+	if rebase.Metadata != `{"reason":"need_rebase"}` {
+		t.Errorf("expected need_rebase reason, got %s", rebase.Metadata)
+	}
+}
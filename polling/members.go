@@ -0,0 +1,97 @@
+package polling
+
+import (
+	"log"
+	"time"
+
+	"devstreamlinebot/models"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"gorm.io/gorm"
+)
+
+// membersSyncInterval is how often members of a subscribed repository are refreshed.
+const membersSyncInterval = time.Hour
+
+// PollRepositoryMembers refreshes project members (including inherited ones) of subscribed
+// repositories and the account state of those users.
+func PollRepositoryMembers(db *gorm.DB, client *gitlab.Client) {
+	var repos []models.Repository
+	if err := db.
+		Where("EXISTS (SELECT 1 FROM repository_subscriptions WHERE repository_subscriptions.repository_id = repositories.id AND repository_subscriptions.deleted_at IS NULL)").
+		Where("members_synced_at IS NULL OR members_synced_at < ?", time.Now().Add(-membersSyncInterval)).
+		Find(&repos).Error; err != nil {
+		log.Printf("failed to fetch repositories for member sync: %v", err)
+		return
+	}
+
+	for _, repo := range repos {
+		var members []*gitlab.ProjectMember
+		opts := &gitlab.ListProjectMembersOptions{ListOptions: gitlab.ListOptions{PerPage: 100, Page: 1}}
+		failed := false
+		for {
+			page, resp, err := client.ProjectMembers.ListAllProjectMembers(repo.GitlabID, opts)
+			if err != nil {
+				log.Printf("failed to list members of repository %d: %v", repo.GitlabID, err)
+				failed = true
+				break
+			}
+			members = append(members, page...)
+			if resp.NextPage == 0 {
+				break
+			}
+			opts.Page = resp.NextPage
+		}
+		if failed {
+			continue
+		}
+
+		if err := syncRepositoryMembers(db, repo.ID, members); err != nil {
+			log.Printf("failed to store members of repository %d: %v", repo.GitlabID, err)
+		}
+	}
+}
+
+// syncRepositoryMembers upserts member users (with their account state) and replaces the
+// repository's member list. An empty list is ignored so a misconfigured token cannot
+// mark everyone as a non-member.
+func syncRepositoryMembers(db *gorm.DB, repoID uint, members []*gitlab.ProjectMember) error {
+	if len(members) == 0 {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		rows := make([]models.RepositoryMember, 0, len(members))
+		rowIndex := make(map[uint]int)
+		for _, m := range members {
+			var u models.User
+			userData := models.User{
+				GitlabID:  m.ID,
+				Username:  m.Username,
+				Name:      m.Name,
+				State:     m.State,
+				AvatarURL: m.AvatarURL,
+				WebURL:    m.WebURL,
+			}
+			if err := tx.Where(models.User{GitlabID: m.ID}).Assign(userData).FirstOrCreate(&u).Error; err != nil {
+				return err
+			}
+			if i, ok := rowIndex[u.ID]; ok {
+				if int(m.AccessLevel) > rows[i].AccessLevel {
+					rows[i].AccessLevel = int(m.AccessLevel)
+				}
+				continue
+			}
+			rowIndex[u.ID] = len(rows)
+			rows = append(rows, models.RepositoryMember{RepositoryID: repoID, UserID: u.ID, AccessLevel: int(m.AccessLevel)})
+		}
+
+		if err := tx.Unscoped().Where("repository_id = ?", repoID).Delete(&models.RepositoryMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&rows).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Repository{}).Where("id = ?", repoID).Update("members_synced_at", time.Now()).Error
+	})
+}
@@ -0,0 +1,50 @@
+package polling
+
+import (
+	"testing"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// TestSyncRepositoryMembers tests member replacement, user state updates and that empty lists are ignored.
+func TestSyncRepositoryMembers(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	leaver := testutils.NewUserFactory(db).Create(testutils.WithGitlabID(7))
+	db.Create(&models.RepositoryMember{RepositoryID: repo.ID, UserID: leaver.ID})
+
+	members := []*gitlab.ProjectMember{
+		{ID: 1, Username: "alice", State: "active", AccessLevel: gitlab.DeveloperPermissions},
+		{ID: 1, Username: "alice", State: "active", AccessLevel: gitlab.MaintainerPermissions},
+		{ID: 2, Username: "bob", State: "blocked", AccessLevel: gitlab.DeveloperPermissions},
+	}
+	if err := syncRepositoryMembers(db, repo.ID, members); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var rows []models.RepositoryMember
+	db.Preload("User").Where("repository_id = ?", repo.ID).Order("id").Find(&rows)
+	if len(rows) != 2 || rows[0].User.Username != "alice" || rows[0].AccessLevel != int(gitlab.MaintainerPermissions) {
+		t.Fatalf("expected alice and bob with highest access, got %+v", rows)
+	}
+	if rows[1].User.State != "blocked" {
+		t.Errorf("expected bob's state synced, got %q", rows[1].User.State)
+	}
+	var reloaded models.Repository
+	db.First(&reloaded, repo.ID)
+	if reloaded.MembersSyncedAt == nil {
+		t.Error("expected members_synced_at set")
+	}
+
+	if err := syncRepositoryMembers(db, repo.ID, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var count int64
+	db.Model(&models.RepositoryMember{}).Where("repository_id = ?", repo.ID).Count(&count)
+	if count != 2 {
+		t.Errorf("expected empty member list ignored, got %d rows", count)
+	}
+}
@@ -0,0 +1,33 @@
+package polling
+
+import (
+	"testing"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+// TestReplaceMRFiles tests that the recorded changed paths follow the MR's latest diff.
+func TestReplaceMRFiles(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := testutils.NewUserFactory(db).Create()
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author)
+
+	paths := func() []string {
+		var got []string
+		db.Model(&models.MergeRequestFile{}).Where("merge_request_id = ?", mr.ID).Order("path").Pluck("path", &got)
+		return got
+	}
+
+	replaceMRFiles(db, mr.ID, []string{"a.go", "b.go"})
+	replaceMRFiles(db, mr.ID, []string{"b.go", "c.go"})
+	if got := paths(); len(got) != 2 || got[0] != "b.go" || got[1] != "c.go" {
+		t.Errorf("expected [b.go c.go], got %v", got)
+	}
+
+	replaceMRFiles(db, mr.ID, nil)
+	if got := paths(); len(got) != 0 {
+		t.Errorf("expected no paths, got %v", got)
+	}
+}
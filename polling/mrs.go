@@ -1,6 +1,7 @@
 package polling
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"regexp"
@@ -8,9 +9,11 @@ import (
 	"time"
 
 	"devstreamlinebot/models"
+	"devstreamlinebot/utils"
 
 	gitlab "gitlab.com/gitlab-org/api/client-go"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // recordMRAction creates an MRAction entry with duplicate detection.
@@ -181,6 +184,74 @@ func detectAndRecordStateChanges(db *gorm.DB, existingMR *models.MergeRequest, n
 		}
 		recordMRAction(db, localMRID, models.ActionClosed, nil, nil, nil, timestamp, "")
 	}
+
+	if existingMR != nil && newMR.State == "opened" && existingMR.SHA != "" && newMR.SHA != "" && existingMR.SHA != newMR.SHA {
+		metadata := fmt.Sprintf(`{"from":%q,"to":%q}`, existingMR.SHA, newMR.SHA)
+		if rebasedByBot(db, localMRID, existingMR.SHA) {
+			recordMRAction(db, localMRID, models.ActionRebased, nil, nil, nil, now, metadata)
+		} else {
+			recordMRAction(db, localMRID, models.ActionCommitsPushed, &existingMR.AuthorID, nil, nil, now, metadata)
+		}
+	}
+
+	if existingMR != nil && newMR.State == "opened" {
+		detectMergeBlockerChange(db, localMRID, newMR, now)
+	}
+}
+
+// rebasedByBot reports whether the bot started a rebase of the given head commit that did not fail,
+// so a new head SHA is the rebase's result rather than a push by the author.
+func rebasedByBot(db *gorm.DB, mrID uint, sha string) bool {
+	var actions []models.MRAction
+	db.Where("merge_request_id = ? AND action_type IN ?", mrID,
+		[]models.MRActionType{models.ActionRebaseRequested, models.ActionRebaseFailed}).
+		Order("timestamp ASC, id ASC").
+		Find(&actions)
+
+	rebased := false
+	for _, action := range actions {
+		var meta struct {
+			SHA string `json:"sha"`
+		}
+		if err := json.Unmarshal([]byte(action.Metadata), &meta); err != nil || meta.SHA != sha {
+			continue
+		}
+		rebased = action.ActionType == models.ActionRebaseRequested
+	}
+	return rebased
+}
+
+// detectMergeBlockerChange records conflict_detected when the MR gets conflicts or needs a rebase
+// and conflict_resolved when it can be merged again. The previous state is taken from the last
+// recorded action, so a transient "checking" merge status does not produce duplicates.
+func detectMergeBlockerChange(db *gorm.DB, localMRID uint, newMR *gitlab.BasicMergeRequest, now time.Time) {
+	if !newMR.HasConflicts && utils.IsMergeStatusChecking(newMR.DetailedMergeStatus) {
+		return
+	}
+
+	var last models.MRAction
+	previous := ""
+	err := db.Where("merge_request_id = ? AND action_type IN ?", localMRID,
+		[]models.MRActionType{models.ActionConflictDetected, models.ActionConflictResolved}).
+		Order("timestamp DESC, id DESC").
+		First(&last).Error
+	if err == nil && last.ActionType == models.ActionConflictDetected {
+		previous = last.Metadata
+	}
+
+	reason := utils.MergeBlocker(newMR.HasConflicts, newMR.DetailedMergeStatus)
+	current := ""
+	if reason != "" {
+		current = fmt.Sprintf(`{"reason":%q}`, reason)
+	}
+	if current == previous {
+		return
+	}
+	if current == "" {
+		recordMRAction(db, localMRID, models.ActionConflictResolved, nil, nil, nil, now, previous)
+		return
+	}
+	recordMRAction(db, localMRID, models.ActionConflictDetected, nil, nil, nil, now, current)
 }
 
 func syncMRDiscussions(db *gorm.DB, client *gitlab.Client, projectID int, mrIID int, localMRID uint) {
@@ -332,11 +403,19 @@ func syncMRDiscussions(db *gorm.DB, client *gitlab.Client, projectID int, mrIID
 	}
 }
 
+// checkAndRecordFullyApproved records ActionFullyApproved once every reviewer approved the MR
+// and GitLab's approval rules are satisfied.
 func checkAndRecordFullyApproved(db *gorm.DB, mrID uint, reviewers []models.User, approvers []models.User) {
 	if len(reviewers) == 0 {
 		return
 	}
 
+	var rulesPending int64
+	db.Model(&models.MergeRequest{}).Where("id = ? AND approval_rules_satisfied = ?", mrID, false).Count(&rulesPending)
+	if rulesPending > 0 {
+		return // GitLab still requires approvals
+	}
+
 	approverIDs := make(map[uint]bool)
 	for _, a := range approvers {
 		approverIDs[a.ID] = true
@@ -357,6 +436,33 @@ func checkAndRecordFullyApproved(db *gorm.DB, mrID uint, reviewers []models.User
 	log.Printf("MR %d is now fully approved", mrID)
 }
 
+// approvalResetByPush reports whether commits were pushed after the user's last approval,
+// i.e. the approval was most likely reset by GitLab rather than revoked.
+func approvalResetByPush(db *gorm.DB, mrID uint, userID uint) bool {
+	return headChangedSinceApproval(db, mrID, userID, models.ActionCommitsPushed)
+}
+
+// approvalResetByRebase reports whether a rebase by the bot changed the head after the user's last
+// approval without any push by the author.
+func approvalResetByRebase(db *gorm.DB, mrID uint, userID uint) bool {
+	return headChangedSinceApproval(db, mrID, userID, models.ActionRebased)
+}
+
+// headChangedSinceApproval reports whether an action of the given type follows the user's last approval.
+func headChangedSinceApproval(db *gorm.DB, mrID uint, userID uint, actionType models.MRActionType) bool {
+	var approved models.MRAction
+	if err := db.Where("merge_request_id = ? AND action_type = ? AND actor_id = ?", mrID, models.ActionApproved, userID).
+		Order("timestamp DESC").
+		First(&approved).Error; err != nil {
+		return false
+	}
+	var changes int64
+	db.Model(&models.MRAction{}).
+		Where("merge_request_id = ? AND action_type = ? AND timestamp > ?", mrID, actionType, approved.Timestamp).
+		Count(&changes)
+	return changes > 0
+}
+
 func syncMRApprovals(db *gorm.DB, client *gitlab.Client, projectID int, mrIID int, localMRID uint) []models.User {
 	approvals, _, err := client.MergeRequests.GetMergeRequestApprovals(projectID, mrIID)
 	if err != nil {
@@ -368,6 +474,15 @@ func syncMRApprovals(db *gorm.DB, client *gitlab.Client, projectID int, mrIID in
 		return nil
 	}
 
+	satisfied, left, missing := approvalRulesState(approvals)
+	if err := db.Model(&models.MergeRequest{}).Where("id = ?", localMRID).UpdateColumns(map[string]interface{}{
+		"approval_rules_satisfied": satisfied,
+		"approvals_left":           left,
+		"missing_approval_rules":   strings.Join(missing, ", "),
+	}).Error; err != nil {
+		log.Printf("Error saving approval rules state for MR %d: %v", localMRID, err)
+	}
+
 	var existingApprovers []models.User
 	db.Model(&models.MergeRequest{Model: gorm.Model{ID: localMRID}}).Association("Approvers").Find(&existingApprovers)
 	existingApproverIDs := make(map[int]bool)
@@ -413,16 +528,102 @@ func syncMRApprovals(db *gorm.DB, client *gitlab.Client, projectID int, mrIID in
 			}
 		}
 		if !found {
-			recordMRAction(db, localMRID, models.ActionUnapproved, &existing.ID, nil, nil, time.Now().UTC(), "")
+			metadata := ""
+			if approvalResetByPush(db, localMRID, existing.ID) {
+				metadata = `{"reason":"commits_pushed"}`
+			} else if approvalResetByRebase(db, localMRID, existing.ID) {
+				metadata = `{"reason":"rebased"}`
+			}
+			recordMRAction(db, localMRID, models.ActionUnapproved, &existing.ID, nil, nil, time.Now().UTC(), metadata)
 		}
 	}
 
 	return approverUsers
 }
 
+// approvalRulesState reports whether GitLab's approval rules are satisfied, how many approvals
+// are still required and the names of the rules that still need approvals.
+func approvalRulesState(approvals *gitlab.MergeRequestApprovals) (bool, int, []string) {
+	var missing []string
+	for _, rule := range approvals.ApprovalRulesLeft {
+		if rule == nil || rule.Name == "" {
+			continue
+		}
+		missing = append(missing, rule.Name)
+	}
+	return approvals.ApprovalsLeft == 0 && len(approvals.ApprovalRulesLeft) == 0, approvals.ApprovalsLeft, missing
+}
+
+// syncMRDiffStats counts changed lines and files of an MR and stores them for the given head SHA.
+// The changed paths are saved too, for the expertise reviewer strategy.
+func syncMRDiffStats(db *gorm.DB, client *gitlab.Client, projectID int, mrIID int, localMRID uint, sha string) {
+	opts := &gitlab.ListMergeRequestDiffsOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100, Page: 1},
+	}
+
+	var added, removed, files int
+	var paths []string
+	for {
+		diffs, resp, err := client.MergeRequests.ListMergeRequestDiffs(projectID, mrIID, opts)
+		if err != nil {
+			log.Printf("Failed to fetch MR diffs for project %d MR IID %d: %v", projectID, mrIID, err)
+			return
+		}
+		for _, d := range diffs {
+			a, r := utils.CountDiffLines(d.Diff)
+			added += a
+			removed += r
+			files++
+			paths = append(paths, d.NewPath)
+			if d.RenamedFile && d.OldPath != d.NewPath {
+				paths = append(paths, d.OldPath)
+			}
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	if err := db.Model(&models.MergeRequest{}).Where("id = ?", localMRID).UpdateColumns(map[string]interface{}{
+		"lines_added":    added,
+		"lines_removed":  removed,
+		"files_changed":  files,
+		"diff_stats_sha": sha,
+	}).Error; err != nil {
+		log.Printf("Error saving diff stats for MR %d: %v", localMRID, err)
+	}
+	replaceMRFiles(db, localMRID, paths)
+}
+
+// replaceMRFiles sets the changed paths recorded for an MR.
+func replaceMRFiles(db *gorm.DB, localMRID uint, paths []string) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		stale := tx.Unscoped().Where("merge_request_id = ?", localMRID)
+		if len(paths) > 0 {
+			stale = stale.Where("path NOT IN ?", paths)
+		}
+		if err := stale.Delete(&models.MergeRequestFile{}).Error; err != nil {
+			return err
+		}
+		if len(paths) == 0 {
+			return nil
+		}
+		files := make([]models.MergeRequestFile, len(paths))
+		for i, p := range paths {
+			files[i] = models.MergeRequestFile{MergeRequestID: localMRID, Path: p}
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&files, 100).Error
+	})
+	if err != nil {
+		log.Printf("Error saving changed files for MR %d: %v", localMRID, err)
+	}
+}
+
 func syncGitLabMRToDB(db *gorm.DB, client *gitlab.Client, mr *gitlab.BasicMergeRequest, localRepositoryID uint, gitlabProjectID int, jiraPattern *regexp.Regexp) (uint, error) {
 	var mrModelID uint
 	var reviewersToAssociate []models.User
+	var statsCurrent, pipelineCurrent bool
 	now := time.Now().UTC()
 
 	err := db.Transaction(func(tx *gorm.DB) error {
@@ -535,6 +736,23 @@ func syncGitLabMRToDB(db *gorm.DB, client *gitlab.Client, mr *gitlab.BasicMergeR
 		} else {
 			detectAndRecordStateChanges(tx, &existingMR, mr, existingMR.ID)
 
+			// Diff stats are refreshed outside the sync transaction; keep them while the head SHA is unchanged.
+			if existingMR.DiffStatsSHA != "" && existingMR.DiffStatsSHA == mr.SHA {
+				mrModel.LinesAdded = existingMR.LinesAdded
+				mrModel.LinesRemoved = existingMR.LinesRemoved
+				mrModel.FilesChanged = existingMR.FilesChanged
+				mrModel.DiffStatsSHA = existingMR.DiffStatsSHA
+			}
+			// Pipeline status is refreshed outside the sync transaction as well.
+			mrModel.PipelineID = existingMR.PipelineID
+			mrModel.PipelineStatus = existingMR.PipelineStatus
+			mrModel.PipelineSHA = existingMR.PipelineSHA
+			mrModel.PipelineWebURL = existingMR.PipelineWebURL
+			// So are the approval rules.
+			mrModel.ApprovalRulesSatisfied = existingMR.ApprovalRulesSatisfied
+			mrModel.ApprovalsLeft = existingMR.ApprovalsLeft
+			mrModel.MissingApprovalRules = existingMR.MissingApprovalRules
+
 			mrModel.ID = existingMR.ID
 			if err := tx.Model(&existingMR).Select("*").Updates(mrModel).Error; err != nil {
 				log.Printf("Error updating merge request GitlabID %d: %v", mrModel.GitlabID, err)
@@ -586,6 +804,7 @@ func syncGitLabMRToDB(db *gorm.DB, client *gitlab.Client, mr *gitlab.BasicMergeR
 				Username:  rv.Username,
 				Name:      rv.Name,
 				State:     rv.State,
+				Locked:    rv.Locked,
 				AvatarURL: rv.AvatarURL,
 				WebURL:    rv.WebURL,
 			}
@@ -593,6 +812,12 @@ func syncGitLabMRToDB(db *gorm.DB, client *gitlab.Client, mr *gitlab.BasicMergeR
 				log.Printf("Error upserting reviewer GitlabID %d for MR GitlabID %d: %v", rv.ID, mr.ID, err)
 				return fmt.Errorf("upserting reviewer GitlabID %d for MR GitlabID %d: %w", rv.ID, mr.ID, err)
 			}
+			if u.Locked && !rv.Locked {
+				// Assign skips zero values, so unlocking has to be written explicitly.
+				if err := tx.Model(&u).Update("locked", false).Error; err != nil {
+					log.Printf("Error unlocking reviewer GitlabID %d: %v", rv.ID, err)
+				}
+			}
 			reviewersToAssociate = append(reviewersToAssociate, u)
 
 			if existingReviewerIDs != nil && !existingReviewerIDs[rv.ID] {
@@ -622,6 +847,12 @@ func syncGitLabMRToDB(db *gorm.DB, client *gitlab.Client, mr *gitlab.BasicMergeR
 		}
 
 		mrModelID = mrModel.ID
+		statsCurrent = mrModel.DiffStatsSHA != ""
+		// A head commit checked without a pipeline is current unless the repository uses CI,
+		// where a pipeline may still start for it.
+		pipelineCurrent = mrModel.PipelineSHA == mr.SHA &&
+			(utils.IsPipelineFinished(mrModel.PipelineStatus) ||
+				(mrModel.PipelineStatus == "" && !utils.RepositoryHasCI(tx, localRepositoryID)))
 		return nil
 	})
 
@@ -640,6 +871,14 @@ func syncGitLabMRToDB(db *gorm.DB, client *gitlab.Client, mr *gitlab.BasicMergeR
 
 		checkAndRecordFullyApproved(db, mrModelID, reviewersToAssociate, approverUsers)
 
+		if !statsCurrent && mr.SHA != "" {
+			syncMRDiffStats(db, client, gitlabProjectID, mr.IID, mrModelID, mr.SHA)
+		}
+
+		if !pipelineCurrent && mr.SHA != "" {
+			syncMRPipeline(db, client, gitlabProjectID, mr.IID, mrModelID)
+		}
+
 		syncMRDiscussions(db, client, gitlabProjectID, mr.IID, mrModelID)
 	} else {
 		if err := db.Model(&models.MergeRequest{Model: gorm.Model{ID: mrModelID}}).Association("Approvers").Clear(); err != nil {
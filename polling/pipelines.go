@@ -0,0 +1,63 @@
+package polling
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"gorm.io/gorm"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/utils"
+)
+
+// syncMRPipeline refreshes the MR's head pipeline. The list endpoint carries no pipeline,
+// so it is read from the single-MR endpoint.
+func syncMRPipeline(db *gorm.DB, client *gitlab.Client, projectID int, mrIID int, localMRID uint) {
+	fullMR, _, err := client.MergeRequests.GetMergeRequest(projectID, mrIID, nil)
+	if err != nil {
+		log.Printf("Failed to fetch head pipeline for project %d MR IID %d: %v", projectID, mrIID, err)
+		return
+	}
+	applyPipelineStatus(db, localMRID, fullMR.SHA, fullMR.HeadPipeline)
+}
+
+// applyPipelineStatus stores the head pipeline of an MR and records pipeline_failed when it turns
+// red and pipeline_fixed when it turns green after a failure. A nil pipeline clears the status and
+// stores the checked head SHA, so MRs without CI are not fetched again until the next push.
+func applyPipelineStatus(db *gorm.DB, localMRID uint, checkedSHA string, pipeline *gitlab.Pipeline) {
+	var mr models.MergeRequest
+	if err := db.Select("id", "pipeline_id", "pipeline_status").First(&mr, localMRID).Error; err != nil {
+		log.Printf("Error loading MR %d for pipeline status: %v", localMRID, err)
+		return
+	}
+
+	updates := map[string]interface{}{
+		"pipeline_id":      0,
+		"pipeline_status":  "",
+		"pipeline_sha":     checkedSHA,
+		"pipeline_web_url": "",
+	}
+	if pipeline != nil {
+		updates["pipeline_id"] = pipeline.ID
+		updates["pipeline_status"] = pipeline.Status
+		updates["pipeline_sha"] = pipeline.SHA
+		updates["pipeline_web_url"] = pipeline.WebURL
+	}
+	if err := db.Model(&models.MergeRequest{}).Where("id = ?", localMRID).UpdateColumns(updates).Error; err != nil {
+		log.Printf("Error saving pipeline status for MR %d: %v", localMRID, err)
+		return
+	}
+
+	if pipeline == nil || pipeline.Status == mr.PipelineStatus {
+		return
+	}
+	metadata := fmt.Sprintf(`{"pipeline_id":%d,"web_url":%q}`, pipeline.ID, pipeline.WebURL)
+	switch {
+	case pipeline.Status == utils.PipelineFailed:
+		recordMRAction(db, localMRID, models.ActionPipelineFailed, nil, nil, nil, time.Now().UTC(), metadata)
+	case pipeline.Status == utils.PipelineSuccess && mr.PipelineStatus == utils.PipelineFailed:
+		recordMRAction(db, localMRID, models.ActionPipelineFixed, nil, nil, nil, time.Now().UTC(), metadata)
+	}
+}
@@ -0,0 +1,53 @@
+package polling
+
+import (
+	"testing"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// TestApplyPipelineStatus tests that a failure is recorded once, a later success is recorded as a fix,
+// and the pipeline fields are stored on the MR.
+func TestApplyPipelineStatus(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := testutils.NewUserFactory(db).Create()
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author)
+
+	countActions := func(actionType models.MRActionType) int64 {
+		var count int64
+		db.Model(&models.MRAction{}).Where("merge_request_id = ? AND action_type = ?", mr.ID, actionType).Count(&count)
+		return count
+	}
+
+	applyPipelineStatus(db, mr.ID, "", &gitlab.Pipeline{ID: 1, Status: "running", SHA: "abc"})
+	if countActions(models.ActionPipelineFailed)+countActions(models.ActionPipelineFixed) != 0 {
+		t.Fatal("expected no action for a running pipeline")
+	}
+
+	applyPipelineStatus(db, mr.ID, "", &gitlab.Pipeline{ID: 1, Status: "failed", SHA: "abc", WebURL: "https://gitlab.example.com/p/1"})
+	applyPipelineStatus(db, mr.ID, "", &gitlab.Pipeline{ID: 1, Status: "failed", SHA: "abc", WebURL: "https://gitlab.example.com/p/1"})
+	if got := countActions(models.ActionPipelineFailed); got != 1 {
+		t.Fatalf("expected one pipeline_failed action, got %d", got)
+	}
+
+	var reloaded models.MergeRequest
+	db.First(&reloaded, mr.ID)
+	if reloaded.PipelineStatus != "failed" || reloaded.PipelineSHA != "abc" || reloaded.PipelineWebURL != "https://gitlab.example.com/p/1" {
+		t.Errorf("expected pipeline fields stored, got %+v", reloaded)
+	}
+
+	applyPipelineStatus(db, mr.ID, "", &gitlab.Pipeline{ID: 2, Status: "success", SHA: "def"})
+	if got := countActions(models.ActionPipelineFixed); got != 1 {
+		t.Errorf("expected one pipeline_fixed action, got %d", got)
+	}
+
+	applyPipelineStatus(db, mr.ID, "ghi", nil)
+	db.First(&reloaded, mr.ID)
+	if reloaded.PipelineStatus != "" || reloaded.PipelineID != 0 || reloaded.PipelineSHA != "ghi" {
+		t.Errorf("expected pipeline cleared with the checked SHA kept, got status %q SHA %q", reloaded.PipelineStatus, reloaded.PipelineSHA)
+	}
+}
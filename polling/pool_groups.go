@@ -0,0 +1,123 @@
+package polling
+
+import (
+	"log"
+	"time"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/utils"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"gorm.io/gorm"
+)
+
+// poolGroupSyncInterval is how often group-bound reviewer pools are reconciled.
+const poolGroupSyncInterval = time.Hour
+
+// PollReviewerPoolGroups reconciles reviewer pools bound to GitLab groups with the groups' members.
+func PollReviewerPoolGroups(db *gorm.DB, client *gitlab.Client) {
+	var bindings []models.ReviewerPoolGroup
+	if err := db.Where("synced_at IS NULL OR synced_at < ?", time.Now().Add(-poolGroupSyncInterval)).
+		Find(&bindings).Error; err != nil {
+		log.Printf("failed to fetch reviewer pool groups: %v", err)
+		return
+	}
+
+	for _, binding := range bindings {
+		var members []*gitlab.GroupMember
+		opts := &gitlab.ListGroupMembersOptions{ListOptions: gitlab.ListOptions{PerPage: 100, Page: 1}}
+		failed := false
+		for {
+			page, resp, err := client.Groups.ListAllGroupMembers(binding.GroupPath, opts)
+			if err != nil {
+				log.Printf("failed to list members of group %s: %v", binding.GroupPath, err)
+				failed = true
+				break
+			}
+			members = append(members, page...)
+			if resp.NextPage == 0 {
+				break
+			}
+			opts.Page = resp.NextPage
+		}
+		if failed {
+			continue
+		}
+
+		if err := reconcileReviewerPoolGroup(db, binding, members); err != nil {
+			log.Printf("failed to reconcile reviewer pool for group %s: %v", binding.GroupPath, err)
+		}
+	}
+}
+
+// reconcileReviewerPoolGroup makes the rows synced from binding match the active group members
+// with at least the minimum access level, minus the repository's pool exclusions. Rows added
+// manually are left alone and users who already have one are not added twice.
+// An empty member list is ignored so an API hiccup cannot empty the pool.
+func reconcileReviewerPoolGroup(db *gorm.DB, binding models.ReviewerPoolGroup, members []*gitlab.GroupMember) error {
+	if len(members) == 0 {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		desired := make(map[uint]bool)
+		for _, m := range members {
+			if int(m.AccessLevel) < binding.MinAccessLevel || (m.State != "" && m.State != "active") {
+				continue
+			}
+			var u models.User
+			userData := models.User{
+				GitlabID:  m.ID,
+				Username:  m.Username,
+				Name:      m.Name,
+				State:     m.State,
+				AvatarURL: m.AvatarURL,
+				WebURL:    m.WebURL,
+			}
+			if err := tx.Where(models.User{GitlabID: m.ID}).Assign(userData).FirstOrCreate(&u).Error; err != nil {
+				return err
+			}
+			desired[u.ID] = true
+		}
+
+		var excludedIDs []uint
+		if err := tx.Model(&models.ReviewerPoolExclusion{}).Where("repository_id = ?", binding.RepositoryID).
+			Pluck("user_id", &excludedIDs).Error; err != nil {
+			return err
+		}
+		for _, id := range excludedIDs {
+			delete(desired, id)
+		}
+		userIDs := make([]uint, 0, len(desired))
+		for id := range desired {
+			userIDs = append(userIDs, id)
+		}
+
+		pool := utils.SyncedPool{
+			Model:        &models.PossibleReviewer{},
+			RepositoryID: binding.RepositoryID,
+			OwnerColumn:  "pool_group_id",
+			OwnerID:      binding.ID,
+			NewRow: func(userID uint) interface{} {
+				return &models.PossibleReviewer{RepositoryID: binding.RepositoryID, UserID: userID, PoolGroupID: &binding.ID}
+			},
+		}
+		if binding.LabelName != "" {
+			pool.Model = &models.LabelReviewer{}
+			pool.LabelName = binding.LabelName
+			pool.HasLabel = true
+			pool.NewRow = func(userID uint) interface{} {
+				return &models.LabelReviewer{RepositoryID: binding.RepositoryID, LabelName: binding.LabelName, UserID: userID, PoolGroupID: &binding.ID}
+			}
+		}
+		added, removed, err := utils.ReconcileSyncedPool(tx, pool, userIDs)
+		if err != nil {
+			return err
+		}
+
+		if added > 0 || removed > 0 {
+			log.Printf("Synced reviewer pool of repository %d label %q from group %s: +%d -%d", binding.RepositoryID, binding.LabelName, binding.GroupPath, added, removed)
+		}
+		return tx.Model(&binding).Update("synced_at", time.Now()).Error
+	})
+}
@@ -0,0 +1,97 @@
+package polling
+
+import (
+	"testing"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func poolUsernames(t *testing.T, rows []models.PossibleReviewer) map[string]bool {
+	t.Helper()
+	names := make(map[string]bool, len(rows))
+	for _, r := range rows {
+		names[r.User.Username] = true
+	}
+	return names
+}
+
+// TestReconcileReviewerPoolGroup tests that new members are added, leavers removed, and that
+// manual rows, exclusions and the minimum access level are honored.
+func TestReconcileReviewerPoolGroup(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+	repo := testutils.NewRepositoryFactory(db).Create()
+
+	binding := models.ReviewerPoolGroup{RepositoryID: repo.ID, GroupPath: "org/backend", MinAccessLevel: int(gitlab.DeveloperPermissions)}
+	db.Create(&binding)
+
+	leaver := userFactory.Create(testutils.WithUsername("leaver"), testutils.WithGitlabID(10))
+	db.Create(&models.PossibleReviewer{RepositoryID: repo.ID, UserID: leaver.ID, PoolGroupID: &binding.ID})
+	manual := userFactory.Create(testutils.WithUsername("manual"), testutils.WithGitlabID(11))
+	testutils.CreatePossibleReviewer(db, repo, manual)
+	excluded := userFactory.Create(testutils.WithUsername("excluded"), testutils.WithGitlabID(12))
+	db.Create(&models.ReviewerPoolExclusion{RepositoryID: repo.ID, UserID: excluded.ID})
+
+	members := []*gitlab.GroupMember{
+		{ID: 1, Username: "newhire", State: "active", AccessLevel: gitlab.DeveloperPermissions},
+		{ID: 2, Username: "guest", State: "active", AccessLevel: gitlab.ReporterPermissions},
+		{ID: 3, Username: "blocked", State: "blocked", AccessLevel: gitlab.MaintainerPermissions},
+		{ID: 11, Username: "manual", State: "active", AccessLevel: gitlab.DeveloperPermissions},
+		{ID: 12, Username: "excluded", State: "active", AccessLevel: gitlab.DeveloperPermissions},
+	}
+	if err := reconcileReviewerPoolGroup(db, binding, members); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var rows []models.PossibleReviewer
+	db.Preload("User").Where("repository_id = ?", repo.ID).Find(&rows)
+	names := poolUsernames(t, rows)
+	if len(rows) != 2 || !names["newhire"] || !names["manual"] {
+		t.Fatalf("expected newhire and manual in the pool, got %v", names)
+	}
+	for _, r := range rows {
+		if r.User.Username == "manual" && r.PoolGroupID != nil {
+			t.Error("expected the manual row to stay manual")
+		}
+	}
+	var reloaded models.ReviewerPoolGroup
+	db.First(&reloaded, binding.ID)
+	if reloaded.SyncedAt == nil {
+		t.Error("expected synced_at set")
+	}
+
+	if err := reconcileReviewerPoolGroup(db, binding, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var count int64
+	db.Model(&models.PossibleReviewer{}).Where("repository_id = ?", repo.ID).Count(&count)
+	if count != 2 {
+		t.Errorf("expected empty member list ignored, got %d rows", count)
+	}
+}
+
+// TestReconcileReviewerPoolGroup_Label tests syncing into a label pool, including re-adding a previously cleared member.
+func TestReconcileReviewerPoolGroup_Label(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	alice := testutils.NewUserFactory(db).Create(testutils.WithUsername("alice"), testutils.WithGitlabID(1))
+	testutils.CreateLabelReviewer(db, repo, "backend", alice)
+	db.Where("repository_id = ? AND label_name = ?", repo.ID, "backend").Delete(&models.LabelReviewer{})
+
+	binding := models.ReviewerPoolGroup{RepositoryID: repo.ID, LabelName: "backend", GroupPath: "org/backend", MinAccessLevel: int(gitlab.DeveloperPermissions)}
+	db.Create(&binding)
+
+	members := []*gitlab.GroupMember{{ID: 1, Username: "alice", State: "active", AccessLevel: gitlab.DeveloperPermissions}}
+	if err := reconcileReviewerPoolGroup(db, binding, members); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var rows []models.LabelReviewer
+	db.Where("repository_id = ? AND label_name = ?", repo.ID, "backend").Find(&rows)
+	if len(rows) != 1 || rows[0].UserID != alice.ID || rows[0].PoolGroupID == nil {
+		t.Fatalf("expected alice synced into the label pool, got %+v", rows)
+	}
+}
@@ -0,0 +1,115 @@
+package polling
+
+import (
+	"testing"
+	"time"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// TestDetectAndRecordStateChanges_CommitsPushed tests that a head SHA change is recorded as a push by the author.
+func TestDetectAndRecordStateChanges_CommitsPushed(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := testutils.NewUserFactory(db).Create()
+	existingMR := testutils.NewMergeRequestFactory(db).Create(repo, author)
+	existingMR.SHA = "aaa111"
+
+	detectAndRecordStateChanges(db, &existingMR, &gitlab.BasicMergeRequest{State: "opened", SHA: "aaa111"}, existingMR.ID)
+	detectAndRecordStateChanges(db, &existingMR, &gitlab.BasicMergeRequest{State: "opened", SHA: "bbb222"}, existingMR.ID)
+
+	var actions []models.MRAction
+	db.Where("merge_request_id = ? AND action_type = ?", existingMR.ID, models.ActionCommitsPushed).Find(&actions)
+	if len(actions) != 1 {
+		t.Fatalf("Expected 1 commits_pushed action, got %d", len(actions))
+	}
+	if actions[0].ActorID == nil || *actions[0].ActorID != author.ID {
+		t.Errorf("Expected the author as actor, got %v", actions[0].ActorID)
+	}
+	if actions[0].Metadata != `{"from":"aaa111","to":"bbb222"}` {
+		t.Errorf("Unexpected metadata %s", actions[0].Metadata)
+	}
+}
+
+// TestDetectAndRecordStateChanges_BotRebase tests that a head SHA change after a rebase started by
+// the bot is recorded as a rebase without an actor, unless that rebase failed.
+func TestDetectAndRecordStateChanges_BotRebase(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := testutils.NewUserFactory(db).Create()
+	existingMR := testutils.NewMergeRequestFactory(db).Create(repo, author)
+	existingMR.SHA = "aaa111"
+
+	testutils.CreateMRAction(db, existingMR, models.ActionRebaseRequested, testutils.WithMetadata(`{"sha":"aaa111"}`))
+	detectAndRecordStateChanges(db, &existingMR, &gitlab.BasicMergeRequest{State: "opened", SHA: "bbb222"}, existingMR.ID)
+
+	var rebased []models.MRAction
+	db.Where("merge_request_id = ? AND action_type = ?", existingMR.ID, models.ActionRebased).Find(&rebased)
+	if len(rebased) != 1 || rebased[0].ActorID != nil {
+		t.Fatalf("Expected 1 rebased action without actor, got %+v", rebased)
+	}
+	var pushes int64
+	db.Model(&models.MRAction{}).Where("action_type = ?", models.ActionCommitsPushed).Count(&pushes)
+	if pushes != 0 {
+		t.Errorf("Expected no commits_pushed action for a bot rebase, got %d", pushes)
+	}
+
+	existingMR.SHA = "ccc333"
+	testutils.CreateMRAction(db, existingMR, models.ActionRebaseRequested, testutils.WithMetadata(`{"sha":"ccc333"}`))
+	testutils.CreateMRAction(db, existingMR, models.ActionRebaseFailed, testutils.WithMetadata(`{"sha":"ccc333","reason":"conflict"}`))
+	detectAndRecordStateChanges(db, &existingMR, &gitlab.BasicMergeRequest{State: "opened", SHA: "ddd444"}, existingMR.ID)
+
+	db.Model(&models.MRAction{}).Where("action_type = ?", models.ActionCommitsPushed).Count(&pushes)
+	if pushes != 1 {
+		t.Errorf("Expected a push by the author after a failed rebase, got %d", pushes)
+	}
+}
+
+// TestApprovalResetByRebase tests that approvals followed only by a bot rebase are not reset by a push.
+func TestApprovalResetByRebase(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	reviewer := userFactory.Create()
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author)
+
+	now := time.Now()
+	testutils.CreateMRAction(db, mr, models.ActionApproved, testutils.WithActor(reviewer), testutils.WithTimestamp(now.Add(-2*time.Hour)))
+	testutils.CreateMRAction(db, mr, models.ActionRebased, testutils.WithTimestamp(now.Add(-time.Hour)))
+	if approvalResetByPush(db, mr.ID, reviewer.ID) {
+		t.Error("expected a bot rebase not to count as a push")
+	}
+	if !approvalResetByRebase(db, mr.ID, reviewer.ID) {
+		t.Error("expected reset by the rebase after the approval")
+	}
+}
+
+// TestApprovalResetByPush tests that only approvals followed by a push count as reset by commits.
+func TestApprovalResetByPush(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	reviewer := userFactory.Create()
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author)
+
+	now := time.Now()
+	testutils.CreateMRAction(db, mr, models.ActionApproved, testutils.WithActor(reviewer), testutils.WithTimestamp(now.Add(-2*time.Hour)))
+	if approvalResetByPush(db, mr.ID, reviewer.ID) {
+		t.Fatal("expected no reset without a push")
+	}
+
+	testutils.CreateMRAction(db, mr, models.ActionCommitsPushed, testutils.WithActor(author), testutils.WithTimestamp(now.Add(-time.Hour)))
+	if !approvalResetByPush(db, mr.ID, reviewer.ID) {
+		t.Error("expected reset by the push after the approval")
+	}
+
+	testutils.CreateMRAction(db, mr, models.ActionApproved, testutils.WithActor(reviewer), testutils.WithTimestamp(now))
+	if approvalResetByPush(db, mr.ID, reviewer.ID) {
+		t.Error("expected no reset once the reviewer approved again")
+	}
+}
@@ -48,6 +48,22 @@ func SetupTestDB(t *testing.T) *gorm.DB {
 		&models.FeatureReleaseBranch{},
 		&models.DeployTrackingRule{},
 		&models.TrackedDeployJob{},
+		&models.ReviewerSelectionTrace{},
+		&models.CodeOwnersConfig{},
+		&models.PathReviewer{},
+		&models.MergeRequestFile{},
+		&models.SizeAssignRule{},
+		&models.SizeLabelConfig{},
+		&models.RepositoryMember{},
+		&models.ReviewerPoolGroup{},
+		&models.ReviewerPoolExclusion{},
+		&models.Team{},
+		&models.TeamBinding{},
+		&models.NotificationPreference{},
+		&models.QueuedNotification{},
+		&models.StaleConfig{},
+		&models.AutoMergeConfig{},
+		&models.AutoRebaseConfig{},
 	)
 	if err != nil {
 		t.Fatalf("failed to migrate test database: %v", err)
@@ -269,6 +285,16 @@ func CreateLabelReviewer(db *gorm.DB, repo models.Repository, labelName string,
 	return lr
 }
 
+func CreatePathReviewer(db *gorm.DB, repo models.Repository, pattern string, user models.User) models.PathReviewer {
+	pr := models.PathReviewer{
+		RepositoryID: repo.ID,
+		Pattern:      pattern,
+		UserID:       user.ID,
+	}
+	db.Create(&pr)
+	return pr
+}
+
 func CreatePossibleReviewer(db *gorm.DB, repo models.Repository, user models.User) models.PossibleReviewer {
 	pr := models.PossibleReviewer{
 		RepositoryID: repo.ID,
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"fmt"
+
+	"devstreamlinebot/models"
+)
+
+// MissingApprovals describes the approvals GitLab still requires before the MR can be merged,
+// or returns "" when its approval rules are satisfied.
+func MissingApprovals(mr *models.MergeRequest) string {
+	if mr.ApprovalRulesSatisfied {
+		return ""
+	}
+	text := "approvals required"
+	switch {
+	case mr.ApprovalsLeft == 1:
+		text = "1 more approval required"
+	case mr.ApprovalsLeft > 1:
+		text = fmt.Sprintf("%d more approvals required", mr.ApprovalsLeft)
+	}
+	if mr.MissingApprovalRules != "" {
+		text += " (" + mr.MissingApprovalRules + ")"
+	}
+	return text
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+// TestApprovalRules_FullyApproved tests that MRs approved by every reviewer stay on review while
+// GitLab's approval rules are pending, for IsMRFullyApproved and both state derivations.
+func TestApprovalRules_FullyApproved(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	reviewer := userFactory.Create()
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author)
+	testutils.AssignReviewers(db, &mr, reviewer)
+	testutils.AssignApprovers(db, &mr, reviewer)
+
+	assertApproved := func(wantApproved bool, wantState MRState) {
+		t.Helper()
+		var reloaded models.MergeRequest
+		db.Preload("Reviewers").Preload("Approvers").First(&reloaded, mr.ID)
+		if got := IsMRFullyApproved(&reloaded); got != wantApproved {
+			t.Errorf("IsMRFullyApproved() = %v, want %v", got, wantApproved)
+		}
+		if got := DeriveState(db, &reloaded); got != wantState {
+			t.Errorf("DeriveState() = %v, want %v", got, wantState)
+		}
+		cache, err := LoadMRDataCache(db, []uint{mr.ID}, []uint{repo.ID})
+		if err != nil {
+			t.Fatalf("LoadMRDataCache() error: %v", err)
+		}
+		if got := DeriveStateFromCache(&reloaded, cache); got != wantState {
+			t.Errorf("DeriveStateFromCache() = %v, want %v", got, wantState)
+		}
+	}
+
+	assertApproved(true, StateReadyToMerge)
+
+	db.Model(&mr).UpdateColumns(map[string]interface{}{
+		"approval_rules_satisfied": false,
+		"approvals_left":           1,
+		"missing_approval_rules":   "Security",
+	})
+	assertApproved(false, StateOnReview)
+}
+
+// TestMissingApprovals tests the description of approvals GitLab still requires.
+func TestMissingApprovals(t *testing.T) {
+	tests := []struct {
+		name string
+		mr   models.MergeRequest
+		want string
+	}{
+		{"satisfied", models.MergeRequest{ApprovalRulesSatisfied: true}, ""},
+		{"one left", models.MergeRequest{ApprovalsLeft: 1}, "1 more approval required"},
+		{"rules left", models.MergeRequest{ApprovalsLeft: 2, MissingApprovalRules: "Backend, *.go"}, "2 more approvals required (Backend, *.go)"},
+		{"unknown count", models.MergeRequest{MissingApprovalRules: "Security"}, "approvals required (Security)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MissingApprovals(&tt.mr); got != tt.want {
+				t.Errorf("MissingApprovals() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestBuildEnhancedReviewDigest_MissingApprovals tests that digests name the approval rules an MR still needs.
+func TestBuildEnhancedReviewDigest_MissingApprovals(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := testutils.NewUserFactory(db).Create()
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author)
+	mr.Repository = repo
+	mr.ApprovalRulesSatisfied = false
+	mr.ApprovalsLeft = 1
+	mr.MissingApprovalRules = "Security"
+
+	digest := BuildEnhancedReviewDigest(db, []DigestMR{{MR: mr, State: StateOnReview}})
+	if !strings.Contains(digest, "GitLab: 1 more approval required (Security)") {
+		t.Errorf("expected the missing approval rule in the digest, got:\n%s", digest)
+	}
+}
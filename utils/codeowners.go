@@ -0,0 +1,200 @@
+package utils
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// CodeOwnersPaths lists the locations GitLab checks for a CODEOWNERS file, in lookup order.
+var CodeOwnersPaths = []string{"CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS"}
+
+// DefaultCodeOwnersSection names rules declared before any section header.
+const DefaultCodeOwnersSection = "codeowners"
+
+// CodeOwnersSection is one [Section] of a CODEOWNERS file.
+// Within a section the last matching rule wins; sections are evaluated independently.
+type CodeOwnersSection struct {
+	Name          string
+	Optional      bool
+	DefaultOwners []string
+	Rules         []CodeOwnersRule
+}
+
+// CodeOwnersRule maps a path pattern to owner references (@user, @group/subgroup or email).
+type CodeOwnersRule struct {
+	Pattern string
+	Owners  []string
+}
+
+var codeOwnersSectionRe = regexp.MustCompile(`^(\^)?\[([^\]]+)\](?:\[\d+\])?\s*(.*)$`)
+
+// ParseCodeOwners parses GitLab CODEOWNERS content into sections.
+// Sections with the same name (case-insensitive) are merged.
+func ParseCodeOwners(content string) []CodeOwnersSection {
+	sections := []CodeOwnersSection{{Name: DefaultCodeOwnersSection}}
+	index := map[string]int{DefaultCodeOwnersSection: 0}
+	current := 0
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if m := codeOwnersSectionRe.FindStringSubmatch(line); m != nil {
+			name := strings.TrimSpace(m[2])
+			key := strings.ToLower(name)
+			idx, ok := index[key]
+			if !ok {
+				sections = append(sections, CodeOwnersSection{Name: name})
+				idx = len(sections) - 1
+				index[key] = idx
+			}
+			sections[idx].Optional = m[1] == "^"
+			if owners := strings.Fields(m[3]); len(owners) > 0 {
+				sections[idx].DefaultOwners = owners
+			}
+			current = idx
+			continue
+		}
+
+		pattern, rest := splitCodeOwnersLine(line)
+		if pattern == "" {
+			continue
+		}
+		sections[current].Rules = append(sections[current].Rules, CodeOwnersRule{
+			Pattern: pattern,
+			Owners:  strings.Fields(rest),
+		})
+	}
+
+	var result []CodeOwnersSection
+	for _, s := range sections {
+		if len(s.Rules) > 0 {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// splitCodeOwnersLine separates the pattern from the owners, honoring escaped spaces and trailing comments.
+func splitCodeOwnersLine(line string) (string, string) {
+	var sb strings.Builder
+	i := 0
+	for ; i < len(line); i++ {
+		ch := line[i]
+		if ch == '\\' && i+1 < len(line) {
+			sb.WriteByte(line[i+1])
+			i++
+			continue
+		}
+		if ch == ' ' || ch == '\t' {
+			break
+		}
+		sb.WriteByte(ch)
+	}
+	rest := line[i:]
+	if idx := strings.Index(rest, " #"); idx >= 0 {
+		rest = rest[:idx]
+	}
+	return sb.String(), rest
+}
+
+// MatchCodeOwners returns owner references per required (non-optional) section for the changed paths.
+// For each path the last matching rule of a section applies; rules without owners use the section defaults.
+func MatchCodeOwners(sections []CodeOwnersSection, paths []string) map[string][]string {
+	result := make(map[string][]string)
+	for _, section := range sections {
+		if section.Optional {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, p := range paths {
+			var matched *CodeOwnersRule
+			for i := range section.Rules {
+				if matchCodeOwnersPattern(section.Rules[i].Pattern, p) {
+					matched = &section.Rules[i]
+				}
+			}
+			if matched == nil {
+				continue
+			}
+			owners := matched.Owners
+			if len(owners) == 0 {
+				owners = section.DefaultOwners
+			}
+			for _, o := range owners {
+				if !seen[o] {
+					seen[o] = true
+					result[section.Name] = append(result[section.Name], o)
+				}
+			}
+		}
+		sort.Strings(result[section.Name])
+	}
+	return result
+}
+
+// matchCodeOwnersPattern applies CODEOWNERS path semantics: patterns without a leading slash
+// match at any depth, a trailing slash matches everything under a directory, and a plain
+// path also matches a directory of that name.
+func matchCodeOwnersPattern(pattern, filePath string) bool {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	anchored := strings.HasPrefix(pattern, "/")
+	pattern = strings.Trim(pattern, "/")
+	if pattern == "" {
+		return false
+	}
+	if !anchored {
+		pattern = "**/" + pattern
+	}
+	if dirOnly {
+		return MatchPathGlob(pattern+"/**", filePath)
+	}
+	return MatchPathGlob(pattern, filePath) || MatchPathGlob(pattern+"/**", filePath)
+}
+
+// MatchPathGlob reports whether filePath matches a slash-separated glob where "*" matches
+// within a segment and "**" matches any number of segments.
+func MatchPathGlob(pattern, filePath string) bool {
+	return matchGlobSegments(strings.Split(strings.Trim(pattern, "/"), "/"), strings.Split(strings.Trim(filePath, "/"), "/"))
+}
+
+func matchGlobSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchGlobSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], parts[0]); err != nil || !ok {
+		return false
+	}
+	return matchGlobSegments(pattern[1:], parts[1:])
+}
+
+// SplitCodeOwnerReferences separates owner references into usernames and emails.
+// Group references (@group/subgroup) are returned separately since they need API resolution.
+func SplitCodeOwnerReferences(owners []string) (usernames, emails, groups []string) {
+	for _, o := range owners {
+		switch {
+		case strings.HasPrefix(o, "@") && strings.Contains(o, "/"):
+			groups = append(groups, strings.TrimPrefix(o, "@"))
+		case strings.HasPrefix(o, "@"):
+			usernames = append(usernames, strings.TrimPrefix(o, "@"))
+		case strings.Contains(o, "@"):
+			emails = append(emails, o)
+		}
+	}
+	return usernames, emails, groups
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+const sampleCodeOwners = `# Global owners
+* @lead
+
+[Backend] @backend-default
+/services/api/ @alice
+/services/api/legacy/
+*.go @bob
+
+^[Docs]
+docs/ @writer
+
+[Frontend][2]
+/web/**/*.tsx @carol team@example.com @org/frontend
+`
+
+// TestParseCodeOwners_Sections tests section headers, default owners, optional flags and rules.
+func TestParseCodeOwners_Sections(t *testing.T) {
+	sections := ParseCodeOwners(sampleCodeOwners)
+
+	if len(sections) != 4 {
+		t.Fatalf("expected 4 sections, got %d: %+v", len(sections), sections)
+	}
+	if sections[0].Name != DefaultCodeOwnersSection || len(sections[0].Rules) != 1 {
+		t.Errorf("unexpected default section: %+v", sections[0])
+	}
+	backend := sections[1]
+	if backend.Name != "Backend" || !reflect.DeepEqual(backend.DefaultOwners, []string{"@backend-default"}) || len(backend.Rules) != 3 {
+		t.Errorf("unexpected backend section: %+v", backend)
+	}
+	if !sections[2].Optional {
+		t.Error("expected Docs section to be optional")
+	}
+	if sections[3].Name != "Frontend" || len(sections[3].Rules[0].Owners) != 3 {
+		t.Errorf("unexpected frontend section: %+v", sections[3])
+	}
+}
+
+// TestMatchCodeOwners_LastRuleWinsPerSection tests section independence, last-match precedence and default owners.
+func TestMatchCodeOwners_LastRuleWinsPerSection(t *testing.T) {
+	sections := ParseCodeOwners(sampleCodeOwners)
+
+	owners := MatchCodeOwners(sections, []string{"services/api/handler.go", "services/api/legacy/old.py", "docs/readme.md"})
+
+	if !reflect.DeepEqual(owners[DefaultCodeOwnersSection], []string{"@lead"}) {
+		t.Errorf("expected global owner, got %v", owners[DefaultCodeOwnersSection])
+	}
+	// handler.go matches both /services/api/ and *.go; the later *.go rule wins.
+	if !reflect.DeepEqual(owners["Backend"], []string{"@backend-default", "@bob"}) {
+		t.Errorf("unexpected backend owners: %v", owners["Backend"])
+	}
+	if _, ok := owners["Docs"]; ok {
+		t.Error("optional sections must not produce required owners")
+	}
+	if _, ok := owners["Frontend"]; ok {
+		t.Error("frontend section should not match")
+	}
+}
+
+// TestMatchCodeOwnersPattern tests anchored, unanchored, directory and globstar patterns.
+func TestMatchCodeOwnersPattern(t *testing.T) {
+	cases := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"README.md", "README.md", true},
+		{"README.md", "nested/README.md", true},
+		{"/README.md", "nested/README.md", false},
+		{"docs/", "docs/a/b.md", true},
+		{"docs/", "src/docs/a.md", true},
+		{"/docs/", "src/docs/a.md", false},
+		{"/lib", "lib/util.go", true},
+		{"*.go", "cmd/main.go", true},
+		{"/web/**/*.tsx", "web/a/b/c.tsx", true},
+		{"/web/**/*.tsx", "web/c.ts", false},
+		{"*", "anything/at/all", true},
+	}
+	for _, tc := range cases {
+		if got := matchCodeOwnersPattern(tc.pattern, tc.path); got != tc.want {
+			t.Errorf("matchCodeOwnersPattern(%q, %q) = %v, want %v", tc.pattern, tc.path, got, tc.want)
+		}
+	}
+}
+
+// TestSplitCodeOwnerReferences tests separation of users, emails and groups.
+func TestSplitCodeOwnerReferences(t *testing.T) {
+	usernames, emails, groups := SplitCodeOwnerReferences([]string{"@carol", "team@example.com", "@org/frontend"})
+
+	if !reflect.DeepEqual(usernames, []string{"carol"}) || !reflect.DeepEqual(emails, []string{"team@example.com"}) || !reflect.DeepEqual(groups, []string{"org/frontend"}) {
+		t.Errorf("unexpected split: %v %v %v", usernames, emails, groups)
+	}
+}
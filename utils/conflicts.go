@@ -0,0 +1,47 @@
+package utils
+
+import "devstreamlinebot/models"
+
+// Reasons GitLab cannot merge an MR's source branch as is.
+const (
+	MergeBlockerConflict   = "conflict"
+	MergeBlockerNeedRebase = "need_rebase"
+)
+
+// MergeBlocker returns MergeBlockerConflict or MergeBlockerNeedRebase when the branch has to be
+// fixed by its author before it can be merged, or "" otherwise.
+func MergeBlocker(hasConflicts bool, detailedMergeStatus string) string {
+	if hasConflicts || detailedMergeStatus == "conflict" {
+		return MergeBlockerConflict
+	}
+	if detailedMergeStatus == "need_rebase" {
+		return MergeBlockerNeedRebase
+	}
+	return ""
+}
+
+// IsMergeStatusChecking reports whether GitLab is still computing the merge status, in which
+// case DetailedMergeStatus says nothing about conflicts.
+func IsMergeStatusChecking(detailedMergeStatus string) bool {
+	switch detailedMergeStatus {
+	case "checking", "unchecked", "preparing", "approvals_syncing":
+		return true
+	}
+	return false
+}
+
+// IsMRConflicted reports whether the MR has conflicts or needs a rebase.
+func IsMRConflicted(mr *models.MergeRequest) bool {
+	return MergeBlocker(mr.HasConflicts, mr.DetailedMergeStatus) != ""
+}
+
+// ConflictMarker returns a short digest marker for a conflicted MR, or "" if it has none.
+func ConflictMarker(mr *models.MergeRequest) string {
+	switch MergeBlocker(mr.HasConflicts, mr.DetailedMergeStatus) {
+	case MergeBlockerConflict:
+		return "CONFLICT"
+	case MergeBlockerNeedRebase:
+		return "REBASE"
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"devstreamlinebot/models"
+)
+
+// TestMergeBlocker tests which GitLab merge statuses count as conflicts or a needed rebase.
+func TestMergeBlocker(t *testing.T) {
+	tests := []struct {
+		hasConflicts bool
+		status       string
+		want         string
+	}{
+		{false, "mergeable", ""},
+		{true, "checking", MergeBlockerConflict},
+		{false, "conflict", MergeBlockerConflict},
+		{false, "need_rebase", MergeBlockerNeedRebase},
+		{false, "not_approved", ""},
+	}
+	for _, tt := range tests {
+		if got := MergeBlocker(tt.hasConflicts, tt.status); got != tt.want {
+			t.Errorf("MergeBlocker(%v, %q) = %q, want %q", tt.hasConflicts, tt.status, got, tt.want)
+		}
+	}
+}
+
+// TestDeriveState_Conflicted tests that a conflicted MR is conflicted since the conflict was detected
+// and returns to on_review from the time it was resolved.
+func TestDeriveState_Conflicted(t *testing.T) {
+	db := setupTestDB(t)
+
+	mr := &models.MergeRequest{State: "opened", HasConflicts: true}
+	db.Create(mr)
+	detectedAt := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	db.Create(&models.MRAction{MergeRequestID: mr.ID, ActionType: models.ActionConflictDetected, Timestamp: detectedAt, Metadata: `{"reason":"conflict"}`})
+
+	if state := DeriveState(db, mr); state != StateConflicted {
+		t.Fatalf("DeriveState() = %v, want %v", state, StateConflicted)
+	}
+	since := GetStateTransitionTime(db, mr, StateConflicted)
+	if since == nil || !since.Equal(detectedAt) {
+		t.Errorf("GetStateTransitionTime() = %v, want %v", since, detectedAt)
+	}
+
+	cache, err := LoadMRDataCache(db, []uint{mr.ID}, nil)
+	if err != nil {
+		t.Fatalf("LoadMRDataCache() error: %v", err)
+	}
+	if state := DeriveStateFromCache(mr, cache); state != StateConflicted {
+		t.Errorf("DeriveStateFromCache() = %v, want %v", state, StateConflicted)
+	}
+	if since := GetStateTransitionTimeFromCache(mr, StateConflicted, cache); since == nil || !since.Equal(detectedAt) {
+		t.Errorf("GetStateTransitionTimeFromCache() = %v, want %v", since, detectedAt)
+	}
+
+	resolvedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	db.Create(&models.MRAction{MergeRequestID: mr.ID, ActionType: models.ActionConflictResolved, Timestamp: resolvedAt, Metadata: `{"reason":"conflict"}`})
+	mr.HasConflicts = false
+	db.Save(mr)
+
+	if state := DeriveState(db, mr); state != StateOnReview {
+		t.Fatalf("DeriveState() = %v, want %v", state, StateOnReview)
+	}
+	if since := GetStateTransitionTime(db, mr, StateOnReview); since == nil || !since.Equal(resolvedAt) {
+		t.Errorf("GetStateTransitionTime() = %v, want %v", since, resolvedAt)
+	}
+}
@@ -73,8 +73,10 @@ type DigestMR struct {
 	Blocked       bool // Whether MR currently has a block label
 }
 
+// IsMRFullyApproved reports whether every assigned reviewer approved the MR and GitLab's
+// approval rules are satisfied.
 func IsMRFullyApproved(mr *models.MergeRequest) bool {
-	if len(mr.Reviewers) == 0 {
+	if len(mr.Reviewers) == 0 || !mr.ApprovalRulesSatisfied {
 		return false
 	}
 	approverIDs := make(map[uint]bool)
@@ -171,12 +173,7 @@ func FindDigestMergeRequestsWithState(db *gorm.DB, repoIDs []uint) ([]DigestMR,
 
 		sla := cache.GetSLAFromCache(mr.RepositoryID)
 
-		var threshold time.Duration
-		if stateInfo.State == StateOnReview {
-			threshold = sla.ReviewDuration.ToDuration()
-		} else if stateInfo.State == StateOnFixes || stateInfo.State == StateDraft {
-			threshold = sla.FixesDuration.ToDuration()
-		}
+		threshold := SLAThresholdForState(sla, stateInfo.State)
 
 		exceeded, percentage := CheckSLAStatus(stateInfo.WorkingTime, threshold)
 
@@ -198,8 +195,8 @@ func FindDigestMergeRequestsWithState(db *gorm.DB, repoIDs []uint) ([]DigestMR,
 // FindUserActionMRs returns MRs requiring action from a specific user.
 // Returns three slices:
 // - reviewMRs: MRs where user is reviewer and needs to take action (no pending threads awaiting author)
-// - fixesMRs: MRs where user is author and state is on_fixes or draft
-// - authorOnReviewMRs: MRs where user is author and state is on_review (waiting for reviewers)
+// - fixesMRs: MRs where user is author and the MR waits for them (on_fixes, draft, conflicted, pipeline_failed)
+// - authorOnReviewMRs: MRs where user is author and the MR waits on others (on_review, ready_to_merge, blocked)
 //
 // Reviewer needs action when ALL their threads are "handled" - meaning they have NO
 // unresolved threads where they commented last (waiting for author to respond).
@@ -230,8 +227,13 @@ func FindUserActionMRs(db *gorm.DB, userID uint) (reviewMRs []DigestMR, fixesMRs
 					 WHERE ac.gitlab_discussion_id = mc.gitlab_discussion_id
 					   AND ac.author_id = merge_requests.author_id),
 					'0001-01-01')
+				  AND rc.gitlab_created_at > COALESCE(
+					(SELECT MAX(pa.timestamp) FROM mr_actions pa
+					 WHERE pa.merge_request_id = merge_requests.id
+					   AND pa.action_type = ?),
+					'0001-01-01')
 			  )
-		)`, true, true, false, userID).
+		)`, true, true, false, userID, models.ActionCommitsPushed).
 		Find(&reviewerMRs).Error
 	if err != nil {
 		return nil, nil, nil, err
@@ -271,6 +273,10 @@ func FindUserActionMRs(db *gorm.DB, userID uint) (reviewMRs []DigestMR, fixesMRs
 		}
 
 		stateInfo := GetStateInfoFromCache(&mr, cache)
+		// Conflicts and failed pipelines are the author's to fix before review continues
+		if stateInfo.State == StateConflicted || stateInfo.State == StatePipelineFailed {
+			continue
+		}
 		userStateSince := GetUserStateTransitionTimeFromCache(&mr, userID, cache)
 		workingTime := calculateUserWorkingTimeFromCache(&mr, userStateSince, cache)
 
@@ -302,8 +308,8 @@ func FindUserActionMRs(db *gorm.DB, userID uint) (reviewMRs []DigestMR, fixesMRs
 		blocked := cache.IsMRBlockedFromCache(mr.Labels, mr.RepositoryID)
 		sla := cache.GetSLAFromCache(mr.RepositoryID)
 
-		if stateInfo.State == StateOnFixes || stateInfo.State == StateDraft {
-			threshold := sla.FixesDuration.ToDuration()
+		if IsAuthorActionState(stateInfo.State) {
+			threshold := SLAThresholdForState(sla, stateInfo.State)
 			exceeded, percentage := CheckSLAStatus(workingTime, threshold)
 
 			fixesMRs = append(fixesMRs, DigestMR{
@@ -315,8 +321,8 @@ func FindUserActionMRs(db *gorm.DB, userID uint) (reviewMRs []DigestMR, fixesMRs
 				SLAPercentage: percentage,
 				Blocked:       blocked,
 			})
-		} else if stateInfo.State == StateOnReview {
-			threshold := sla.ReviewDuration.ToDuration()
+		} else {
+			threshold := SLAThresholdForState(sla, stateInfo.State)
 			exceeded, percentage := CheckSLAStatus(workingTime, threshold)
 
 			authorOnReviewMRs = append(authorOnReviewMRs, DigestMR{
@@ -373,7 +379,7 @@ func calculateUserWorkingTimeFromCache(mr *models.MergeRequest, stateSince *time
 
 // GetActiveReviewers returns active reviewers for each MR.
 // A reviewer is active if they: are assigned, haven't approved, and have no unresolved threads
-// where they participated after the MR author's last reply (waiting for author to respond).
+// where they participated after the MR author's last reply or push (waiting for author to respond).
 func GetActiveReviewers(db *gorm.DB, mrIDs []uint) (map[uint][]models.User, error) {
 	result := make(map[uint][]models.User)
 	if len(mrIDs) == 0 {
@@ -435,7 +441,13 @@ func GetActiveReviewers(db *gorm.DB, mrIDs []uint) (map[uint][]models.User, erro
 			   AND ac.author_id = mr.author_id),
 			'0001-01-01'
 		  )
-	`, mrIDs, true, false, true).Scan(&waitingRows).Error; err != nil {
+		  AND rc.gitlab_created_at > COALESCE(
+			(SELECT MAX(pa.timestamp) FROM mr_actions pa
+			 WHERE pa.merge_request_id = rc.merge_request_id
+			   AND pa.action_type = ?),
+			'0001-01-01'
+		  )
+	`, mrIDs, true, false, true, models.ActionCommitsPushed).Scan(&waitingRows).Error; err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+// TestGiniCoefficient tests even, fully concentrated and empty distributions.
+func TestGiniCoefficient(t *testing.T) {
+	if g := GiniCoefficient([]float64{3, 3, 3}); math.Abs(g) > 1e-9 {
+		t.Errorf("expected 0 for even load, got %v", g)
+	}
+	if g := GiniCoefficient([]float64{0, 0, 0, 8}); math.Abs(g-0.75) > 1e-9 {
+		t.Errorf("expected 0.75 for one member carrying all load, got %v", g)
+	}
+	if g := GiniCoefficient(nil); g != 0 {
+		t.Errorf("expected 0 for no values, got %v", g)
+	}
+	if g := GiniCoefficient([]float64{0, 0}); g != 0 {
+		t.Errorf("expected 0 for zero load, got %v", g)
+	}
+}
+
+// TestStdDev tests the population standard deviation.
+func TestStdDev(t *testing.T) {
+	if s := StdDev([]float64{2, 4, 4, 4, 5, 5, 7, 9}); math.Abs(s-2) > 1e-9 {
+		t.Errorf("expected 2, got %v", s)
+	}
+	if s := StdDev(nil); s != 0 {
+		t.Errorf("expected 0 for no values, got %v", s)
+	}
+}
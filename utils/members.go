@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"gorm.io/gorm"
+
+	"devstreamlinebot/models"
+)
+
+// Reasons a pool member or reviewer is not eligible to review.
+const (
+	InactiveLocked    = "locked"
+	InactiveNotMember = "not a project member"
+)
+
+// UserInactiveState returns why a GitLab account cannot review (its state such as blocked or
+// deactivated, or locked), or "" for active users. Users with an unknown state count as active.
+func UserInactiveState(u *models.User) string {
+	if u.State != "" && u.State != "active" {
+		return u.State
+	}
+	if u.Locked {
+		return InactiveLocked
+	}
+	return ""
+}
+
+// ActiveRepositoryUsers restricts a users query to active, unlocked accounts that are members of
+// the repository. Membership is only enforced once the repository's members have been synced.
+func ActiveRepositoryUsers(repoID uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Where("(users.state = '' OR users.state IS NULL OR users.state = ?) AND (users.locked = ? OR users.locked IS NULL)", "active", false).
+			Where("(NOT EXISTS (SELECT 1 FROM repositories WHERE repositories.id = ? AND repositories.members_synced_at IS NOT NULL) "+
+				"OR EXISTS (SELECT 1 FROM repository_members WHERE repository_members.repository_id = ? AND repository_members.user_id = users.id AND repository_members.deleted_at IS NULL))",
+				repoID, repoID)
+	}
+}
+
+// UserIneligibleReason returns why a user may not review in a repository: an inactive
+// account state or missing project membership. Returns "" for eligible users.
+func UserIneligibleReason(db *gorm.DB, repoID uint, u *models.User) string {
+	if reason := UserInactiveState(u); reason != "" {
+		return reason
+	}
+	var count int64
+	db.Model(&models.User{}).Scopes(ActiveRepositoryUsers(repoID)).Where("users.id = ?", u.ID).Count(&count)
+	if count == 0 {
+		return InactiveNotMember
+	}
+	return ""
+}
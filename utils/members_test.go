@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+// TestUserIneligibleReason tests account state checks and membership enforcement after a member sync.
+func TestUserIneligibleReason(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+	repo := testutils.NewRepositoryFactory(db).Create()
+
+	member := userFactory.Create()
+	outsider := userFactory.Create()
+	blocked := userFactory.Create()
+	db.Model(&blocked).Update("state", "blocked")
+	db.Create(&models.RepositoryMember{RepositoryID: repo.ID, UserID: member.ID})
+
+	if reason := UserIneligibleReason(db, repo.ID, &outsider); reason != "" {
+		t.Errorf("expected membership ignored before sync, got %q", reason)
+	}
+	if reason := UserIneligibleReason(db, repo.ID, &blocked); reason != "blocked" {
+		t.Errorf("expected blocked, got %q", reason)
+	}
+
+	db.Model(&repo).Update("members_synced_at", time.Now())
+	if reason := UserIneligibleReason(db, repo.ID, &outsider); reason != InactiveNotMember {
+		t.Errorf("expected non-member after sync, got %q", reason)
+	}
+	if reason := UserIneligibleReason(db, repo.ID, &member); reason != "" {
+		t.Errorf("expected member eligible, got %q", reason)
+	}
+
+	unknown := models.User{Locked: true}
+	if reason := UserInactiveState(&unknown); reason != InactiveLocked {
+		t.Errorf("expected locked, got %q", reason)
+	}
+}
@@ -162,17 +162,20 @@ func BuildEnhancedReviewDigest(db *gorm.DB, digestMRs []DigestMR) string {
 
 	var pendingReview []DigestMR
 	var pendingFixes []DigestMR
+	var readyToMerge []DigestMR
 	var blocked []DigestMR
 
 	for _, dmr := range digestMRs {
-		if dmr.Blocked {
+		if dmr.Blocked || dmr.State == StateBlocked {
 			blocked = append(blocked, dmr)
 			continue
 		}
-		switch dmr.State {
-		case StateOnReview:
+		switch {
+		case dmr.State == StateOnReview:
 			pendingReview = append(pendingReview, dmr)
-		case StateOnFixes, StateDraft:
+		case dmr.State == StateReadyToMerge:
+			readyToMerge = append(readyToMerge, dmr)
+		case IsAuthorActionState(dmr.State):
 			pendingFixes = append(pendingFixes, dmr)
 		}
 	}
@@ -206,6 +209,16 @@ func BuildEnhancedReviewDigest(db *gorm.DB, digestMRs []DigestMR) string {
 		}
 	}
 
+	if len(readyToMerge) > 0 {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("READY TO MERGE:\n")
+		for _, dmr := range readyToMerge {
+			writeDigestEntry(&sb, &dmr, mentionMap, activeReviewersMap[dmr.MR.ID])
+		}
+	}
+
 	if len(blocked) > 0 {
 		if sb.Len() > 0 {
 			sb.WriteString("\n")
@@ -246,11 +259,23 @@ func writeDigestEntry(sb *strings.Builder, dmr *DigestMR, mentionMap map[uint]st
 	if dmr.State == StateDraft {
 		stateIndicator = " [DRAFT]"
 	}
+	if size := FormatMRSize(mr); size != "" {
+		stateIndicator += " [" + size + "]"
+	}
+	if marker := ConflictMarker(mr); marker != "" {
+		stateIndicator += " [" + marker + "]"
+	}
+	if badge := PipelineBadge(mr); badge != "" {
+		stateIndicator += " [" + badge + "]"
+	}
 
 	repoName := mr.Repository.Name
 	sb.WriteString(fmt.Sprintf("- [%s] %s%s\n", repoName, sanitizedTitle, stateIndicator))
 	sb.WriteString(fmt.Sprintf("  %s\n", mr.WebURL))
 	sb.WriteString(fmt.Sprintf("  by @[%s] → %s\n", authorMention, reviewerStr))
+	if missing := MissingApprovals(mr); missing != "" {
+		sb.WriteString(fmt.Sprintf("  🔒 GitLab: %s\n", missing))
+	}
 	sb.WriteString(fmt.Sprintf("  ⏱ %s | SLA: %s\n\n", timeStr, slaStatus))
 }
 
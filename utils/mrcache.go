@@ -27,6 +27,11 @@ type MRDataCache struct {
 
 	// Comment cache by DiscussionID for thread timing
 	CommentsByDiscussion map[string][]models.MRComment
+
+	// Reviewer, approver and label caches - keyed by MergeRequestID
+	ReviewerIDs map[uint][]uint
+	ApproverIDs map[uint]map[uint]bool
+	LabelNames  map[uint][]string
 }
 
 // LoadMRDataCache batch loads all data needed for MR processing.
@@ -41,6 +46,9 @@ func LoadMRDataCache(db *gorm.DB, mrIDs []uint, repoIDs []uint) (*MRDataCache, e
 		Actions:              make(map[uint][]models.MRAction),
 		Comments:             make(map[uint][]models.MRComment),
 		CommentsByDiscussion: make(map[string][]models.MRComment),
+		ReviewerIDs:          make(map[uint][]uint),
+		ApproverIDs:          make(map[uint]map[uint]bool),
+		LabelNames:           make(map[uint][]string),
 	}
 
 	if len(repoIDs) == 0 && len(mrIDs) == 0 {
@@ -129,6 +137,51 @@ func LoadMRDataCache(db *gorm.DB, mrIDs []uint, repoIDs []uint) (*MRDataCache, e
 			cache.Comments[c.MergeRequestID] = append(cache.Comments[c.MergeRequestID], c)
 			cache.CommentsByDiscussion[c.GitlabDiscussionID] = append(cache.CommentsByDiscussion[c.GitlabDiscussionID], c)
 		}
+
+		// Load reviewers, approvers and labels for all MRs
+		var reviewers []struct {
+			MergeRequestID uint
+			UserID         uint
+		}
+		if err := db.Table("merge_request_reviewers").
+			Where("merge_request_id IN ?", mrIDs).
+			Find(&reviewers).Error; err != nil {
+			return nil, err
+		}
+		for _, r := range reviewers {
+			cache.ReviewerIDs[r.MergeRequestID] = append(cache.ReviewerIDs[r.MergeRequestID], r.UserID)
+		}
+
+		var approvers []struct {
+			MergeRequestID uint
+			UserID         uint
+		}
+		if err := db.Table("merge_request_approvers").
+			Where("merge_request_id IN ?", mrIDs).
+			Find(&approvers).Error; err != nil {
+			return nil, err
+		}
+		for _, a := range approvers {
+			if cache.ApproverIDs[a.MergeRequestID] == nil {
+				cache.ApproverIDs[a.MergeRequestID] = make(map[uint]bool)
+			}
+			cache.ApproverIDs[a.MergeRequestID][a.UserID] = true
+		}
+
+		var labels []struct {
+			MergeRequestID uint
+			Name           string
+		}
+		if err := db.Table("merge_request_labels").
+			Select("merge_request_labels.merge_request_id, labels.name").
+			Joins("JOIN labels ON labels.id = merge_request_labels.label_id").
+			Where("merge_request_labels.merge_request_id IN ?", mrIDs).
+			Find(&labels).Error; err != nil {
+			return nil, err
+		}
+		for _, l := range labels {
+			cache.LabelNames[l.MergeRequestID] = append(cache.LabelNames[l.MergeRequestID], l.Name)
+		}
 	}
 
 	return cache, nil
@@ -182,6 +235,31 @@ func (c *MRDataCache) IsMRBlockedFromCache(labels []models.Label, repoID uint) b
 	return false
 }
 
+// hasBlockLabel checks if the MR currently carries a block label using cached data.
+func (c *MRDataCache) hasBlockLabel(mrID uint, repoID uint) bool {
+	blockLabels := c.BlockLabels[repoID]
+	for _, name := range c.LabelNames[mrID] {
+		if _, ok := blockLabels[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isFullyApproved checks if the MR has reviewers and all of them approved it using cached data.
+func (c *MRDataCache) isFullyApproved(mrID uint) bool {
+	reviewerIDs := c.ReviewerIDs[mrID]
+	if len(reviewerIDs) == 0 {
+		return false
+	}
+	for _, id := range reviewerIDs {
+		if !c.ApproverIDs[mrID][id] {
+			return false
+		}
+	}
+	return true
+}
+
 // collectUniqueIDs extracts unique MR IDs and repo IDs from a slice of MRs.
 func CollectUniqueIDs(mrs []models.MergeRequest) (mrIDs []uint, repoIDs []uint) {
 	mrIDSet := make(map[uint]struct{})
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"devstreamlinebot/models"
+)
+
+// SizeLabelPrefix prefixes automatically applied MR size labels.
+const SizeLabelPrefix = "size/"
+
+// mrSizeLimits maps upper bounds (exclusive) of changed lines to size names; larger MRs are XL.
+var mrSizeLimits = []struct {
+	maxLines int
+	size     string
+}{
+	{50, "S"},
+	{250, "M"},
+	{1000, "L"},
+}
+
+// MRSize returns S, M, L or XL for the number of changed lines (added + removed).
+func MRSize(lines int) string {
+	for _, l := range mrSizeLimits {
+		if lines < l.maxLines {
+			return l.size
+		}
+	}
+	return "XL"
+}
+
+// MRSizeLabel returns the size label (e.g. size/M) for the number of changed lines.
+func MRSizeLabel(lines int) string {
+	return SizeLabelPrefix + MRSize(lines)
+}
+
+// ChangedLines returns added plus removed lines of an MR.
+func ChangedLines(mr *models.MergeRequest) int {
+	return mr.LinesAdded + mr.LinesRemoved
+}
+
+// FormatMRSize renders the MR size like "L +320/-45", or "" when diff stats are not fetched yet.
+func FormatMRSize(mr *models.MergeRequest) string {
+	if mr.DiffStatsSHA == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s +%d/-%d", MRSize(ChangedLines(mr)), mr.LinesAdded, mr.LinesRemoved)
+}
+
+// CountDiffLines counts added and removed lines in a GitLab diff body.
+// GitLab diffs start at the first hunk, so there are no ---/+++ file headers to skip.
+func CountDiffLines(diff string) (added, removed int) {
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"testing"
+
+	"devstreamlinebot/models"
+)
+
+// TestMRSize tests the size boundaries.
+func TestMRSize(t *testing.T) {
+	cases := map[int]string{0: "S", 49: "S", 50: "M", 249: "M", 250: "L", 999: "L", 1000: "XL"}
+	for lines, want := range cases {
+		if got := MRSize(lines); got != want {
+			t.Errorf("MRSize(%d) = %s, want %s", lines, got, want)
+		}
+	}
+	if got := MRSizeLabel(300); got != "size/L" {
+		t.Errorf("expected size/L, got %s", got)
+	}
+}
+
+// TestCountDiffLines tests counting of added and removed lines, including removed lines starting with "--".
+func TestCountDiffLines(t *testing.T) {
+	diff := "@@ -1,3 +1,3 @@\n context\n-old\n--- removed sql comment\n+new\n+another\n"
+	added, removed := CountDiffLines(diff)
+	if added != 2 || removed != 2 {
+		t.Errorf("expected +2/-2, got +%d/-%d", added, removed)
+	}
+}
+
+// TestFormatMRSize tests size rendering and the empty result before stats are synced.
+func TestFormatMRSize(t *testing.T) {
+	mr := &models.MergeRequest{LinesAdded: 320, LinesRemoved: 45}
+	if got := FormatMRSize(mr); got != "" {
+		t.Errorf("expected empty size without stats, got %q", got)
+	}
+	mr.DiffStatsSHA = "abc"
+	if got := FormatMRSize(mr); got != "L +320/-45" {
+		t.Errorf("expected L +320/-45, got %q", got)
+	}
+}
@@ -12,15 +12,20 @@ import (
 type MRState string
 
 const (
-	StateOnReview MRState = "on_review" // Has reviewers, no unresolved resolvable comments, not draft
-	StateOnFixes  MRState = "on_fixes"  // Has unresolved resolvable comments (awaiting author fixes)
-	StateDraft    MRState = "draft"     // MR is marked as draft/WIP
-	StateMerged   MRState = "merged"    // MR has been merged
-	StateClosed   MRState = "closed"    // MR has been closed without merging
+	StateOnReview       MRState = "on_review"       // Has reviewers, no unresolved resolvable comments, not draft
+	StateOnFixes        MRState = "on_fixes"        // Has unresolved resolvable comments (awaiting author fixes)
+	StateDraft          MRState = "draft"           // MR is marked as draft/WIP
+	StateMerged         MRState = "merged"          // MR has been merged
+	StateClosed         MRState = "closed"          // MR has been closed without merging
+	StateBlocked        MRState = "blocked"         // MR carries a block label
+	StateConflicted     MRState = "conflicted"      // MR has merge conflicts or needs a rebase
+	StatePipelineFailed MRState = "pipeline_failed" // MR head pipeline failed
+	StateReadyToMerge   MRState = "ready_to_merge"  // All assigned reviewers approved
 )
 
 // DeriveState determines the current state of a merge request based on DB data.
-// Priority order: merged > closed > draft > on_fixes > on_review
+// Priority order: merged > closed > draft > blocked > conflicted > pipeline_failed >
+// on_fixes > ready_to_merge > on_review
 // Note: on_fixes only applies when there are unresolved threads where the last
 // comment is NOT from the MR author (i.e., author hasn't responded yet).
 func DeriveState(db *gorm.DB, mr *models.MergeRequest) MRState {
@@ -35,13 +40,62 @@ func DeriveState(db *gorm.DB, mr *models.MergeRequest) MRState {
 		return StateDraft
 	}
 
+	if hasBlockLabel(db, mr) {
+		return StateBlocked
+	}
+
+	if IsMRConflicted(mr) {
+		return StateConflicted
+	}
+
+	if mr.PipelineStatus == PipelineFailed {
+		return StatePipelineFailed
+	}
+
 	if HasThreadsAwaitingAuthor(db, mr.ID, mr.AuthorID) {
 		return StateOnFixes
 	}
 
+	if isFullyApprovedInDB(db, mr.ID) {
+		return StateReadyToMerge
+	}
+
 	return StateOnReview
 }
 
+// hasBlockLabel reports whether the MR currently carries one of its repository's block labels.
+func hasBlockLabel(db *gorm.DB, mr *models.MergeRequest) bool {
+	var count int64
+	db.Table("merge_request_labels").
+		Joins("JOIN labels ON labels.id = merge_request_labels.label_id").
+		Joins("JOIN block_labels ON block_labels.label_name = labels.name AND block_labels.repository_id = ? AND block_labels.deleted_at IS NULL", mr.RepositoryID).
+		Where("merge_request_labels.merge_request_id = ?", mr.ID).
+		Count(&count)
+	return count > 0
+}
+
+// isFullyApprovedInDB reports whether the MR has reviewers, every one of them approved it and
+// GitLab's approval rules are satisfied. It is the query counterpart of IsMRFullyApproved for
+// MRs loaded without associations.
+func isFullyApprovedInDB(db *gorm.DB, mrID uint) bool {
+	var rulesPending int64
+	db.Model(&models.MergeRequest{}).Where("id = ? AND approval_rules_satisfied = ?", mrID, false).Count(&rulesPending)
+	if rulesPending > 0 {
+		return false
+	}
+
+	var reviewers, pending int64
+	db.Table("merge_request_reviewers").Where("merge_request_id = ?", mrID).Count(&reviewers)
+	if reviewers == 0 {
+		return false
+	}
+	db.Table("merge_request_reviewers").
+		Where(`merge_request_id = ? AND NOT EXISTS (SELECT 1 FROM merge_request_approvers a
+			WHERE a.merge_request_id = merge_request_reviewers.merge_request_id AND a.user_id = merge_request_reviewers.user_id)`, mrID).
+		Count(&pending)
+	return pending == 0
+}
+
 // StateInfo contains derived state information for an MR.
 type StateInfo struct {
 	State           MRState
@@ -161,6 +215,18 @@ func GetStateTransitionTime(db *gorm.DB, mr *models.MergeRequest, state MRState)
 		}
 		return mr.GitlabCreatedAt
 
+	case StateBlocked:
+		return sinceOrCreated(mr, blockedSince(mrActionsOfType(db, mr.ID, models.ActionBlockLabelAdded, models.ActionBlockLabelRemoved)))
+
+	case StateConflicted:
+		return sinceOrCreated(mr, conflictSince(mrActionsOfType(db, mr.ID, models.ActionConflictDetected, models.ActionConflictResolved)))
+
+	case StatePipelineFailed:
+		return sinceOrCreated(mr, lastActionTime(mrActionsOfType(db, mr.ID, models.ActionPipelineFailed), models.ActionPipelineFailed))
+
+	case StateReadyToMerge:
+		return sinceOrCreated(mr, lastActionTime(mrActionsOfType(db, mr.ID, models.ActionApproved), models.ActionApproved))
+
 	case StateOnFixes:
 		var threads []struct {
 			GitlabCreatedAt time.Time
@@ -215,8 +281,28 @@ func GetStateTransitionTime(db *gorm.DB, mr *models.MergeRequest, state MRState)
 
 		var candidates []time.Time
 
+		var lastUnblocking models.MRAction
+		err := db.Where("merge_request_id = ? AND action_type IN ?", mr.ID, reviewResumingActions).
+			Order("timestamp DESC").
+			First(&lastUnblocking).Error
+		if err == nil {
+			candidates = append(candidates, lastUnblocking.Timestamp)
+		}
+
+		if lastPush := lastPushTime(db, mr.ID); lastPush != nil {
+			var answered int64
+			db.Model(&models.MRComment{}).
+				Where(`merge_request_id = ? AND is_last_in_thread = ? AND thread_starter_id IS NOT NULL AND author_id != ? AND gitlab_created_at < ?
+					AND EXISTS (SELECT 1 FROM mr_comments starter WHERE starter.gitlab_discussion_id = mr_comments.gitlab_discussion_id AND starter.resolvable = ? AND starter.resolved = ?)`,
+					mr.ID, true, mr.AuthorID, *lastPush, true, false).
+				Count(&answered)
+			if answered > 0 {
+				candidates = append(candidates, *lastPush)
+			}
+		}
+
 		var lastResolved models.MRAction
-		err := db.Where("merge_request_id = ? AND action_type = ?", mr.ID, models.ActionCommentResolved).
+		err = db.Where("merge_request_id = ? AND action_type = ?", mr.ID, models.ActionCommentResolved).
 			Order("timestamp DESC").
 			First(&lastResolved).Error
 		if err == nil {
@@ -293,9 +379,9 @@ func HasUnresolvedComments(db *gorm.DB, mrID uint) bool {
 func HasThreadsAwaitingAuthor(db *gorm.DB, mrID uint, mrAuthorID uint) bool {
 	var count int64
 	db.Model(&models.MRComment{}).
-		Where(`merge_request_id = ? AND is_last_in_thread = ? AND thread_starter_id IS NOT NULL AND author_id != ?
+		Where(`merge_request_id = ? AND is_last_in_thread = ? AND thread_starter_id IS NOT NULL AND author_id != ? AND gitlab_created_at > ?
 			AND EXISTS (SELECT 1 FROM mr_comments starter WHERE starter.gitlab_discussion_id = mr_comments.gitlab_discussion_id AND starter.resolvable = ? AND starter.resolved = ?)`,
-			mrID, true, mrAuthorID, true, false).
+			mrID, true, mrAuthorID, pushCutoff(lastPushTime(db, mrID)), true, false).
 		Count(&count)
 	return count > 0
 }
@@ -305,9 +391,9 @@ func HasThreadsAwaitingAuthor(db *gorm.DB, mrID uint, mrAuthorID uint) bool {
 func CountThreadsAwaitingAuthor(db *gorm.DB, mrID uint, mrAuthorID uint) int64 {
 	var count int64
 	db.Model(&models.MRComment{}).
-		Where(`merge_request_id = ? AND is_last_in_thread = ? AND thread_starter_id IS NOT NULL AND author_id != ?
+		Where(`merge_request_id = ? AND is_last_in_thread = ? AND thread_starter_id IS NOT NULL AND author_id != ? AND gitlab_created_at > ?
 			AND EXISTS (SELECT 1 FROM mr_comments starter WHERE starter.gitlab_discussion_id = mr_comments.gitlab_discussion_id AND starter.resolvable = ? AND starter.resolved = ?)`,
-			mrID, true, mrAuthorID, true, false).
+			mrID, true, mrAuthorID, pushCutoff(lastPushTime(db, mrID)), true, false).
 		Count(&count)
 	return count
 }
@@ -334,9 +420,14 @@ func GetUserStateTransitionTime(db *gorm.DB, mr *models.MergeRequest, userID uin
 }
 
 // getAuthorStateTransitionTime returns when author entered current state.
+// If the MR is blocked, conflicted, has a failed pipeline or is ready to merge → that state's time
 // If author has unresolved threads awaiting their response → on_fixes → earliest awaiting thread
 // Otherwise → on_review → use existing logic
 func getAuthorStateTransitionTime(db *gorm.DB, mr *models.MergeRequest) *time.Time {
+	switch state := DeriveState(db, mr); state {
+	case StateBlocked, StateConflicted, StatePipelineFailed, StateReadyToMerge:
+		return GetStateTransitionTime(db, mr, state)
+	}
 	if HasThreadsAwaitingAuthor(db, mr.ID, mr.AuthorID) {
 		return getAuthorOnFixesTime(db, mr)
 	}
@@ -346,16 +437,17 @@ func getAuthorStateTransitionTime(db *gorm.DB, mr *models.MergeRequest) *time.Ti
 // getAuthorOnFixesTime finds when author entered on_fixes state.
 // Returns the earliest time when an unresolved thread started awaiting author response.
 func getAuthorOnFixesTime(db *gorm.DB, mr *models.MergeRequest) *time.Time {
+	lastPush := lastPushTime(db, mr.ID)
 	var awaitingThreads []struct {
 		DiscussionID string
 	}
 	db.Model(&models.MRComment{}).
 		Select("DISTINCT gitlab_discussion_id as discussion_id").
-		Where(`merge_request_id = ? AND is_last_in_thread = ? AND author_id != ?
+		Where(`merge_request_id = ? AND is_last_in_thread = ? AND author_id != ? AND gitlab_created_at > ?
 			AND EXISTS (SELECT 1 FROM mr_comments starter
 				WHERE starter.gitlab_discussion_id = mr_comments.gitlab_discussion_id
 				AND starter.resolvable = ? AND starter.resolved = ?)`,
-			mr.ID, true, mr.AuthorID, true, false).
+			mr.ID, true, mr.AuthorID, pushCutoff(lastPush), true, false).
 		Find(&awaitingThreads)
 
 	if len(awaitingThreads) == 0 {
@@ -364,7 +456,7 @@ func getAuthorOnFixesTime(db *gorm.DB, mr *models.MergeRequest) *time.Time {
 
 	var earliestTime *time.Time
 	for _, thread := range awaitingThreads {
-		waitStart := getThreadAwaitingAuthorTime(db, thread.DiscussionID, mr.AuthorID)
+		waitStart := getThreadAwaitingAuthorTime(db, thread.DiscussionID, mr.AuthorID, lastPush)
 		if waitStart != nil && (earliestTime == nil || waitStart.Before(*earliestTime)) {
 			earliestTime = waitStart
 		}
@@ -373,8 +465,8 @@ func getAuthorOnFixesTime(db *gorm.DB, mr *models.MergeRequest) *time.Time {
 }
 
 // getThreadAwaitingAuthorTime returns when author started being awaited in this thread.
-// This is when reviewer commented after author's last reply (or thread creation if no author reply).
-func getThreadAwaitingAuthorTime(db *gorm.DB, discussionID string, mrAuthorID uint) *time.Time {
+// This is when reviewer commented after author's last reply or push (or thread creation if neither).
+func getThreadAwaitingAuthorTime(db *gorm.DB, discussionID string, mrAuthorID uint, lastPush *time.Time) *time.Time {
 	var comments []models.MRComment
 	db.Where("gitlab_discussion_id = ?", discussionID).
 		Order("gitlab_created_at ASC").
@@ -392,6 +484,7 @@ func getThreadAwaitingAuthorTime(db *gorm.DB, discussionID string, mrAuthorID ui
 		}
 	}
 
+	lastAuthorTime = laterTime(lastAuthorTime, lastPush)
 	if lastAuthorTime == nil {
 		for _, c := range comments {
 			if c.AuthorID != mrAuthorID {
@@ -416,6 +509,34 @@ func getThreadAwaitingAuthorTime(db *gorm.DB, discussionID string, mrAuthorID ui
 // If reviewer participated in unresolved threads where last comment is not from MR author → waiting for author
 // Otherwise → needs action → when they entered that state
 func getReviewerStateTransitionTime(db *gorm.DB, mr *models.MergeRequest, reviewerID uint) *time.Time {
+	awaitingThreads := getThreadsAwaitingAuthorForReviewer(db, mr, reviewerID)
+	if len(awaitingThreads) == 0 {
+		return getReviewerNeedsActionTime(db, mr, reviewerID)
+	}
+
+	lastPush := lastPushTime(db, mr.ID)
+	var earliestWaitStart *time.Time
+	for _, discussionID := range awaitingThreads {
+		waitStart := getThreadWaitStartForReviewer(db, discussionID, mr.AuthorID, reviewerID, lastPush)
+		if waitStart != nil && (earliestWaitStart == nil || waitStart.Before(*earliestWaitStart)) {
+			earliestWaitStart = waitStart
+		}
+	}
+	return earliestWaitStart
+}
+
+// GetReviewerPendingSince returns when the reviewer entered "needs action" state,
+// or nil if the reviewer is waiting for the author in an unresolved thread.
+func GetReviewerPendingSince(db *gorm.DB, mr *models.MergeRequest, reviewerID uint) *time.Time {
+	if len(getThreadsAwaitingAuthorForReviewer(db, mr, reviewerID)) > 0 {
+		return nil
+	}
+	return getReviewerNeedsActionTime(db, mr, reviewerID)
+}
+
+// getThreadsAwaitingAuthorForReviewer returns discussion IDs of unresolved threads where the reviewer
+// commented after the MR author's last reply and push, and the last comment is not from the author.
+func getThreadsAwaitingAuthorForReviewer(db *gorm.DB, mr *models.MergeRequest, reviewerID uint) []string {
 	var awaitingThreads []struct {
 		DiscussionID string
 	}
@@ -441,25 +562,19 @@ func getReviewerStateTransitionTime(db *gorm.DB, mr *models.MergeRequest, review
 			   AND ac.author_id = ?),
 			'0001-01-01'
 		  )
-	`, mr.ID, reviewerID, true, false, true, mr.AuthorID, mr.AuthorID).Scan(&awaitingThreads)
-
-	if len(awaitingThreads) == 0 {
-		return getReviewerNeedsActionTime(db, mr, reviewerID)
-	}
+		  AND rc.gitlab_created_at > ?
+	`, mr.ID, reviewerID, true, false, true, mr.AuthorID, mr.AuthorID, pushCutoff(lastPushTime(db, mr.ID))).Scan(&awaitingThreads)
 
-	var earliestWaitStart *time.Time
-	for _, thread := range awaitingThreads {
-		waitStart := getThreadWaitStartForReviewer(db, thread.DiscussionID, mr.AuthorID, reviewerID)
-		if waitStart != nil && (earliestWaitStart == nil || waitStart.Before(*earliestWaitStart)) {
-			earliestWaitStart = waitStart
-		}
+	ids := make([]string, len(awaitingThreads))
+	for i, thread := range awaitingThreads {
+		ids[i] = thread.DiscussionID
 	}
-	return earliestWaitStart
+	return ids
 }
 
 // getThreadWaitStartForReviewer returns when a specific reviewer started waiting for author in this thread.
-// This is when the reviewer commented after the MR author's last reply (or their first comment if no author reply).
-func getThreadWaitStartForReviewer(db *gorm.DB, discussionID string, mrAuthorID uint, reviewerID uint) *time.Time {
+// This is when the reviewer commented after the MR author's last reply or push (or their first comment if neither).
+func getThreadWaitStartForReviewer(db *gorm.DB, discussionID string, mrAuthorID uint, reviewerID uint, lastPush *time.Time) *time.Time {
 	var comments []models.MRComment
 	db.Where("gitlab_discussion_id = ?", discussionID).
 		Order("gitlab_created_at ASC").
@@ -477,6 +592,7 @@ func getThreadWaitStartForReviewer(db *gorm.DB, discussionID string, mrAuthorID
 		}
 	}
 
+	lastAuthorTime = laterTime(lastAuthorTime, lastPush)
 	if lastAuthorTime == nil {
 		for _, c := range comments {
 			if c.AuthorID == reviewerID {
@@ -517,6 +633,16 @@ func getReviewerNeedsActionTime(db *gorm.DB, mr *models.MergeRequest, reviewerID
 		candidates = append(candidates, lastAuthorReply.GitlabCreatedAt)
 	}
 
+	if lastPush := lastPushTime(db, mr.ID); lastPush != nil {
+		var commented int64
+		db.Model(&models.MRComment{}).
+			Where("merge_request_id = ? AND author_id = ? AND gitlab_created_at < ?", mr.ID, reviewerID, *lastPush).
+			Count(&commented)
+		if commented > 0 {
+			candidates = append(candidates, *lastPush)
+		}
+	}
+
 	var lastResolved models.MRAction
 	err = db.Where(`merge_request_id = ? AND action_type = ?
 		AND comment_id IN (
@@ -570,9 +696,21 @@ func DeriveStateFromCache(mr *models.MergeRequest, cache *MRDataCache) MRState {
 	if mr.Draft {
 		return StateDraft
 	}
+	if cache.hasBlockLabel(mr.ID, mr.RepositoryID) {
+		return StateBlocked
+	}
+	if IsMRConflicted(mr) {
+		return StateConflicted
+	}
+	if mr.PipelineStatus == PipelineFailed {
+		return StatePipelineFailed
+	}
 	if HasThreadsAwaitingAuthorFromCache(mr.ID, mr.AuthorID, cache) {
 		return StateOnFixes
 	}
+	if mr.ApprovalRulesSatisfied && cache.isFullyApproved(mr.ID) {
+		return StateReadyToMerge
+	}
 	return StateOnReview
 }
 
@@ -596,10 +734,12 @@ func HasThreadsAwaitingAuthorFromCache(mrID uint, mrAuthorID uint, cache *MRData
 	// Find last comments in threads (marked with IsLastInThread) where:
 	// - thread_starter_id IS NOT NULL (it's part of a thread)
 	// - author_id != mrAuthorID (not the MR author)
+	// - it was written after the last push
 	// - the discussion has an unresolved resolvable comment
+	cutoff := pushCutoff(lastPushTimeFromCache(mrID, cache))
 	for i := range comments {
 		c := &comments[i]
-		if c.IsLastInThread && c.ThreadStarterID != nil && c.AuthorID != mrAuthorID {
+		if c.IsLastInThread && c.ThreadStarterID != nil && c.AuthorID != mrAuthorID && c.GitlabCreatedAt.After(cutoff) {
 			if unresolvedDiscussions[c.GitlabDiscussionID] {
 				return true
 			}
@@ -626,11 +766,12 @@ func CountThreadsAwaitingAuthorFromCache(mrID uint, mrAuthorID uint, cache *MRDa
 		}
 	}
 
-	// Count last comments in threads where author is NOT the MR author
+	// Count last comments in threads where author is NOT the MR author, written after the last push
+	cutoff := pushCutoff(lastPushTimeFromCache(mrID, cache))
 	var count int64
 	for i := range comments {
 		c := &comments[i]
-		if c.IsLastInThread && c.ThreadStarterID != nil && c.AuthorID != mrAuthorID {
+		if c.IsLastInThread && c.ThreadStarterID != nil && c.AuthorID != mrAuthorID && c.GitlabCreatedAt.After(cutoff) {
 			if unresolvedDiscussions[c.GitlabDiscussionID] {
 				count++
 			}
@@ -693,6 +834,18 @@ func GetStateTransitionTimeFromCache(mr *models.MergeRequest, state MRState, cac
 		}
 		return mr.GitlabCreatedAt
 
+	case StateBlocked:
+		return sinceOrCreated(mr, blockedSince(cache.Actions[mr.ID]))
+
+	case StateConflicted:
+		return sinceOrCreated(mr, conflictSince(cache.Actions[mr.ID]))
+
+	case StatePipelineFailed:
+		return sinceOrCreated(mr, lastActionTime(cache.Actions[mr.ID], models.ActionPipelineFailed))
+
+	case StateReadyToMerge:
+		return sinceOrCreated(mr, lastActionTime(cache.Actions[mr.ID], models.ActionApproved))
+
 	case StateOnFixes:
 		comments := cache.Comments[mr.ID]
 		if len(comments) == 0 {
@@ -758,6 +911,16 @@ func GetStateTransitionTimeFromCache(mr *models.MergeRequest, state MRState, cac
 		actions := cache.Actions[mr.ID]
 		var candidates []time.Time
 
+		// Find last conflict resolution, pipeline fix or revoked approval
+		if t := lastActionTime(actions, reviewResumingActions...); t != nil {
+			candidates = append(candidates, *t)
+		}
+
+		// Find last push that answered open threads
+		if lastPush := lastPushTimeFromCache(mr.ID, cache); lastPush != nil && threadsAnsweredByPush(mr, *lastPush, cache) {
+			candidates = append(candidates, *lastPush)
+		}
+
 		// Find last resolved action
 		for i := len(actions) - 1; i >= 0; i-- {
 			if actions[i].ActionType == models.ActionCommentResolved {
@@ -798,6 +961,150 @@ func GetStateTransitionTimeFromCache(mr *models.MergeRequest, state MRState, cac
 	return nil
 }
 
+// reviewResumingActions hand an MR back to its reviewers when it leaves the conflicted,
+// pipeline_failed or ready_to_merge state.
+var reviewResumingActions = []models.MRActionType{
+	models.ActionConflictResolved,
+	models.ActionPipelineFixed,
+	models.ActionUnapproved,
+}
+
+// mrActionsOfType returns the MR's actions of the given types sorted by timestamp.
+func mrActionsOfType(db *gorm.DB, mrID uint, types ...models.MRActionType) []models.MRAction {
+	var actions []models.MRAction
+	db.Where("merge_request_id = ? AND action_type IN ?", mrID, types).
+		Order("timestamp ASC").
+		Find(&actions)
+	return actions
+}
+
+// lastActionTime returns the timestamp of the latest action of one of the given types, or nil.
+// actions must be sorted by timestamp.
+func lastActionTime(actions []models.MRAction, types ...models.MRActionType) *time.Time {
+	for i := len(actions) - 1; i >= 0; i-- {
+		for _, at := range types {
+			if actions[i].ActionType == at {
+				t := actions[i].Timestamp
+				return &t
+			}
+		}
+	}
+	return nil
+}
+
+// sinceOrCreated returns since, falling back to the MR creation time when the transition
+// predates action tracking.
+func sinceOrCreated(mr *models.MergeRequest, since *time.Time) *time.Time {
+	if since != nil {
+		return since
+	}
+	return mr.GitlabCreatedAt
+}
+
+// blockedSince returns when the MR went from no block labels to at least one, unless all of
+// them were removed since. actions must be sorted by timestamp.
+func blockedSince(actions []models.MRAction) *time.Time {
+	active := 0
+	var since *time.Time
+	for _, a := range actions {
+		switch a.ActionType {
+		case models.ActionBlockLabelAdded:
+			if active == 0 {
+				t := a.Timestamp
+				since = &t
+			}
+			active++
+		case models.ActionBlockLabelRemoved:
+			if active > 0 {
+				active--
+			}
+			if active == 0 {
+				since = nil
+			}
+		}
+	}
+	return since
+}
+
+// conflictSince returns when the current conflict started: the last conflict_detected action,
+// unless a conflict_resolved follows it. actions must be sorted by timestamp.
+func conflictSince(actions []models.MRAction) *time.Time {
+	var since *time.Time
+	for _, a := range actions {
+		switch a.ActionType {
+		case models.ActionConflictDetected:
+			if since == nil {
+				t := a.Timestamp
+				since = &t
+			}
+		case models.ActionConflictResolved:
+			since = nil
+		}
+	}
+	return since
+}
+
+// lastPushTime returns when commits were last pushed to the MR, or nil. A push counts as the
+// author's reply to every open thread.
+func lastPushTime(db *gorm.DB, mrID uint) *time.Time {
+	var action models.MRAction
+	if err := db.Where("merge_request_id = ? AND action_type = ?", mrID, models.ActionCommitsPushed).
+		Order("timestamp DESC").
+		First(&action).Error; err != nil {
+		return nil
+	}
+	return &action.Timestamp
+}
+
+// lastPushTimeFromCache returns when commits were last pushed to the MR using cached data.
+func lastPushTimeFromCache(mrID uint, cache *MRDataCache) *time.Time {
+	actions := cache.Actions[mrID]
+	for i := len(actions) - 1; i >= 0; i-- {
+		if actions[i].ActionType == models.ActionCommitsPushed {
+			t := actions[i].Timestamp
+			return &t
+		}
+	}
+	return nil
+}
+
+// pushCutoff returns the time comments must follow to still await the author: the last push,
+// or the zero time when nothing was pushed.
+func pushCutoff(lastPush *time.Time) time.Time {
+	if lastPush == nil {
+		return time.Time{}
+	}
+	return *lastPush
+}
+
+// laterTime returns the later of two optional times.
+func laterTime(a, b *time.Time) *time.Time {
+	if a == nil || (b != nil && b.After(*a)) {
+		return b
+	}
+	return a
+}
+
+// threadsAnsweredByPush reports whether an unresolved thread's last comment is from someone other
+// than the MR author and predates the push, using cached data.
+func threadsAnsweredByPush(mr *models.MergeRequest, push time.Time, cache *MRDataCache) bool {
+	comments := cache.Comments[mr.ID]
+	unresolvedDiscussions := make(map[string]bool)
+	for i := range comments {
+		if comments[i].Resolvable && !comments[i].Resolved {
+			unresolvedDiscussions[comments[i].GitlabDiscussionID] = true
+		}
+	}
+	for i := range comments {
+		c := &comments[i]
+		if c.IsLastInThread && c.ThreadStarterID != nil && c.AuthorID != mr.AuthorID &&
+			c.GitlabCreatedAt.Before(push) && unresolvedDiscussions[c.GitlabDiscussionID] {
+			return true
+		}
+	}
+	return false
+}
+
 // CalculateBlockedTimeFromCache calculates total working time an MR was blocked by block labels
 // within the given time window using cached data.
 func CalculateBlockedTimeFromCache(mrID uint, repoID uint, start, end time.Time, actions []models.MRAction, holidays map[string]bool) time.Duration {
@@ -876,6 +1183,10 @@ func GetUserStateTransitionTimeFromCache(mr *models.MergeRequest, userID uint, c
 
 // getAuthorStateTransitionTimeFromCache returns when author entered current state using cached data.
 func getAuthorStateTransitionTimeFromCache(mr *models.MergeRequest, cache *MRDataCache) *time.Time {
+	switch state := DeriveStateFromCache(mr, cache); state {
+	case StateBlocked, StateConflicted, StatePipelineFailed, StateReadyToMerge:
+		return GetStateTransitionTimeFromCache(mr, state, cache)
+	}
 	if HasThreadsAwaitingAuthorFromCache(mr.ID, mr.AuthorID, cache) {
 		return getAuthorOnFixesTimeFromCache(mr, cache)
 	}
@@ -905,13 +1216,15 @@ func getAuthorOnFixesTimeFromCache(mr *models.MergeRequest, cache *MRDataCache)
 		}
 	}
 
+	lastPush := lastPushTimeFromCache(mr.ID, cache)
+	cutoff := pushCutoff(lastPush)
 	var awaitingDiscussionIDs []string
 	for discussionID, starter := range threadStarters {
 		if starter.Resolved {
 			continue
 		}
 		lastComment := threadLastComments[discussionID]
-		if lastComment != nil && lastComment.AuthorID != mr.AuthorID {
+		if lastComment != nil && lastComment.AuthorID != mr.AuthorID && lastComment.GitlabCreatedAt.After(cutoff) {
 			awaitingDiscussionIDs = append(awaitingDiscussionIDs, discussionID)
 		}
 	}
@@ -922,7 +1235,7 @@ func getAuthorOnFixesTimeFromCache(mr *models.MergeRequest, cache *MRDataCache)
 
 	var earliestTime *time.Time
 	for _, discussionID := range awaitingDiscussionIDs {
-		waitStart := getThreadAwaitingAuthorTimeFromCache(discussionID, mr.AuthorID, cache)
+		waitStart := getThreadAwaitingAuthorTimeFromCache(discussionID, mr.AuthorID, lastPush, cache)
 		if waitStart != nil && (earliestTime == nil || waitStart.Before(*earliestTime)) {
 			earliestTime = waitStart
 		}
@@ -931,7 +1244,7 @@ func getAuthorOnFixesTimeFromCache(mr *models.MergeRequest, cache *MRDataCache)
 }
 
 // getThreadAwaitingAuthorTimeFromCache returns when author started being awaited in this thread using cached data.
-func getThreadAwaitingAuthorTimeFromCache(discussionID string, mrAuthorID uint, cache *MRDataCache) *time.Time {
+func getThreadAwaitingAuthorTimeFromCache(discussionID string, mrAuthorID uint, lastPush *time.Time, cache *MRDataCache) *time.Time {
 	comments := cache.CommentsByDiscussion[discussionID]
 	if len(comments) == 0 {
 		return nil
@@ -946,6 +1259,7 @@ func getThreadAwaitingAuthorTimeFromCache(discussionID string, mrAuthorID uint,
 		}
 	}
 
+	lastAuthorTime = laterTime(lastAuthorTime, lastPush)
 	if lastAuthorTime == nil {
 		for _, c := range comments {
 			if c.AuthorID != mrAuthorID {
@@ -967,8 +1281,8 @@ func getThreadAwaitingAuthorTimeFromCache(discussionID string, mrAuthorID uint,
 }
 
 // reviewerParticipatedAfterAuthor checks if reviewer wrote any comment in the thread
-// after the MR author's last comment (or MR author has no comments in the thread).
-func reviewerParticipatedAfterAuthor(threadComments []models.MRComment, reviewerID uint, mrAuthorID uint) bool {
+// after the MR author's last comment or push (or the author has done neither).
+func reviewerParticipatedAfterAuthor(threadComments []models.MRComment, reviewerID uint, mrAuthorID uint, lastPush *time.Time) bool {
 	var lastAuthorTime *time.Time
 	for i := len(threadComments) - 1; i >= 0; i-- {
 		if threadComments[i].AuthorID == mrAuthorID {
@@ -978,6 +1292,7 @@ func reviewerParticipatedAfterAuthor(threadComments []models.MRComment, reviewer
 		}
 	}
 
+	lastAuthorTime = laterTime(lastAuthorTime, lastPush)
 	for _, c := range threadComments {
 		if c.AuthorID == reviewerID {
 			if lastAuthorTime == nil || c.GitlabCreatedAt.After(*lastAuthorTime) {
@@ -1006,7 +1321,8 @@ func getReviewerStateTransitionTimeFromCache(mr *models.MergeRequest, reviewerID
 		}
 	}
 
-	// Find threads where reviewer participated after author's last reply
+	// Find threads where reviewer participated after author's last reply or push
+	lastPush := lastPushTimeFromCache(mr.ID, cache)
 	var awaitingDiscussionIDs []string
 	for discussionID := range unresolvedDiscussions {
 		lastComment := threadLastComments[discussionID]
@@ -1014,7 +1330,7 @@ func getReviewerStateTransitionTimeFromCache(mr *models.MergeRequest, reviewerID
 			continue
 		}
 		threadComments := cache.CommentsByDiscussion[discussionID]
-		if reviewerParticipatedAfterAuthor(threadComments, reviewerID, mr.AuthorID) {
+		if reviewerParticipatedAfterAuthor(threadComments, reviewerID, mr.AuthorID, lastPush) {
 			awaitingDiscussionIDs = append(awaitingDiscussionIDs, discussionID)
 		}
 	}
@@ -1025,7 +1341,7 @@ func getReviewerStateTransitionTimeFromCache(mr *models.MergeRequest, reviewerID
 
 	var earliestWaitStart *time.Time
 	for _, discussionID := range awaitingDiscussionIDs {
-		waitStart := getThreadWaitStartForReviewerFromCache(discussionID, mr.AuthorID, reviewerID, cache)
+		waitStart := getThreadWaitStartForReviewerFromCache(discussionID, mr.AuthorID, reviewerID, lastPush, cache)
 		if waitStart != nil && (earliestWaitStart == nil || waitStart.Before(*earliestWaitStart)) {
 			earliestWaitStart = waitStart
 		}
@@ -1034,7 +1350,7 @@ func getReviewerStateTransitionTimeFromCache(mr *models.MergeRequest, reviewerID
 }
 
 // getThreadWaitStartForReviewerFromCache returns when a specific reviewer started waiting for author in this thread using cached data.
-func getThreadWaitStartForReviewerFromCache(discussionID string, mrAuthorID uint, reviewerID uint, cache *MRDataCache) *time.Time {
+func getThreadWaitStartForReviewerFromCache(discussionID string, mrAuthorID uint, reviewerID uint, lastPush *time.Time, cache *MRDataCache) *time.Time {
 	comments := cache.CommentsByDiscussion[discussionID]
 	if len(comments) == 0 {
 		return nil
@@ -1049,6 +1365,7 @@ func getThreadWaitStartForReviewerFromCache(discussionID string, mrAuthorID uint
 		}
 	}
 
+	lastAuthorTime = laterTime(lastAuthorTime, lastPush)
 	if lastAuthorTime == nil {
 		for _, c := range comments {
 			if c.AuthorID == reviewerID {
@@ -1100,6 +1417,16 @@ func getReviewerNeedsActionTimeFromCache(mr *models.MergeRequest, reviewerID uin
 		}
 	}
 
+	// A push after the reviewer commented answers their threads
+	if lastPush := lastPushTimeFromCache(mr.ID, cache); lastPush != nil {
+		for _, c := range comments {
+			if c.AuthorID == reviewerID && c.GitlabCreatedAt.Before(*lastPush) {
+				candidates = append(candidates, *lastPush)
+				break
+			}
+		}
+	}
+
 	// Find last resolved action for reviewer's discussion threads
 	for i := len(actions) - 1; i >= 0; i-- {
 		a := &actions[i]
@@ -420,6 +420,10 @@ func TestMRStateConstants(t *testing.T) {
 		{StateDraft, "draft"},
 		{StateMerged, "merged"},
 		{StateClosed, "closed"},
+		{StateBlocked, "blocked"},
+		{StateConflicted, "conflicted"},
+		{StatePipelineFailed, "pipeline_failed"},
+		{StateReadyToMerge, "ready_to_merge"},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"devstreamlinebot/models"
+)
+
+// NotificationEvent is a kind of DM the bot sends to users.
+type NotificationEvent string
+
+const (
+	NotifyAssignment      NotificationEvent = "assignment"       // Assigned as reviewer
+	NotifyStateChange     NotificationEvent = "state_change"     // MR needs fixes or is ready for re-review
+	NotifyFullyApproved   NotificationEvent = "fully_approved"   // Own MR fully approved
+	NotifyReviewerRemoved NotificationEvent = "reviewer_removed" // Removed from a review
+	NotifyRelease         NotificationEvent = "release"          // MR ready for release (release managers)
+	NotifySLAWarning      NotificationEvent = "sla_warning"      // Review or fixes SLA exceeded
+	NotifyPipeline        NotificationEvent = "pipeline"         // Pipeline of own MR failed
+	NotifyConflict        NotificationEvent = "conflict"         // Own MR got merge conflicts or needs a rebase
+	NotifyStale           NotificationEvent = "stale"            // Own MR has had no activity for a while
+	NotifyAutoMerge       NotificationEvent = "auto_merge"       // Own MR was merged by the bot or auto-merge failed
+)
+
+// NotificationEvents lists all events in display order.
+var NotificationEvents = []NotificationEvent{
+	NotifyAssignment, NotifyStateChange, NotifyFullyApproved, NotifyReviewerRemoved, NotifyRelease, NotifySLAWarning,
+	NotifyPipeline, NotifyConflict, NotifyStale, NotifyAutoMerge,
+}
+
+// notificationColumns maps events to NotificationPreference columns.
+var notificationColumns = map[NotificationEvent]string{
+	NotifyAssignment:      "assignment",
+	NotifyStateChange:     "state_change",
+	NotifyFullyApproved:   "fully_approved",
+	NotifyReviewerRemoved: "reviewer_removed",
+	NotifyRelease:         "release",
+	NotifySLAWarning:      "sla_warning",
+	NotifyPipeline:        "pipeline",
+	NotifyConflict:        "conflict",
+	NotifyStale:           "stale",
+	NotifyAutoMerge:       "auto_merge",
+}
+
+// IsValidNotificationEvent reports whether s names a notification event.
+func IsValidNotificationEvent(s string) bool {
+	_, ok := notificationColumns[NotificationEvent(s)]
+	return ok
+}
+
+// GetNotificationPreference returns a user's notification preference, or nil if they never changed one.
+func GetNotificationPreference(db *gorm.DB, userID uint) *models.NotificationPreference {
+	var pref models.NotificationPreference
+	if err := db.Where("user_id = ?", userID).First(&pref).Error; err != nil {
+		return nil
+	}
+	return &pref
+}
+
+// UpdateNotificationPreference creates the user's preference with defaults if missing and applies updates.
+func UpdateNotificationPreference(db *gorm.DB, userID uint, updates map[string]interface{}) (*models.NotificationPreference, error) {
+	var pref models.NotificationPreference
+	if err := db.Where(models.NotificationPreference{UserID: userID}).FirstOrCreate(&pref).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Model(&pref).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	if err := db.First(&pref, pref.ID).Error; err != nil {
+		return nil, err
+	}
+	return &pref, nil
+}
+
+// NotificationColumn returns the NotificationPreference column of an event.
+func NotificationColumn(event NotificationEvent) string {
+	return notificationColumns[event]
+}
+
+// NotificationEnabled reports whether a preference lets event through. A nil preference allows everything.
+func NotificationEnabled(pref *models.NotificationPreference, event NotificationEvent) bool {
+	if pref == nil {
+		return true
+	}
+	switch event {
+	case NotifyAssignment:
+		return pref.Assignment
+	case NotifyStateChange:
+		return pref.StateChange
+	case NotifyFullyApproved:
+		return pref.FullyApproved
+	case NotifyReviewerRemoved:
+		return pref.ReviewerRemoved
+	case NotifyRelease:
+		return pref.Release
+	case NotifySLAWarning:
+		return pref.SLAWarning
+	case NotifyPipeline:
+		return pref.Pipeline
+	case NotifyConflict:
+		return pref.Conflict
+	case NotifyStale:
+		return pref.Stale
+	case NotifyAutoMerge:
+		return pref.AutoMerge
+	}
+	return true
+}
+
+// InQuietHours reports whether now falls in the preference's quiet hours. Windows may wrap
+// midnight (e.g. 22-8).
+func InQuietHours(pref *models.NotificationPreference, now time.Time) bool {
+	if pref == nil || pref.QuietStart < 0 || pref.QuietEnd < 0 || pref.QuietStart == pref.QuietEnd {
+		return false
+	}
+	hour := now.UTC().Add(time.Duration(pref.TimezoneOffset) * time.Hour).Hour()
+	if pref.QuietStart < pref.QuietEnd {
+		return hour >= pref.QuietStart && hour < pref.QuietEnd
+	}
+	return hour >= pref.QuietStart || hour < pref.QuietEnd
+}
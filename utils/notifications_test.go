@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+// TestInQuietHours tests plain and midnight-wrapping quiet hour windows in the user's timezone.
+func TestInQuietHours(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2026, 10, 19, hour, 30, 0, 0, time.UTC) }
+
+	pref := &models.NotificationPreference{QuietStart: 22, QuietEnd: 8, TimezoneOffset: 3}
+	if !InQuietHours(pref, at(20)) { // 23:30 local
+		t.Error("expected 23:30 to be quiet")
+	}
+	if !InQuietHours(pref, at(3)) { // 06:30 local
+		t.Error("expected 06:30 to be quiet")
+	}
+	if InQuietHours(pref, at(6)) { // 09:30 local
+		t.Error("expected 09:30 not to be quiet")
+	}
+
+	pref = &models.NotificationPreference{QuietStart: 12, QuietEnd: 14}
+	if !InQuietHours(pref, at(13)) || InQuietHours(pref, at(14)) {
+		t.Error("unexpected daytime window")
+	}
+	if InQuietHours(&models.NotificationPreference{QuietStart: -1, QuietEnd: -1}, at(0)) || InQuietHours(nil, at(0)) {
+		t.Error("expected no quiet hours when disabled")
+	}
+}
+
+// TestUpdateNotificationPreference tests that new preferences default to all events on and that events can be turned off.
+func TestUpdateNotificationPreference(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	user := testutils.NewUserFactory(db).Create()
+
+	if pref := GetNotificationPreference(db, user.ID); pref != nil || !NotificationEnabled(pref, NotifyAssignment) {
+		t.Fatal("expected no preference and everything enabled")
+	}
+
+	pref, err := UpdateNotificationPreference(db, user.ID, map[string]interface{}{NotificationColumn(NotifyStateChange): false})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if NotificationEnabled(pref, NotifyStateChange) {
+		t.Error("expected state_change turned off")
+	}
+	for _, e := range NotificationEvents {
+		if e != NotifyStateChange && !NotificationEnabled(pref, e) {
+			t.Errorf("expected %s to stay on", e)
+		}
+	}
+	if pref.QuietStart != -1 || pref.TimezoneOffset != 3 {
+		t.Errorf("unexpected defaults: %+v", pref)
+	}
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"gorm.io/gorm"
+
+	"devstreamlinebot/models"
+)
+
+// GitLab pipeline statuses the bot acts on.
+const (
+	PipelineSuccess  = "success"
+	PipelineFailed   = "failed"
+	PipelineCanceled = "canceled"
+	PipelineSkipped  = "skipped"
+	PipelineManual   = "manual"
+)
+
+// IsPipelineFinished reports whether a pipeline status will not change anymore without a new push
+// or a manual action, so the head pipeline does not need to be polled again.
+func IsPipelineFinished(status string) bool {
+	switch status {
+	case PipelineSuccess, PipelineFailed, PipelineCanceled, PipelineSkipped, PipelineManual:
+		return true
+	}
+	return false
+}
+
+// RepositoryHasCI reports whether any MR of the repository has had a head pipeline.
+// Repositories where none has are treated as having no CI.
+func RepositoryHasCI(db *gorm.DB, repoID uint) bool {
+	var count int64
+	db.Model(&models.MergeRequest{}).Where("repository_id = ? AND pipeline_id > 0", repoID).Limit(1).Count(&count)
+	return count > 0
+}
+
+// PipelineBadge returns a short badge for the MR's head pipeline, or "" if it has none.
+func PipelineBadge(mr *models.MergeRequest) string {
+	switch mr.PipelineStatus {
+	case "":
+		return ""
+	case PipelineSuccess:
+		return "CI ✅"
+	case PipelineFailed:
+		return "CI ❌"
+	case PipelineCanceled, PipelineSkipped:
+		return "CI ⚪"
+	}
+	return "CI ⏳"
+}
+
+// PipelineHoldsAssignment reports whether reviewer assignment waits for the MR's pipeline:
+// the repository enabled WaitForPipeline and the head pipeline has not succeeded yet. MRs
+// without a pipeline for their head commit are held unless the repository has no CI.
+func PipelineHoldsAssignment(db *gorm.DB, sla *models.RepositorySLA, mr *models.MergeRequest) bool {
+	if sla == nil || !sla.WaitForPipeline {
+		return false
+	}
+	if mr.PipelineSHA != mr.SHA || mr.PipelineStatus == "" {
+		return RepositoryHasCI(db, mr.RepositoryID) // Pipeline may not have started yet
+	}
+	return mr.PipelineStatus != PipelineSuccess
+}
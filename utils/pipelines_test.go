@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"testing"
+
+	"devstreamlinebot/testutils"
+)
+
+// TestRepositoryHasCI tests that a repository uses CI once any of its MRs had a head pipeline.
+func TestRepositoryHasCI(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repoFactory := testutils.NewRepositoryFactory(db)
+	repo := repoFactory.Create()
+	other := repoFactory.Create()
+	author := testutils.NewUserFactory(db).Create()
+	mrFactory := testutils.NewMergeRequestFactory(db)
+	mr := mrFactory.Create(repo, author)
+	mrFactory.Create(other, author)
+
+	if RepositoryHasCI(db, repo.ID) {
+		t.Error("expected no CI before any pipeline")
+	}
+	db.Model(&mr).UpdateColumn("pipeline_id", 7)
+	if !RepositoryHasCI(db, repo.ID) {
+		t.Error("expected CI after a pipeline")
+	}
+	if RepositoryHasCI(db, other.ID) {
+		t.Error("expected pipelines of other repositories to be ignored")
+	}
+}
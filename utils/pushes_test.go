@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+// TestDeriveState_PushAnswersThreads tests that a push after a reviewer's comment moves the MR
+// back to on_review from the push time, and that a new reviewer comment moves it to on_fixes again.
+func TestDeriveState_PushAnswersThreads(t *testing.T) {
+	db := setupTestDB(t)
+
+	reviewer := &models.User{GitlabID: 100, Username: "reviewer"}
+	db.Create(reviewer)
+	author := &models.User{GitlabID: 200, Username: "author"}
+	db.Create(author)
+	mr := &models.MergeRequest{State: "opened", AuthorID: author.ID}
+	db.Create(mr)
+
+	commentTime := time.Now().Add(-3 * time.Hour).UTC().Truncate(time.Second)
+	starter := &models.MRComment{
+		MergeRequestID:     mr.ID,
+		GitlabNoteID:       1,
+		GitlabDiscussionID: "disc-push-1",
+		AuthorID:           reviewer.ID,
+		Resolvable:         true,
+		GitlabCreatedAt:    commentTime,
+		ThreadStarterID:    &reviewer.ID,
+		IsLastInThread:     true,
+	}
+	db.Create(starter)
+	if state := DeriveState(db, mr); state != StateOnFixes {
+		t.Fatalf("DeriveState() = %v, want %v before the push", state, StateOnFixes)
+	}
+
+	pushTime := time.Now().Add(-2 * time.Hour).UTC().Truncate(time.Second)
+	db.Create(&models.MRAction{MergeRequestID: mr.ID, ActionType: models.ActionCommitsPushed, ActorID: &author.ID, Timestamp: pushTime})
+
+	if state := DeriveState(db, mr); state != StateOnReview {
+		t.Fatalf("DeriveState() = %v, want %v after the push", state, StateOnReview)
+	}
+	if since := GetStateTransitionTime(db, mr, StateOnReview); since == nil || !since.Equal(pushTime) {
+		t.Errorf("GetStateTransitionTime() = %v, want %v", since, pushTime)
+	}
+	if since := GetUserStateTransitionTime(db, mr, reviewer.ID); since == nil || !since.Equal(pushTime) {
+		t.Errorf("reviewer needs action since %v, want %v", since, pushTime)
+	}
+
+	cache, err := LoadMRDataCache(db, []uint{mr.ID}, nil)
+	if err != nil {
+		t.Fatalf("LoadMRDataCache() error: %v", err)
+	}
+	if state := DeriveStateFromCache(mr, cache); state != StateOnReview {
+		t.Errorf("DeriveStateFromCache() = %v, want %v", state, StateOnReview)
+	}
+	if since := GetStateTransitionTimeFromCache(mr, StateOnReview, cache); since == nil || !since.Equal(pushTime) {
+		t.Errorf("GetStateTransitionTimeFromCache() = %v, want %v", since, pushTime)
+	}
+	if since := GetUserStateTransitionTimeFromCache(mr, reviewer.ID, cache); since == nil || !since.Equal(pushTime) {
+		t.Errorf("cached reviewer needs action since %v, want %v", since, pushTime)
+	}
+
+	followUpTime := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	db.Model(starter).Update("is_last_in_thread", false)
+	db.Create(&models.MRComment{
+		MergeRequestID:     mr.ID,
+		GitlabNoteID:       2,
+		GitlabDiscussionID: "disc-push-1",
+		AuthorID:           reviewer.ID,
+		GitlabCreatedAt:    followUpTime,
+		ThreadStarterID:    &reviewer.ID,
+		IsLastInThread:     true,
+	})
+
+	if state := DeriveState(db, mr); state != StateOnFixes {
+		t.Fatalf("DeriveState() = %v, want %v after a new comment", state, StateOnFixes)
+	}
+	if since := GetUserStateTransitionTime(db, mr, author.ID); since == nil || !since.Equal(followUpTime) {
+		t.Errorf("author on fixes since %v, want %v", since, followUpTime)
+	}
+}
+
+// TestGetActiveReviewers_PushAnswersThread tests that a reviewer waiting in a thread becomes active again
+// once the author pushes, in both the active reviewer and personal digest queries.
+func TestGetActiveReviewers_PushAnswersThread(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	reviewer := userFactory.Create()
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author)
+	testutils.AssignReviewers(db, &mr, reviewer)
+
+	testutils.CreateMRComment(db, mr, reviewer, 1,
+		testutils.WithResolvable(),
+		testutils.WithDiscussionID("disc-push-active"),
+		testutils.WithThreadStarter(&reviewer),
+		testutils.WithIsLastInThread(),
+		testutils.WithCommentCreatedAt(time.Now().Add(-2*time.Hour)))
+
+	result, err := GetActiveReviewers(db, []uint{mr.ID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result[mr.ID]) != 0 {
+		t.Fatalf("expected reviewer waiting for the author before the push, got %d active", len(result[mr.ID]))
+	}
+
+	testutils.CreateMRAction(db, mr, models.ActionCommitsPushed, testutils.WithActor(author), testutils.WithTimestamp(time.Now().Add(-time.Hour)))
+
+	result, err = GetActiveReviewers(db, []uint{mr.ID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result[mr.ID]) != 1 {
+		t.Errorf("expected reviewer active after the push, got %d", len(result[mr.ID]))
+	}
+
+	reviewMRs, _, _, err := FindUserActionMRs(db, reviewer.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reviewMRs) != 1 {
+		t.Errorf("expected the MR in the reviewer's action items after the push, got %d", len(reviewMRs))
+	}
+}
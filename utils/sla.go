@@ -179,6 +179,28 @@ func SLAStatusString(elapsed, threshold time.Duration) string {
 	return fmt.Sprintf("%.0f%%", percentage)
 }
 
+// SLAThresholdForState returns the SLA that applies while an MR is in state: the review SLA
+// while reviewers are expected to act, the fixes SLA while the author is, and 0 (no SLA) for
+// blocked, ready-to-merge and finished MRs.
+func SLAThresholdForState(sla *models.RepositorySLA, state MRState) time.Duration {
+	switch {
+	case state == StateOnReview:
+		return sla.ReviewDuration.ToDuration()
+	case IsAuthorActionState(state):
+		return sla.FixesDuration.ToDuration()
+	}
+	return 0
+}
+
+// IsAuthorActionState reports whether an MR in state waits for its author rather than its reviewers.
+func IsAuthorActionState(state MRState) bool {
+	switch state {
+	case StateOnFixes, StateDraft, StateConflicted, StatePipelineFailed:
+		return true
+	}
+	return false
+}
+
 const DefaultSLADuration = models.Duration(48 * time.Hour)
 
 func GetRepositorySLA(db *gorm.DB, repoID uint) (*models.RepositorySLA, error) {
@@ -186,10 +208,11 @@ func GetRepositorySLA(db *gorm.DB, repoID uint) (*models.RepositorySLA, error) {
 	err := db.Where("repository_id = ?", repoID).First(&sla).Error
 	if err == gorm.ErrRecordNotFound {
 		return &models.RepositorySLA{
-			RepositoryID:   repoID,
-			ReviewDuration: DefaultSLADuration,
-			FixesDuration:  DefaultSLADuration,
-			AssignCount:    1,
+			RepositoryID:     repoID,
+			ReviewDuration:   DefaultSLADuration,
+			FixesDuration:    DefaultSLADuration,
+			AssignCount:      1,
+			MaxAutoReassigns: 1,
 		}, nil
 	}
 	if err != nil {
@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"devstreamlinebot/models"
+)
+
+// StaleLabel is added to stale MRs of repositories with /stale label on.
+const StaleLabel = "stale"
+
+// staleActivityActions are the actions that count as activity on an MR.
+var staleActivityActions = []models.MRActionType{
+	models.ActionCommitsPushed,
+	models.ActionCommentAdded,
+	models.ActionCommentResolved,
+	models.ActionApproved,
+	models.ActionUnapproved,
+	models.ActionDraftToggled,
+}
+
+// rebaseResetMetadata marks approvals reset by a rebase the bot started, which is not activity.
+const rebaseResetMetadata = `{"reason":"rebased"}`
+
+// staleActivity scopes a query to the MR's actions that count as activity.
+func staleActivity(db *gorm.DB, mrID uint) *gorm.DB {
+	return db.Where("merge_request_id = ? AND action_type IN ? AND NOT (action_type = ? AND COALESCE(metadata, '') = ?)",
+		mrID, staleActivityActions, models.ActionUnapproved, rebaseResetMetadata)
+}
+
+// StaleMR is an open MR without activity for at least its repository's stale threshold.
+type StaleMR struct {
+	MR   models.MergeRequest
+	Idle time.Duration // Working time since the last activity
+}
+
+// LastActivityTime returns when the MR last saw commits, comments, approvals or draft changes,
+// falling back to its GitLab update and creation times. GitLab updates after the MR was marked
+// stale are ignored: they are usually the stale label itself. So are updates no later than the
+// last rebase by the bot, which are the rebase itself.
+func LastActivityTime(db *gorm.DB, mr *models.MergeRequest) time.Time {
+	var last time.Time
+	if mr.GitlabCreatedAt != nil {
+		last = *mr.GitlabCreatedAt
+	}
+
+	var action models.MRAction
+	if err := staleActivity(db, mr.ID).
+		Order("timestamp DESC").
+		First(&action).Error; err == nil && action.Timestamp.After(last) {
+		last = action.Timestamp
+	}
+
+	var rebased models.MRAction
+	rebasedErr := db.Where("merge_request_id = ? AND action_type = ?", mr.ID, models.ActionRebased).
+		Order("timestamp DESC").
+		First(&rebased).Error
+	if mr.GitlabUpdatedAt != nil && mr.GitlabUpdatedAt.After(last) &&
+		(rebasedErr != nil || mr.GitlabUpdatedAt.After(rebased.Timestamp)) {
+		var marked models.MRAction
+		err := db.Where("merge_request_id = ? AND action_type = ?", mr.ID, models.ActionStaleDetected).
+			Order("timestamp DESC").
+			First(&marked).Error
+		if err != nil || mr.GitlabUpdatedAt.Before(marked.Timestamp) {
+			last = *mr.GitlabUpdatedAt
+		}
+	}
+	return last
+}
+
+// StaleMarkedSince returns when the MR was last marked stale, or nil if it never was or saw
+// activity since.
+func StaleMarkedSince(db *gorm.DB, mr *models.MergeRequest) *time.Time {
+	var marked models.MRAction
+	if err := db.Where("merge_request_id = ? AND action_type = ?", mr.ID, models.ActionStaleDetected).
+		Order("timestamp DESC").
+		First(&marked).Error; err != nil {
+		return nil
+	}
+
+	var activity int64
+	staleActivity(db.Model(&models.MRAction{}), mr.ID).
+		Where("timestamp > ?", marked.Timestamp).
+		Count(&activity)
+	if activity > 0 {
+		return nil
+	}
+	return &marked.Timestamp
+}
+
+// StaleThreshold returns the idle working time after which an MR of cfg's repository is stale.
+func StaleThreshold(cfg *models.StaleConfig) time.Duration {
+	return time.Duration(cfg.AfterDays) * 24 * time.Hour
+}
+
+// FindStaleMRs returns the open MRs of repositories with stale detection enabled that have been
+// idle for at least their repository's threshold, longest idle first. Blocked and release MRs are skipped.
+func FindStaleMRs(db *gorm.DB, repoIDs []uint) ([]StaleMR, error) {
+	var configs []models.StaleConfig
+	if err := db.Where("repository_id IN ?", repoIDs).Find(&configs).Error; err != nil {
+		return nil, err
+	}
+	if len(configs) == 0 {
+		return nil, nil
+	}
+	thresholds := make(map[uint]time.Duration, len(configs))
+	enabledRepoIDs := make([]uint, len(configs))
+	for i := range configs {
+		thresholds[configs[i].RepositoryID] = StaleThreshold(&configs[i])
+		enabledRepoIDs[i] = configs[i].RepositoryID
+	}
+
+	var mrs []models.MergeRequest
+	if err := db.
+		Preload("Author").
+		Preload("Repository").
+		Preload("Labels").
+		Where("merge_requests.state = ? AND merge_requests.merged_at IS NULL", "opened").
+		Where("repository_id IN ?", enabledRepoIDs).
+		Find(&mrs).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var stale []StaleMR
+	for _, mr := range mrs {
+		if HasReleaseLabel(db, &mr) || IsMRBlocked(db, &mr) {
+			continue
+		}
+		idle := CalculateWorkingTime(db, mr.RepositoryID, LastActivityTime(db, &mr), now)
+		if idle >= thresholds[mr.RepositoryID] {
+			stale = append(stale, StaleMR{MR: mr, Idle: idle})
+		}
+	}
+
+	sort.Slice(stale, func(i, j int) bool {
+		return stale[i].Idle > stale[j].Idle
+	})
+	return stale, nil
+}
+
+// BuildStaleReport renders the weekly list of stale MRs for a chat.
+func BuildStaleReport(db *gorm.DB, staleMRs []StaleMR) string {
+	authors := make([]models.User, len(staleMRs))
+	for i, s := range staleMRs {
+		authors[i] = s.MR.Author
+	}
+	mentionMap := BatchGetUserMentions(db, authors)
+
+	var sb strings.Builder
+	sb.WriteString("STALE MRS (no commits, comments or approvals):\n")
+	for _, s := range staleMRs {
+		mr := &s.MR
+		draft := ""
+		if mr.Draft {
+			draft = " [DRAFT]"
+		}
+		sb.WriteString(fmt.Sprintf("- [%s] %s%s\n", mr.Repository.Name, SanitizeTitle(mr.Title), draft))
+		sb.WriteString(fmt.Sprintf("  %s\n", mr.WebURL))
+		sb.WriteString(fmt.Sprintf("  by @[%s], idle %s\n", mentionMap[mr.Author.ID], FormatDuration(s.Idle)))
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
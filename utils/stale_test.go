@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+// TestLastActivityTime tests that activity actions move the last activity time and that GitLab
+// updates after the stale mark are ignored.
+func TestLastActivityTime(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := testutils.NewUserFactory(db).Create()
+
+	base := time.Now().AddDate(0, 0, -20).UTC().Truncate(time.Second)
+	at := func(d int) time.Time { return base.AddDate(0, 0, d) }
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author, testutils.WithCreatedAt(at(0)))
+
+	if got := LastActivityTime(db, &mr); !got.Equal(at(0)) {
+		t.Errorf("LastActivityTime() = %v, want creation time %v", got, at(0))
+	}
+
+	testutils.CreateMRAction(db, mr, models.ActionCommentAdded, testutils.WithTimestamp(at(2)))
+	testutils.CreateMRAction(db, mr, models.ActionReviewerAssigned, testutils.WithTimestamp(at(4)))
+	if got := LastActivityTime(db, &mr); !got.Equal(at(2)) {
+		t.Errorf("LastActivityTime() = %v, want the comment at %v", got, at(2))
+	}
+
+	updated := at(3)
+	mr.GitlabUpdatedAt = &updated
+	if got := LastActivityTime(db, &mr); !got.Equal(at(3)) {
+		t.Errorf("LastActivityTime() = %v, want the GitLab update at %v", got, at(3))
+	}
+
+	testutils.CreateMRAction(db, mr, models.ActionStaleDetected, testutils.WithTimestamp(at(10)))
+	labeled := at(10).Add(time.Minute)
+	mr.GitlabUpdatedAt = &labeled
+	if got := LastActivityTime(db, &mr); !got.Equal(at(2)) {
+		t.Errorf("LastActivityTime() = %v, want the label update ignored", got)
+	}
+
+	if got := StaleMarkedSince(db, &mr); got == nil || !got.Equal(at(10)) {
+		t.Errorf("StaleMarkedSince() = %v, want %v", got, at(10))
+	}
+	testutils.CreateMRAction(db, mr, models.ActionCommitsPushed, testutils.WithTimestamp(at(11)))
+	if got := StaleMarkedSince(db, &mr); got != nil {
+		t.Errorf("StaleMarkedSince() = %v, want nil after new commits", got)
+	}
+}
+
+// TestLastActivityTime_IgnoresBotRebase tests that a rebase by the bot and the approvals it reset
+// do not count as activity.
+func TestLastActivityTime_IgnoresBotRebase(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := testutils.NewUserFactory(db).Create()
+
+	base := time.Now().AddDate(0, 0, -20).UTC().Truncate(time.Second)
+	at := func(d int) time.Time { return base.AddDate(0, 0, d) }
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author, testutils.WithCreatedAt(at(0)))
+
+	testutils.CreateMRAction(db, mr, models.ActionCommentAdded, testutils.WithTimestamp(at(2)))
+	rebasedAt := at(5)
+	mr.GitlabUpdatedAt = &rebasedAt
+	testutils.CreateMRAction(db, mr, models.ActionRebased, testutils.WithTimestamp(rebasedAt.Add(time.Minute)))
+	testutils.CreateMRAction(db, mr, models.ActionUnapproved,
+		testutils.WithTimestamp(rebasedAt.Add(time.Minute)), testutils.WithMetadata(`{"reason":"rebased"}`))
+
+	if got := LastActivityTime(db, &mr); !got.Equal(at(2)) {
+		t.Errorf("LastActivityTime() = %v, want the comment at %v", got, at(2))
+	}
+
+	updated := at(6)
+	mr.GitlabUpdatedAt = &updated
+	if got := LastActivityTime(db, &mr); !got.Equal(at(6)) {
+		t.Errorf("LastActivityTime() = %v, want the later GitLab update at %v", got, at(6))
+	}
+}
+
+// TestFindStaleMRs tests that stale MRs are found per repository threshold, longest idle first,
+// skipping blocked MRs and repositories without stale detection.
+func TestFindStaleMRs(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repoFactory := testutils.NewRepositoryFactory(db)
+	repo := repoFactory.Create()
+	disabled := repoFactory.Create()
+	author := testutils.NewUserFactory(db).Create()
+	db.Create(&models.StaleConfig{RepositoryID: repo.ID, AfterDays: 5})
+	db.Create(&models.BlockLabel{RepositoryID: repo.ID, LabelName: "on-hold"})
+
+	now := time.Now()
+	mrFactory := testutils.NewMergeRequestFactory(db)
+	older := mrFactory.Create(repo, author, testutils.WithCreatedAt(now.AddDate(0, 0, -40)))
+	newer := mrFactory.Create(repo, author, testutils.WithCreatedAt(now.AddDate(0, 0, -20)))
+	mrFactory.Create(repo, author, testutils.WithCreatedAt(now.AddDate(0, 0, -1)))
+	mrFactory.Create(repo, author, testutils.WithCreatedAt(now.AddDate(0, 0, -40)), testutils.WithLabels(db, "on-hold"))
+	mrFactory.Create(disabled, author, testutils.WithCreatedAt(now.AddDate(0, 0, -40)))
+
+	stale, err := FindStaleMRs(db, []uint{repo.ID, disabled.ID})
+	if err != nil {
+		t.Fatalf("FindStaleMRs() error: %v", err)
+	}
+	if len(stale) != 2 {
+		t.Fatalf("expected 2 stale MRs, got %d", len(stale))
+	}
+	if stale[0].MR.ID != older.ID || stale[1].MR.ID != newer.ID {
+		t.Errorf("expected longest idle first, got MRs %d, %d", stale[0].MR.ID, stale[1].MR.ID)
+	}
+}
@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+// TestDeriveState_ExtendedPriority tests the priority of the blocked, conflicted, pipeline_failed
+// and ready_to_merge states against each other and on_fixes, in both the DB and cache variants.
+func TestDeriveState_ExtendedPriority(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	reviewer := userFactory.Create()
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author)
+	testutils.AssignReviewers(db, &mr, reviewer)
+
+	label := models.Label{Name: "on-hold"}
+	db.Create(&label)
+	db.Create(&models.BlockLabel{RepositoryID: repo.ID, LabelName: "on-hold"})
+
+	assertState := func(want MRState) {
+		t.Helper()
+		var reloaded models.MergeRequest
+		db.First(&reloaded, mr.ID)
+		if got := DeriveState(db, &reloaded); got != want {
+			t.Errorf("DeriveState() = %v, want %v", got, want)
+		}
+		cache, err := LoadMRDataCache(db, []uint{mr.ID}, []uint{repo.ID})
+		if err != nil {
+			t.Fatalf("LoadMRDataCache() error: %v", err)
+		}
+		if got := DeriveStateFromCache(&reloaded, cache); got != want {
+			t.Errorf("DeriveStateFromCache() = %v, want %v", got, want)
+		}
+	}
+
+	assertState(StateOnReview)
+
+	testutils.AssignApprovers(db, &mr, reviewer)
+	assertState(StateReadyToMerge)
+
+	testutils.CreateMRComment(db, mr, reviewer, 1, testutils.WithResolvable(), testutils.WithDiscussionID("disc-1"),
+		testutils.WithThreadStarter(&reviewer), testutils.WithIsLastInThread())
+	assertState(StateOnFixes)
+
+	db.Model(&mr).UpdateColumn("pipeline_status", PipelineFailed)
+	assertState(StatePipelineFailed)
+
+	db.Model(&mr).UpdateColumn("has_conflicts", true)
+	assertState(StateConflicted)
+
+	db.Model(&mr).Association("Labels").Append(&label)
+	assertState(StateBlocked)
+
+	db.Model(&mr).UpdateColumn("draft", true)
+	assertState(StateDraft)
+}
+
+// TestGetStateTransitionTime_ExtendedStates tests that the new states start at the action that
+// caused them, in both the DB and cache variants.
+func TestGetStateTransitionTime_ExtendedStates(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := testutils.NewUserFactory(db).Create()
+	mr := testutils.NewMergeRequestFactory(db).Create(repo, author)
+
+	base := time.Now().Add(-10 * time.Hour).UTC().Truncate(time.Second)
+	at := func(h int) time.Time { return base.Add(time.Duration(h) * time.Hour) }
+
+	testutils.CreateMRAction(db, mr, models.ActionBlockLabelAdded, testutils.WithTimestamp(at(0)))
+	testutils.CreateMRAction(db, mr, models.ActionBlockLabelRemoved, testutils.WithTimestamp(at(1)))
+	testutils.CreateMRAction(db, mr, models.ActionBlockLabelAdded, testutils.WithTimestamp(at(2)))
+	testutils.CreateMRAction(db, mr, models.ActionBlockLabelAdded, testutils.WithTimestamp(at(3)))
+	testutils.CreateMRAction(db, mr, models.ActionBlockLabelRemoved, testutils.WithTimestamp(at(4)))
+	testutils.CreateMRAction(db, mr, models.ActionConflictDetected, testutils.WithTimestamp(at(5)))
+	testutils.CreateMRAction(db, mr, models.ActionPipelineFailed, testutils.WithTimestamp(at(6)))
+	testutils.CreateMRAction(db, mr, models.ActionApproved, testutils.WithTimestamp(at(7)))
+
+	cache, err := LoadMRDataCache(db, []uint{mr.ID}, []uint{repo.ID})
+	if err != nil {
+		t.Fatalf("LoadMRDataCache() error: %v", err)
+	}
+
+	tests := []struct {
+		state MRState
+		want  time.Time
+	}{
+		{StateBlocked, at(2)},
+		{StateConflicted, at(5)},
+		{StatePipelineFailed, at(6)},
+		{StateReadyToMerge, at(7)},
+	}
+	for _, tt := range tests {
+		if got := GetStateTransitionTime(db, &mr, tt.state); got == nil || !got.Equal(tt.want) {
+			t.Errorf("GetStateTransitionTime(%v) = %v, want %v", tt.state, got, tt.want)
+		}
+		if got := GetStateTransitionTimeFromCache(&mr, tt.state, cache); got == nil || !got.Equal(tt.want) {
+			t.Errorf("GetStateTransitionTimeFromCache(%v) = %v, want %v", tt.state, got, tt.want)
+		}
+	}
+
+	testutils.CreateMRAction(db, mr, models.ActionPipelineFixed, testutils.WithTimestamp(at(8)))
+	if got := GetStateTransitionTime(db, &mr, StateOnReview); got == nil || !got.Equal(at(8)) {
+		t.Errorf("GetStateTransitionTime(on_review) = %v, want the pipeline fix at %v", got, at(8))
+	}
+}
+
+// TestSLAThresholdForState tests which SLA applies to each state.
+func TestSLAThresholdForState(t *testing.T) {
+	sla := &models.RepositorySLA{
+		ReviewDuration: models.Duration(24 * time.Hour),
+		FixesDuration:  models.Duration(8 * time.Hour),
+	}
+
+	tests := []struct {
+		state MRState
+		want  time.Duration
+	}{
+		{StateOnReview, 24 * time.Hour},
+		{StateOnFixes, 8 * time.Hour},
+		{StateDraft, 8 * time.Hour},
+		{StateConflicted, 8 * time.Hour},
+		{StatePipelineFailed, 8 * time.Hour},
+		{StateReadyToMerge, 0},
+		{StateBlocked, 0},
+		{StateMerged, 0},
+	}
+	for _, tt := range tests {
+		if got := SLAThresholdForState(sla, tt.state); got != tt.want {
+			t.Errorf("SLAThresholdForState(%v) = %v, want %v", tt.state, got, tt.want)
+		}
+	}
+}
+
+// TestBuildEnhancedReviewDigest_ReadyToMerge tests that approved MRs get their own section and
+// conflicted MRs are listed under pending fixes.
+func TestBuildEnhancedReviewDigest_ReadyToMerge(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := testutils.NewUserFactory(db).Create()
+	mrFactory := testutils.NewMergeRequestFactory(db)
+	approved := mrFactory.Create(repo, author, testutils.WithTitle("Approved change"))
+	conflicted := mrFactory.Create(repo, author, testutils.WithTitle("Conflicted change"))
+	approved.Repository = repo
+	conflicted.Repository = repo
+
+	digest := BuildEnhancedReviewDigest(db, []DigestMR{
+		{MR: approved, State: StateReadyToMerge},
+		{MR: conflicted, State: StateConflicted},
+	})
+
+	fixesAt := strings.Index(digest, "PENDING FIXES:")
+	readyAt := strings.Index(digest, "READY TO MERGE:")
+	if fixesAt < 0 || readyAt < 0 {
+		t.Fatalf("expected PENDING FIXES and READY TO MERGE sections, got:\n%s", digest)
+	}
+	if conflictedAt := strings.Index(digest, "Conflicted change"); conflictedAt < fixesAt || conflictedAt > readyAt {
+		t.Errorf("expected the conflicted MR under PENDING FIXES, got:\n%s", digest)
+	}
+	if approvedAt := strings.Index(digest, "Approved change"); approvedAt < readyAt {
+		t.Errorf("expected the approved MR under READY TO MERGE, got:\n%s", digest)
+	}
+}
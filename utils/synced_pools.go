@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// SyncedPool is one reviewer or release manager pool that a sync source, such as a GitLab group
+// binding, keeps rows in.
+type SyncedPool struct {
+	Model        interface{} // Empty row of the pool's table, e.g. &models.PossibleReviewer{}
+	RepositoryID uint
+	LabelName    string
+	HasLabel     bool   // The table is keyed by label as well as repository
+	OwnerColumn  string // Column naming the source of synced rows, e.g. "pool_group_id"
+	OwnerID      uint
+	NewRow       func(userID uint) interface{} // Builds a row owned by the source
+}
+
+// ReconcileSyncedPool makes the pool's rows owned by the source match desired: owned rows of
+// users no longer desired are deleted and desired users without any row are added. Rows added
+// manually or by other sources are left alone. Returns how many rows were added and removed.
+func ReconcileSyncedPool(tx *gorm.DB, pool SyncedPool, desired []uint) (int, int, error) {
+	want := make(map[uint]bool, len(desired))
+	for _, id := range desired {
+		want[id] = true
+	}
+
+	var rows []struct {
+		ID      uint
+		UserID  uint
+		OwnerID *uint
+	}
+	if err := pool.scope(tx.Model(pool.Model)).
+		Select("id, user_id, " + pool.OwnerColumn + " AS owner_id").
+		Scan(&rows).Error; err != nil {
+		return 0, 0, err
+	}
+
+	var added, removed int
+	present := make(map[uint]bool)
+	for _, r := range rows {
+		if r.OwnerID != nil && *r.OwnerID == pool.OwnerID && !want[r.UserID] {
+			if err := tx.Unscoped().Where("id = ?", r.ID).Delete(pool.Model).Error; err != nil {
+				return added, removed, err
+			}
+			removed++
+			continue
+		}
+		present[r.UserID] = true
+	}
+
+	sorted := append([]uint(nil), desired...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	for _, userID := range sorted {
+		if present[userID] {
+			continue
+		}
+		present[userID] = true
+		// A soft-deleted row of the same user would collide with the pool's unique index.
+		if err := pool.scope(tx.Unscoped()).Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+			Delete(pool.Model).Error; err != nil {
+			return added, removed, err
+		}
+		if err := tx.Create(pool.NewRow(userID)).Error; err != nil {
+			return added, removed, err
+		}
+		added++
+	}
+	return added, removed, nil
+}
+
+// scope restricts a query to the pool's repository and label.
+func (p SyncedPool) scope(db *gorm.DB) *gorm.DB {
+	db = db.Where("repository_id = ?", p.RepositoryID)
+	if p.HasLabel {
+		db = db.Where("label_name = ?", p.LabelName)
+	}
+	return db
+}
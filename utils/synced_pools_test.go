@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"testing"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+// TestReconcileSyncedPool tests that owned rows follow the desired users, that other rows are
+// kept, and that only soft-deleted rows of re-added users are purged.
+func TestReconcileSyncedPool(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+	repo := testutils.NewRepositoryFactory(db).Create()
+
+	alice := userFactory.Create(testutils.WithUsername("alice"))
+	bob := userFactory.Create(testutils.WithUsername("bob"))
+	carol := userFactory.Create(testutils.WithUsername("carol"))
+	manual := userFactory.Create(testutils.WithUsername("manual"))
+
+	testutils.CreateLabelReviewer(db, repo, "api", manual)
+	removedAlice := testutils.CreateLabelReviewer(db, repo, "api", alice)
+	db.Delete(&removedAlice)
+	removedCarol := testutils.CreateLabelReviewer(db, repo, "api", carol)
+	db.Delete(&removedCarol)
+
+	ownerID := uint(7)
+	pool := SyncedPool{
+		Model:        &models.LabelReviewer{},
+		RepositoryID: repo.ID,
+		LabelName:    "api",
+		HasLabel:     true,
+		OwnerColumn:  "pool_group_id",
+		OwnerID:      ownerID,
+		NewRow: func(userID uint) interface{} {
+			return &models.LabelReviewer{RepositoryID: repo.ID, LabelName: "api", UserID: userID, PoolGroupID: &ownerID}
+		},
+	}
+
+	added, removed, err := ReconcileSyncedPool(db, pool, []uint{alice.ID, bob.ID, manual.ID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if added != 2 || removed != 0 {
+		t.Errorf("expected +2 -0, got +%d -%d", added, removed)
+	}
+
+	var softDeleted int64
+	db.Unscoped().Model(&models.LabelReviewer{}).Where("user_id = ? AND deleted_at IS NOT NULL", carol.ID).Count(&softDeleted)
+	if softDeleted != 1 {
+		t.Error("expected the soft-deleted row of a user not re-added to be kept")
+	}
+
+	added, removed, err = ReconcileSyncedPool(db, pool, []uint{alice.ID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if added != 0 || removed != 1 {
+		t.Errorf("expected +0 -1, got +%d -%d", added, removed)
+	}
+	var userIDs []uint
+	db.Model(&models.LabelReviewer{}).Where("repository_id = ?", repo.ID).Order("user_id").Pluck("user_id", &userIDs)
+	if len(userIDs) != 2 || userIDs[0] != alice.ID || userIDs[1] != manual.ID {
+		t.Errorf("expected alice and the manual row, got %v", userIDs)
+	}
+}
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"devstreamlinebot/models"
+)
+
+// teamRowTables lists the tables team members are synced into, per binding role.
+var teamRowTables = []struct {
+	role     string
+	model    interface{}
+	hasLabel bool
+}{
+	{models.TeamRoleReviewers, &models.PossibleReviewer{}, false},
+	{models.TeamRoleLabelReviewers, &models.LabelReviewer{}, true},
+	{models.TeamRoleReleaseManagers, &models.ReleaseManager{}, false},
+}
+
+type teamRow struct {
+	ID           uint
+	RepositoryID uint
+	LabelName    string
+	UserID       uint
+	TeamID       *uint
+}
+
+// SyncTeam makes the rows synced from a team match its current members and bindings: members
+// missing from a bound pool are added, rows of removed members or dropped bindings are deleted.
+// Rows added manually are left alone and users who already have one are not added twice.
+func SyncTeam(db *gorm.DB, teamID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var memberIDs []uint
+		if err := tx.Table("team_members").Where("team_id = ?", teamID).Pluck("user_id", &memberIDs).Error; err != nil {
+			return err
+		}
+		var bindings []models.TeamBinding
+		if err := tx.Where("team_id = ?", teamID).Find(&bindings).Error; err != nil {
+			return err
+		}
+
+		for _, t := range teamRowTables {
+			bound := make(map[string]bool)
+			for _, b := range bindings {
+				if b.Role != t.role {
+					continue
+				}
+				bound[fmt.Sprintf("%d/%s", b.RepositoryID, b.LabelName)] = true
+				pool := SyncedPool{
+					Model:        t.model,
+					RepositoryID: b.RepositoryID,
+					LabelName:    b.LabelName,
+					HasLabel:     t.hasLabel,
+					OwnerColumn:  "team_id",
+					OwnerID:      teamID,
+					NewRow: func(userID uint) interface{} {
+						switch t.role {
+						case models.TeamRoleLabelReviewers:
+							return &models.LabelReviewer{RepositoryID: b.RepositoryID, LabelName: b.LabelName, UserID: userID, TeamID: &teamID}
+						case models.TeamRoleReleaseManagers:
+							return &models.ReleaseManager{RepositoryID: b.RepositoryID, UserID: userID, TeamID: &teamID}
+						}
+						return &models.PossibleReviewer{RepositoryID: b.RepositoryID, UserID: userID, TeamID: &teamID}
+					},
+				}
+				if _, _, err := ReconcileSyncedPool(tx, pool, memberIDs); err != nil {
+					return err
+				}
+			}
+
+			columns := "id, repository_id, '' AS label_name, user_id, team_id"
+			if t.hasLabel {
+				columns = "id, repository_id, label_name, user_id, team_id"
+			}
+			var rows []teamRow
+			if err := tx.Model(t.model).Select(columns).Where("team_id = ?", teamID).Scan(&rows).Error; err != nil {
+				return err
+			}
+			for _, r := range rows {
+				if bound[fmt.Sprintf("%d/%s", r.RepositoryID, r.LabelName)] {
+					continue
+				}
+				if err := tx.Unscoped().Where("id = ?", r.ID).Delete(t.model).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// FindTeamDigestMRs returns open MRs for the digest that are authored or reviewed by any of userIDs.
+func FindTeamDigestMRs(db *gorm.DB, userIDs []uint) ([]DigestMR, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	var repoIDs []uint
+	if err := db.Model(&models.MergeRequest{}).
+		Where("state = ? AND merged_at IS NULL", "opened").
+		Where("author_id IN ? OR EXISTS (SELECT 1 FROM merge_request_reviewers mrr WHERE mrr.merge_request_id = merge_requests.id AND mrr.user_id IN ?)", userIDs, userIDs).
+		Distinct().
+		Pluck("repository_id", &repoIDs).Error; err != nil {
+		return nil, err
+	}
+	if len(repoIDs) == 0 {
+		return nil, nil
+	}
+
+	digestMRs, err := FindDigestMergeRequestsWithState(db, repoIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make(map[uint]bool, len(userIDs))
+	for _, id := range userIDs {
+		members[id] = true
+	}
+	var result []DigestMR
+	for _, dmr := range digestMRs {
+		involved := members[dmr.MR.AuthorID]
+		for _, r := range dmr.MR.Reviewers {
+			involved = involved || members[r.ID]
+		}
+		if involved {
+			result = append(result, dmr)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"testing"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+// TestSyncTeam tests that team members are synced into bound pools and release managers,
+// that leavers and dropped bindings are removed, and that manual rows are kept.
+func TestSyncTeam(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+	repo := testutils.NewRepositoryFactory(db).Create()
+
+	alice := userFactory.Create(testutils.WithUsername("alice"))
+	bob := userFactory.Create(testutils.WithUsername("bob"))
+	manual := userFactory.Create(testutils.WithUsername("manual"))
+	testutils.CreatePossibleReviewer(db, repo, manual)
+	testutils.CreateReleaseManager(db, repo, bob)
+
+	team := models.Team{Name: "backend", Members: []models.User{alice, bob, manual}}
+	db.Create(&team)
+	db.Create(&models.TeamBinding{TeamID: team.ID, RepositoryID: repo.ID, Role: models.TeamRoleReviewers})
+	db.Create(&models.TeamBinding{TeamID: team.ID, RepositoryID: repo.ID, Role: models.TeamRoleLabelReviewers, LabelName: "api"})
+	db.Create(&models.TeamBinding{TeamID: team.ID, RepositoryID: repo.ID, Role: models.TeamRoleReleaseManagers})
+
+	if err := SyncTeam(db, team.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var pool []models.PossibleReviewer
+	db.Where("repository_id = ?", repo.ID).Find(&pool)
+	if len(pool) != 3 {
+		t.Fatalf("expected 3 default pool rows, got %d", len(pool))
+	}
+	for _, r := range pool {
+		if r.UserID == manual.ID && r.TeamID != nil {
+			t.Error("expected the manual row to stay manual")
+		}
+	}
+	var labelCount, managerCount int64
+	db.Model(&models.LabelReviewer{}).Where("repository_id = ? AND label_name = ? AND team_id = ?", repo.ID, "api", team.ID).Count(&labelCount)
+	db.Model(&models.ReleaseManager{}).Where("repository_id = ?", repo.ID).Count(&managerCount)
+	if labelCount != 3 || managerCount != 3 {
+		t.Fatalf("expected 3 label reviewers and 3 release managers, got %d and %d", labelCount, managerCount)
+	}
+
+	db.Model(&team).Association("Members").Delete(&alice)
+	db.Unscoped().Where("team_id = ? AND role = ?", team.ID, models.TeamRoleLabelReviewers).Delete(&models.TeamBinding{})
+	if err := SyncTeam(db, team.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var aliceRows int64
+	db.Model(&models.PossibleReviewer{}).Where("user_id = ?", alice.ID).Count(&aliceRows)
+	if aliceRows != 0 {
+		t.Error("expected the leaver removed from the default pool")
+	}
+	db.Model(&models.LabelReviewer{}).Where("repository_id = ?", repo.ID).Count(&labelCount)
+	if labelCount != 0 {
+		t.Errorf("expected label rows removed with the binding, got %d", labelCount)
+	}
+	var bobManager models.ReleaseManager
+	if err := db.Where("repository_id = ? AND user_id = ?", repo.ID, bob.ID).First(&bobManager).Error; err != nil || bobManager.TeamID != nil {
+		t.Errorf("expected bob to stay a manual release manager, got %+v (%v)", bobManager, err)
+	}
+}
+
+// TestFindTeamDigestMRs tests that only MRs authored or reviewed by team members are returned.
+func TestFindTeamDigestMRs(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+	repo := testutils.NewRepositoryFactory(db).Create()
+	member := userFactory.Create()
+	outsider := userFactory.Create()
+	reviewer := userFactory.Create()
+
+	mrFactory := testutils.NewMergeRequestFactory(db)
+	authored := mrFactory.Create(repo, member)
+	testutils.AssignReviewers(db, &authored, reviewer)
+	reviewed := mrFactory.Create(repo, outsider)
+	testutils.AssignReviewers(db, &reviewed, member)
+	other := mrFactory.Create(repo, outsider)
+	testutils.AssignReviewers(db, &other, reviewer)
+
+	digestMRs, err := FindTeamDigestMRs(db, []uint{member.ID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(digestMRs) != 2 {
+		t.Fatalf("expected 2 team MRs, got %d", len(digestMRs))
+	}
+	for _, dmr := range digestMRs {
+		if dmr.MR.ID == other.ID {
+			t.Error("expected MR without team members excluded")
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package utils
+
+import (
+	"devstreamlinebot/models"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecentReviewWindow is the lookback period used to weigh reviewer selection.
+const RecentReviewWindow = 14 * 24 * time.Hour
+
+// ReviewerLoad summarizes current and recent review load of a pool member.
+type ReviewerLoad struct {
+	User          models.User
+	OpenReviews   int        // Open MRs where the user is a reviewer and has not approved
+	OldestPending *time.Time // When the oldest pending review was assigned
+	RecentCount   int        // Reviews assigned within RecentReviewWindow
+	Weight        float64    // Normalized selection probability within the pool
+	Capacity      int        // Max concurrent open reviews (0 = unlimited)
+}
+
+// SelectionWeight returns the unnormalized weight used by weighted reviewer selection.
+func SelectionWeight(recentCount int) float64 {
+	return 1.0 / float64(recentCount+1)
+}
+
+// GetRecentReviewCounts returns how many MRs created within RecentReviewWindow
+// each user was assigned to review.
+func GetRecentReviewCounts(db *gorm.DB, userIDs []uint) (map[uint]int, error) {
+	return GetRecentReviewCountsAt(db, userIDs, time.Now())
+}
+
+// GetRecentReviewCountsAt returns how many MRs created within RecentReviewWindow before at
+// each user was assigned to review. Simulations pass the replay time.
+func GetRecentReviewCountsAt(db *gorm.DB, userIDs []uint, at time.Time) (map[uint]int, error) {
+	reviewCounts := make(map[uint]int)
+	if len(userIDs) == 0 {
+		return reviewCounts, nil
+	}
+
+	var counts []struct {
+		UserID uint
+		Count  int
+	}
+
+	if err := db.Table("merge_request_reviewers").
+		Joins("JOIN merge_requests ON merge_requests.id = merge_request_reviewers.merge_request_id").
+		Where("merge_requests.gitlab_created_at > ? AND merge_requests.gitlab_created_at <= ?", at.Add(-RecentReviewWindow), at).
+		Where("merge_request_reviewers.user_id IN ?", userIDs).
+		Select("merge_request_reviewers.user_id, COUNT(*) as count").
+		Group("merge_request_reviewers.user_id").
+		Find(&counts).Error; err != nil {
+		return reviewCounts, err
+	}
+
+	for _, rc := range counts {
+		reviewCounts[rc.UserID] = rc.Count
+	}
+	return reviewCounts, nil
+}
+
+// GetOpenReviewStats returns the number of open, not yet approved reviews per user
+// and the assignment time of each user's oldest pending review.
+// Assignment time is taken from the latest reviewer_assigned action, falling back to MR creation.
+func GetOpenReviewStats(db *gorm.DB, userIDs []uint) (map[uint]int, map[uint]time.Time, error) {
+	openCounts := make(map[uint]int)
+	oldest := make(map[uint]time.Time)
+	if len(userIDs) == 0 {
+		return openCounts, oldest, nil
+	}
+
+	type pendingRow struct {
+		MergeRequestID  uint
+		UserID          uint
+		GitlabCreatedAt *time.Time
+	}
+
+	var rows []pendingRow
+	if err := pendingReviews(db).
+		Select("mrr.merge_request_id, mrr.user_id, merge_requests.gitlab_created_at").
+		Where("mrr.user_id IN ?", userIDs).
+		Scan(&rows).Error; err != nil {
+		return openCounts, oldest, err
+	}
+
+	if len(rows) == 0 {
+		return openCounts, oldest, nil
+	}
+
+	mrIDs := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		mrIDs = append(mrIDs, row.MergeRequestID)
+	}
+
+	var assignActions []models.MRAction
+	if err := db.Where("merge_request_id IN ? AND action_type = ? AND target_user_id IN ?",
+		mrIDs, models.ActionReviewerAssigned, userIDs).
+		Find(&assignActions).Error; err != nil {
+		return openCounts, oldest, err
+	}
+
+	type assignKey struct {
+		mrID   uint
+		userID uint
+	}
+	assignedAt := make(map[assignKey]time.Time)
+	for _, a := range assignActions {
+		key := assignKey{a.MergeRequestID, *a.TargetUserID}
+		if a.Timestamp.After(assignedAt[key]) {
+			assignedAt[key] = a.Timestamp
+		}
+	}
+
+	for _, row := range rows {
+		openCounts[row.UserID]++
+
+		since, ok := assignedAt[assignKey{row.MergeRequestID, row.UserID}]
+		if !ok {
+			if row.GitlabCreatedAt == nil {
+				continue
+			}
+			since = *row.GitlabCreatedAt
+		}
+		if current, exists := oldest[row.UserID]; !exists || since.Before(current) {
+			oldest[row.UserID] = since
+		}
+	}
+
+	return openCounts, oldest, nil
+}
+
+// pendingReviews scopes merge_request_reviewers (as mrr) to open MRs the reviewer has not approved yet.
+func pendingReviews(db *gorm.DB) *gorm.DB {
+	return db.Table("merge_request_reviewers mrr").
+		Joins("JOIN merge_requests ON merge_requests.id = mrr.merge_request_id").
+		Where("merge_requests.state = ? AND merge_requests.merged_at IS NULL AND merge_requests.deleted_at IS NULL", "opened").
+		Where("NOT EXISTS (SELECT 1 FROM merge_request_approvers mra WHERE mra.merge_request_id = mrr.merge_request_id AND mra.user_id = mrr.user_id)")
+}
+
+// EffectiveCapacity returns the user's max concurrent open reviews:
+// the per-user limit when set, otherwise the repository limit (0 = unlimited).
+func EffectiveCapacity(user models.User, repoLimit int) int {
+	if user.MaxOpenReviews > 0 {
+		return user.MaxOpenReviews
+	}
+	if repoLimit > 0 {
+		return repoLimit
+	}
+	return 0
+}
+
+// GetUsersAtCapacity returns users whose open, not yet approved reviews across all
+// repositories reached their effective capacity for a repository with repoLimit.
+func GetUsersAtCapacity(db *gorm.DB, repoLimit int) ([]models.User, error) {
+	var counts []struct {
+		UserID uint
+		Count  int
+	}
+	if err := pendingReviews(db).
+		Select("mrr.user_id, COUNT(*) as count").
+		Group("mrr.user_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	if len(counts) == 0 {
+		return nil, nil
+	}
+
+	openCounts := make(map[uint]int, len(counts))
+	userIDs := make([]uint, 0, len(counts))
+	for _, rc := range counts {
+		openCounts[rc.UserID] = rc.Count
+		userIDs = append(userIDs, rc.UserID)
+	}
+
+	query := db.Where("id IN ?", userIDs)
+	if repoLimit <= 0 {
+		query = query.Where("max_open_reviews > 0")
+	}
+	var users []models.User
+	if err := query.Order("username").Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	var atCapacity []models.User
+	for _, u := range users {
+		if limit := EffectiveCapacity(u, repoLimit); limit > 0 && openCounts[u.ID] >= limit {
+			atCapacity = append(atCapacity, u)
+		}
+	}
+	return atCapacity, nil
+}
+
+// BuildPoolLoad computes load entries for pool members sorted from most to least loaded.
+// Selection weights are normalized across members eligible for selection (not on vacation).
+func BuildPoolLoad(users []models.User, openCounts map[uint]int, oldest map[uint]time.Time, recentCounts map[uint]int) []ReviewerLoad {
+	loads := make([]ReviewerLoad, 0, len(users))
+	totalWeight := 0.0
+	for _, u := range users {
+		load := ReviewerLoad{
+			User:        u,
+			OpenReviews: openCounts[u.ID],
+			RecentCount: recentCounts[u.ID],
+		}
+		if t, ok := oldest[u.ID]; ok {
+			load.OldestPending = &t
+		}
+		if !u.OnVacation {
+			load.Weight = SelectionWeight(load.RecentCount)
+			totalWeight += load.Weight
+		}
+		loads = append(loads, load)
+	}
+
+	if totalWeight > 0 {
+		for i := range loads {
+			loads[i].Weight /= totalWeight
+		}
+	}
+
+	sort.SliceStable(loads, func(i, j int) bool {
+		if loads[i].OpenReviews != loads[j].OpenReviews {
+			return loads[i].OpenReviews > loads[j].OpenReviews
+		}
+		if loads[i].RecentCount != loads[j].RecentCount {
+			return loads[i].RecentCount > loads[j].RecentCount
+		}
+		return loads[i].User.Username < loads[j].User.Username
+	})
+
+	return loads
+}
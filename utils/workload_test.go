@@ -0,0 +1,210 @@
+package utils
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"devstreamlinebot/models"
+	"devstreamlinebot/testutils"
+)
+
+// TestGetOpenReviewStats_CountsOnlyPendingOpenReviews tests that approved, merged and closed reviews are excluded.
+func TestGetOpenReviewStats_CountsOnlyPendingOpenReviews(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repoFactory := testutils.NewRepositoryFactory(db)
+	userFactory := testutils.NewUserFactory(db)
+	mrFactory := testutils.NewMergeRequestFactory(db)
+
+	repo := repoFactory.Create()
+	author := userFactory.Create()
+	reviewer := userFactory.Create()
+
+	pending1 := mrFactory.Create(repo, author)
+	testutils.AssignReviewers(db, &pending1, reviewer)
+	pending2 := mrFactory.Create(repo, author)
+	testutils.AssignReviewers(db, &pending2, reviewer)
+
+	approved := mrFactory.Create(repo, author)
+	testutils.AssignReviewers(db, &approved, reviewer)
+	testutils.AssignApprovers(db, &approved, reviewer)
+
+	closed := mrFactory.Create(repo, author, testutils.WithMRState("closed"))
+	testutils.AssignReviewers(db, &closed, reviewer)
+
+	openCounts, _, err := GetOpenReviewStats(db, []uint{reviewer.ID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if openCounts[reviewer.ID] != 2 {
+		t.Errorf("expected 2 open reviews, got %d", openCounts[reviewer.ID])
+	}
+}
+
+// TestGetOpenReviewStats_OldestUsesAssignmentAction tests that the assignment action timestamp wins over MR creation.
+func TestGetOpenReviewStats_OldestUsesAssignmentAction(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repoFactory := testutils.NewRepositoryFactory(db)
+	userFactory := testutils.NewUserFactory(db)
+	mrFactory := testutils.NewMergeRequestFactory(db)
+
+	repo := repoFactory.Create()
+	author := userFactory.Create()
+	reviewer := userFactory.Create()
+
+	createdAt := time.Now().Add(-72 * time.Hour).Truncate(time.Second)
+	assignedAt := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	mr := mrFactory.Create(repo, author, testutils.WithCreatedAt(createdAt))
+	testutils.AssignReviewers(db, &mr, reviewer)
+	testutils.CreateMRAction(db, mr, models.ActionReviewerAssigned,
+		testutils.WithTargetUser(reviewer), testutils.WithTimestamp(assignedAt))
+
+	otherCreatedAt := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	other := mrFactory.Create(repo, author, testutils.WithCreatedAt(otherCreatedAt))
+	testutils.AssignReviewers(db, &other, reviewer)
+
+	_, oldest, err := GetOpenReviewStats(db, []uint{reviewer.ID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, ok := oldest[reviewer.ID]
+	if !ok {
+		t.Fatal("expected oldest pending time for reviewer")
+	}
+	if !got.Equal(otherCreatedAt) {
+		t.Errorf("expected oldest pending %v (creation fallback), got %v", otherCreatedAt, got)
+	}
+}
+
+// TestGetOpenReviewStats_EmptyIDs tests with empty user IDs.
+func TestGetOpenReviewStats_EmptyIDs(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+
+	openCounts, oldest, err := GetOpenReviewStats(db, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(openCounts) != 0 || len(oldest) != 0 {
+		t.Errorf("expected empty maps, got %v and %v", openCounts, oldest)
+	}
+}
+
+// TestGetRecentReviewCounts_IgnoresOldMRs tests that only MRs within the recent window are counted.
+func TestGetRecentReviewCounts_IgnoresOldMRs(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repoFactory := testutils.NewRepositoryFactory(db)
+	userFactory := testutils.NewUserFactory(db)
+	mrFactory := testutils.NewMergeRequestFactory(db)
+
+	repo := repoFactory.Create()
+	author := userFactory.Create()
+	reviewer := userFactory.Create()
+
+	recent := mrFactory.Create(repo, author, testutils.WithCreatedAt(time.Now().Add(-24*time.Hour)))
+	testutils.AssignReviewers(db, &recent, reviewer)
+	old := mrFactory.Create(repo, author, testutils.WithCreatedAt(time.Now().Add(-20*24*time.Hour)))
+	testutils.AssignReviewers(db, &old, reviewer)
+
+	counts, err := GetRecentReviewCounts(db, []uint{reviewer.ID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if counts[reviewer.ID] != 1 {
+		t.Errorf("expected 1 recent review, got %d", counts[reviewer.ID])
+	}
+}
+
+// TestGetRecentReviewCountsAt_UsesGivenTime tests that the window ends at the given time and
+// MRs created after it are not counted.
+func TestGetRecentReviewCountsAt_UsesGivenTime(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	userFactory := testutils.NewUserFactory(db)
+	mrFactory := testutils.NewMergeRequestFactory(db)
+
+	repo := testutils.NewRepositoryFactory(db).Create()
+	author := userFactory.Create()
+	reviewer := userFactory.Create()
+
+	at := time.Now().Add(-30 * 24 * time.Hour)
+	before := mrFactory.Create(repo, author, testutils.WithCreatedAt(at.Add(-24*time.Hour)))
+	testutils.AssignReviewers(db, &before, reviewer)
+	after := mrFactory.Create(repo, author, testutils.WithCreatedAt(at.Add(24*time.Hour)))
+	testutils.AssignReviewers(db, &after, reviewer)
+
+	counts, err := GetRecentReviewCountsAt(db, []uint{reviewer.ID}, at)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if counts[reviewer.ID] != 1 {
+		t.Errorf("expected 1 review before the given time, got %d", counts[reviewer.ID])
+	}
+}
+
+// TestBuildPoolLoad_WeightsAndOrder tests weight normalization, vacation handling and sort order.
+func TestBuildPoolLoad_WeightsAndOrder(t *testing.T) {
+	busy := models.User{Username: "busy"}
+	busy.ID = 1
+	idle := models.User{Username: "idle"}
+	idle.ID = 2
+	away := models.User{Username: "away", OnVacation: true}
+	away.ID = 3
+
+	openCounts := map[uint]int{1: 4, 2: 0, 3: 1}
+	recentCounts := map[uint]int{1: 3, 2: 0, 3: 0}
+
+	loads := BuildPoolLoad([]models.User{idle, away, busy}, openCounts, nil, recentCounts)
+
+	if len(loads) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(loads))
+	}
+	if loads[0].User.Username != "busy" || loads[1].User.Username != "away" || loads[2].User.Username != "idle" {
+		t.Errorf("unexpected order: %s, %s, %s", loads[0].User.Username, loads[1].User.Username, loads[2].User.Username)
+	}
+
+	// busy weight 1/4, idle weight 1/1 -> normalized 0.2 and 0.8
+	if math.Abs(loads[0].Weight-0.2) > 1e-9 {
+		t.Errorf("expected busy weight 0.2, got %f", loads[0].Weight)
+	}
+	if loads[1].Weight != 0 {
+		t.Errorf("expected vacation weight 0, got %f", loads[1].Weight)
+	}
+	if math.Abs(loads[2].Weight-0.8) > 1e-9 {
+		t.Errorf("expected idle weight 0.8, got %f", loads[2].Weight)
+	}
+}
+
+// TestGetUsersAtCapacity tests repository limits, per-user overrides and unlimited users.
+func TestGetUsersAtCapacity(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	repoFactory := testutils.NewRepositoryFactory(db)
+	userFactory := testutils.NewUserFactory(db)
+	mrFactory := testutils.NewMergeRequestFactory(db)
+
+	repo := repoFactory.Create()
+	author := userFactory.Create()
+	full := userFactory.Create(testutils.WithUsername("full"))
+	raised := userFactory.Create(testutils.WithUsername("raised"))
+	capped := userFactory.Create(testutils.WithUsername("capped"))
+	db.Model(&raised).Update("max_open_reviews", 5)
+	db.Model(&capped).Update("max_open_reviews", 1)
+
+	for i := 0; i < 2; i++ {
+		mr := mrFactory.Create(repo, author)
+		testutils.AssignReviewers(db, &mr, full, raised)
+	}
+	mr := mrFactory.Create(repo, author)
+	testutils.AssignReviewers(db, &mr, capped)
+
+	users, err := GetUsersAtCapacity(db, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(users) != 2 || users[0].ID != capped.ID || users[1].ID != full.ID {
+		t.Errorf("expected capped and full at capacity, got %v", users)
+	}
+
+	users, _ = GetUsersAtCapacity(db, 0)
+	if len(users) != 1 || users[0].ID != capped.ID {
+		t.Errorf("expected only per-user limits without repository limit, got %v", users)
+	}
+}